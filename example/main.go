@@ -15,7 +15,6 @@ import (
 
 	"github.com/mickamy/ormgen/example/model"
 	"github.com/mickamy/ormgen/example/query"
-	"github.com/mickamy/ormgen/example/repo"
 	"github.com/mickamy/ormgen/orm"
 	"github.com/mickamy/ormgen/scope"
 )
@@ -104,7 +103,9 @@ func main() {
 	}
 	fmt.Println("Tables 'users' and 'posts' created.")
 
-	userRepo := repo.NewUserRepository(db)
+	// UserRepository is generated by the -repo flag in model/user.go.
+	userRepo := query.NewUserRepository(db)
+	byID := query.UserSortByID.Asc()
 
 	// INSERT
 	fmt.Println("\n--- INSERT ---")
@@ -126,7 +127,7 @@ func main() {
 
 	// SELECT (all)
 	fmt.Println("\n--- SELECT ALL ---")
-	all, err := userRepo.FindAll(ctx)
+	all, err := userRepo.FindAll(ctx, byID)
 	if err != nil {
 		log.Fatalf("find all: %v", err)
 	}
@@ -166,9 +167,8 @@ func main() {
 	fmt.Println("\n--- SCOPES ---")
 
 	// Paginate with Limit + Offset
-	fmt.Println("Paginate (limit=2, offset=1):")
-	paginate := scope.Combine(scope.Limit(2), scope.Offset(1))
-	page, err := userRepo.FindAll(ctx, paginate...)
+	fmt.Println("Paginate (page=2, perPage=2):")
+	page, err := userRepo.FindAll(ctx, append(scope.Paginate(2, 2), byID)...)
 	if err != nil {
 		log.Fatalf("paginate: %v", err)
 	}
@@ -178,7 +178,7 @@ func main() {
 
 	// Filter with Where
 	fmt.Println("Where (name LIKE 'A%%'):")
-	filtered, err := userRepo.FindAll(ctx, scope.Where("name LIKE ?", "A%"))
+	filtered, err := userRepo.FindAll(ctx, scope.Where("name LIKE ?", "A%"), byID)
 	if err != nil {
 		log.Fatalf("where: %v", err)
 	}
@@ -189,7 +189,7 @@ func main() {
 	// Filter with In
 	fmt.Println("In (id IN ...):")
 	ids := []int{users[0].ID, users[2].ID, users[4].ID}
-	inResult, err := userRepo.FindAll(ctx, scope.In("id", ids))
+	inResult, err := userRepo.FindAll(ctx, scope.In("id", ids), byID)
 	if err != nil {
 		log.Fatalf("in: %v", err)
 	}
@@ -223,7 +223,7 @@ func main() {
 	// JOIN
 	fmt.Println("\n--- JOIN ---")
 	fmt.Println("Users who have posts (INNER JOIN):")
-	joined, err := query.Users(db).Join("Posts").Select("DISTINCT users.*").All(ctx)
+	joined, err := query.Users(db).Join("Posts").Distinct().All(ctx)
 	if err != nil {
 		log.Fatalf("join: %v", err)
 	}
@@ -309,7 +309,7 @@ func main() {
 
 	// EXISTS
 	fmt.Println("\n--- EXISTS ---")
-	exists, err := query.Users(db).Where("email = ?", "alice.updated@example.com").Exists(ctx)
+	exists, err := query.Users(db).Scopes(query.UsersByEmail("alice.updated@example.com")).Exists(ctx)
 	if err != nil {
 		log.Fatalf("exists: %v", err)
 	}
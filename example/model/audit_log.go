@@ -0,0 +1,12 @@
+package model
+
+//go:generate go tool ormgen -source=$GOFILE -destination=../query
+
+type AuditLog struct {
+	ID     int
+	Action string
+}
+
+// TableName overrides the inferred "audit_logs". It has a pointer receiver,
+// which the generated factory must still honour at runtime.
+func (*AuditLog) TableName() string { return "audit_entries" }
@@ -2,7 +2,7 @@ package model
 
 import "time"
 
-//go:generate go tool ormgen -source=$GOFILE -destination=../query
+//go:generate go tool ormgen -source=$GOFILE -destination=../query -sort-columns -column-scopes -repo -mock
 
 type User struct {
 	ID        int
@@ -16,20 +16,50 @@ func Posts(db orm.Querier) *orm.Query[model.Post] {
 		db, orm.ResolveTableName[model.Post]("posts"), postsColumns, "id",
 		scanPost, postColumnValuePairs, setPostPK,
 	)
+	q.RegisterPK(getPostPK)
 	q.RegisterJoin("User", orm.JoinConfig{
 		TargetTable: orm.ResolveTableName[model.User]("users"), TargetColumn: "id",
 		SourceTable: orm.ResolveTableName[model.Post]("posts"), SourceColumn: "user_id",
 		SelectColumns: []string{"id", "name", "email", "created_at"},
 	})
 	q.RegisterPreloader("User", preloadPostUser)
+	q.RegisterScanInto(scanPostInto)
 	return q
 }
 
+// PostTable is the inferred name of the posts table. A TableName
+// method on model.Post still takes precedence at runtime.
+const PostTable = "posts"
+
 var postsColumns = []string{"id", "user_id", "title", "body"}
 
+// PostColumns holds the column name of each model.Post field, for
+// building clauses without spelling columns out, e.g.
+// PostColumns.ID+" = ?".
+var PostColumns = struct {
+	ID     string
+	UserID string
+	Title  string
+	Body   string
+}{
+	ID:     "id",
+	UserID: "user_id",
+	Title:  "title",
+	Body:   "body",
+}
+
 func scanPost(rows *sql.Rows) (model.Post, error) {
-	cols, _ := rows.Columns()
 	var v model.Post
+	err := scanPostInto(rows, &v)
+	return v, err
+}
+
+// scanPostInto scans the current row into v, so that callers can reuse
+// one destination across rows. Fields whose columns are not in the row are
+// left untouched, but join-scanned pointer relations are always
+// reset so that a reused v never keeps the previous row's relation.
+func scanPostInto(rows *sql.Rows, v *model.Post) error {
+	cols, _ := rows.Columns()
 	var joinScanUserPK sql.NullInt64
 	var joinScanUser model.User
 	dest := make([]any, len(cols))
@@ -46,21 +76,25 @@ func scanPost(rows *sql.Rows) (model.Post, error) {
 		case "User__id":
 			dest[i] = &joinScanUserPK
 		case "User__name":
-			dest[i] = &joinScanUser.Name
+			dest[i] = orm.SkipNull(&joinScanUser.Name)
 		case "User__email":
-			dest[i] = &joinScanUser.Email
+			dest[i] = orm.SkipNull(&joinScanUser.Email)
 		case "User__created_at":
-			dest[i] = &joinScanUser.CreatedAt
+			dest[i] = orm.SkipNull(&joinScanUser.CreatedAt)
 		default:
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
 	if joinScanUserPK.Valid {
 		joinScanUser.ID = int(joinScanUserPK.Int64)
 		v.User = &joinScanUser
+	} else {
+		v.User = nil
 	}
-	return v, err
+	return nil
 }
 
 func postColumnValuePairs(v *model.Post, includesPK bool) ([]string, []any) {
@@ -72,6 +106,10 @@ func postColumnValuePairs(v *model.Post, includesPK bool) ([]string, []any) {
 		[]any{v.UserID, v.Title, v.Body}
 }
 
+func getPostPK(v *model.Post) any {
+	return v.ID
+}
+
 func setPostPK(v *model.Post, id int64) {
 	v.ID = int(id)
 }
@@ -97,3 +135,9 @@ func preloadPostUser(ctx context.Context, db orm.Querier, results []model.Post)
 	}
 	return nil
 }
+
+// PreloadPostUser loads the User relation into every element of
+// results, which may come from anywhere (a cache, another query, ...).
+func PreloadPostUser(ctx context.Context, db orm.Querier, results []model.Post) error {
+	return preloadPostUser(ctx, db, results)
+}
@@ -10,17 +10,45 @@ import (
 
 // Profiles returns a new Query for the profiles table.
 func Profiles(db orm.Querier) *orm.Query[model.Profile] {
-	return orm.NewQuery[model.Profile](
+	q := orm.NewQuery[model.Profile](
 		db, orm.ResolveTableName[model.Profile]("profiles"), profilesColumns, "id",
 		scanProfile, profileColumnValuePairs, setProfilePK,
 	)
+	q.RegisterPK(getProfilePK)
+	q.RegisterScanInto(scanProfileInto)
+	return q
 }
 
+// ProfileTable is the inferred name of the profiles table. A TableName
+// method on model.Profile still takes precedence at runtime.
+const ProfileTable = "profiles"
+
 var profilesColumns = []string{"id", "user_id", "bio"}
 
+// ProfileColumns holds the column name of each model.Profile field, for
+// building clauses without spelling columns out, e.g.
+// ProfileColumns.ID+" = ?".
+var ProfileColumns = struct {
+	ID     string
+	UserID string
+	Bio    string
+}{
+	ID:     "id",
+	UserID: "user_id",
+	Bio:    "bio",
+}
+
 func scanProfile(rows *sql.Rows) (model.Profile, error) {
-	cols, _ := rows.Columns()
 	var v model.Profile
+	err := scanProfileInto(rows, &v)
+	return v, err
+}
+
+// scanProfileInto scans the current row into v, so that callers can reuse
+// one destination across rows. Fields whose columns are not in the row are
+// left untouched.
+func scanProfileInto(rows *sql.Rows, v *model.Profile) error {
+	cols, _ := rows.Columns()
 	dest := make([]any, len(cols))
 	for i, col := range cols {
 		switch col {
@@ -34,8 +62,10 @@ func scanProfile(rows *sql.Rows) (model.Profile, error) {
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
-	return v, err
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return nil
 }
 
 func profileColumnValuePairs(v *model.Profile, includesPK bool) ([]string, []any) {
@@ -47,6 +77,10 @@ func profileColumnValuePairs(v *model.Profile, includesPK bool) ([]string, []any
 		[]any{v.UserID, v.Bio}
 }
 
+func getProfilePK(v *model.Profile) any {
+	return v.ID
+}
+
 func setProfilePK(v *model.Profile, id int64) {
 	v.ID = int(id)
 }
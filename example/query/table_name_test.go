@@ -0,0 +1,69 @@
+//go:build integration
+
+package query_test
+
+import (
+	"testing"
+
+	"github.com/mickamy/ormgen/example/model"
+	"github.com/mickamy/ormgen/example/query"
+)
+
+// TestGeneratedFactoryHonorsPointerReceiverTableName checks that a generated
+// factory targets the table named by a pointer-receiver TableName method,
+// not the inferred one.
+func TestGeneratedFactoryHonorsPointerReceiverTableName(t *testing.T) {
+	ddl := map[string]string{
+		"MySQL":      "CREATE TABLE audit_entries (id INT AUTO_INCREMENT PRIMARY KEY, action VARCHAR(255) NOT NULL)",
+		"PostgreSQL": "CREATE TABLE audit_entries (id SERIAL PRIMARY KEY, action VARCHAR(255) NOT NULL)",
+	}
+
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, stmt := range []string{"DROP TABLE IF EXISTS audit_entries", ddl[ds.name]} {
+				if _, err := db.ExecContext(ctx, stmt); err != nil {
+					t.Fatalf("%s: %v", stmt, err)
+				}
+			}
+
+			entry := &model.AuditLog{Action: "login"}
+			if err := query.AuditLogs(db).Create(ctx, entry); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if entry.ID == 0 {
+				t.Fatal("expected ID to be set after Create")
+			}
+
+			// Read back through raw SQL against the custom table, so that a
+			// factory writing to "audit_logs" could not pass by accident.
+			var action string
+			rows, err := db.QueryContext(ctx, "SELECT action FROM audit_entries")
+			if err != nil {
+				t.Fatalf("raw select: %v", err)
+			}
+			defer func() { _ = rows.Close() }()
+			if !rows.Next() {
+				t.Fatal("no row in audit_entries")
+			}
+			if err := rows.Scan(&action); err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if action != "login" {
+				t.Errorf("action = %q, want %q", action, "login")
+			}
+
+			got, err := query.AuditLogs(db).First(ctx)
+			if err != nil {
+				t.Fatalf("First: %v", err)
+			}
+			if got.ID != entry.ID || got.Action != "login" {
+				t.Errorf("First = %+v, want %+v", got, *entry)
+			}
+		})
+	}
+}
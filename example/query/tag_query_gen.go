@@ -10,17 +10,43 @@ import (
 
 // Tags returns a new Query for the tags table.
 func Tags(db orm.Querier) *orm.Query[model.Tag] {
-	return orm.NewQuery[model.Tag](
+	q := orm.NewQuery[model.Tag](
 		db, orm.ResolveTableName[model.Tag]("tags"), tagsColumns, "id",
 		scanTag, tagColumnValuePairs, setTagPK,
 	)
+	q.RegisterPK(getTagPK)
+	q.RegisterScanInto(scanTagInto)
+	return q
 }
 
+// TagTable is the inferred name of the tags table. A TableName
+// method on model.Tag still takes precedence at runtime.
+const TagTable = "tags"
+
 var tagsColumns = []string{"id", "name"}
 
+// TagColumns holds the column name of each model.Tag field, for
+// building clauses without spelling columns out, e.g.
+// TagColumns.ID+" = ?".
+var TagColumns = struct {
+	ID   string
+	Name string
+}{
+	ID:   "id",
+	Name: "name",
+}
+
 func scanTag(rows *sql.Rows) (model.Tag, error) {
-	cols, _ := rows.Columns()
 	var v model.Tag
+	err := scanTagInto(rows, &v)
+	return v, err
+}
+
+// scanTagInto scans the current row into v, so that callers can reuse
+// one destination across rows. Fields whose columns are not in the row are
+// left untouched.
+func scanTagInto(rows *sql.Rows, v *model.Tag) error {
+	cols, _ := rows.Columns()
 	dest := make([]any, len(cols))
 	for i, col := range cols {
 		switch col {
@@ -32,8 +58,10 @@ func scanTag(rows *sql.Rows) (model.Tag, error) {
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
-	return v, err
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return nil
 }
 
 func tagColumnValuePairs(v *model.Tag, includesPK bool) ([]string, []any) {
@@ -45,6 +73,10 @@ func tagColumnValuePairs(v *model.Tag, includesPK bool) ([]string, []any) {
 		[]any{v.Name}
 }
 
+func getTagPK(v *model.Tag) any {
+	return v.ID
+}
+
 func setTagPK(v *model.Tag, id int64) {
 	v.ID = int(id)
 }
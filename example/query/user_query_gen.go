@@ -4,6 +4,7 @@ package query
 import (
 	"context"
 	"database/sql"
+	"sync"
 	"time"
 
 	"github.com/mickamy/ormgen/example/model"
@@ -17,10 +18,13 @@ func Users(db orm.Querier) *orm.Query[model.User] {
 		db, orm.ResolveTableName[model.User]("users"), usersColumns, "id",
 		scanUser, userColumnValuePairs, setUserPK,
 	)
+	q.RegisterPK(getUserPK)
 	q.RegisterJoin("Posts", orm.JoinConfig{
 		TargetTable: orm.ResolveTableName[model.Post]("posts"), TargetColumn: "user_id",
 		SourceTable: orm.ResolveTableName[model.User]("users"), SourceColumn: "id",
+		SelectColumns: []string{"id", "user_id", "title", "body"},
 	})
+	q.RegisterMerge("Posts", mergeUserPosts)
 	q.RegisterPreloader("Posts", preloadUserPosts)
 	q.RegisterJoin("Profile", orm.JoinConfig{
 		TargetTable: orm.ResolveTableName[model.Profile]("profiles"), TargetColumn: "user_id",
@@ -35,14 +39,46 @@ func Users(db orm.Querier) *orm.Query[model.User] {
 		nil,
 		nil,
 	)
+	q.RegisterScanInto(scanUserInto)
 	return q
 }
 
+// UserTable is the inferred name of the users table. A TableName
+// method on model.User still takes precedence at runtime.
+const UserTable = "users"
+
 var usersColumns = []string{"id", "name", "email", "created_at"}
 
+// UserColumns holds the column name of each model.User field, for
+// building clauses without spelling columns out, e.g.
+// UserColumns.ID+" = ?".
+var UserColumns = struct {
+	ID        string
+	Name      string
+	Email     string
+	CreatedAt string
+}{
+	ID:        "id",
+	Name:      "name",
+	Email:     "email",
+	CreatedAt: "created_at",
+}
+
 func scanUser(rows *sql.Rows) (model.User, error) {
-	cols, _ := rows.Columns()
 	var v model.User
+	err := scanUserInto(rows, &v)
+	return v, err
+}
+
+// scanUserInto scans the current row into v, so that callers can reuse
+// one destination across rows. Fields whose columns are not in the row are
+// left untouched, but join-scanned pointer relations are always
+// reset so that a reused v never keeps the previous row's relation.
+func scanUserInto(rows *sql.Rows, v *model.User) error {
+	cols, _ := rows.Columns()
+	var joinScanPostsPK sql.NullInt64
+	var joinScanPosts model.Post
+	joinScanPostsSeen := false
 	var joinScanProfilePK sql.NullInt64
 	var joinScanProfile model.Profile
 	dest := make([]any, len(cols))
@@ -56,22 +92,43 @@ func scanUser(rows *sql.Rows) (model.User, error) {
 			dest[i] = &v.Email
 		case "created_at":
 			dest[i] = &v.CreatedAt
+		case "Posts__id":
+			dest[i] = &joinScanPostsPK
+			joinScanPostsSeen = true
+		case "Posts__user_id":
+			dest[i] = orm.SkipNull(&joinScanPosts.UserID)
+		case "Posts__title":
+			dest[i] = orm.SkipNull(&joinScanPosts.Title)
+		case "Posts__body":
+			dest[i] = orm.SkipNull(&joinScanPosts.Body)
 		case "Profile__id":
 			dest[i] = &joinScanProfilePK
 		case "Profile__user_id":
-			dest[i] = &joinScanProfile.UserID
+			dest[i] = orm.SkipNull(&joinScanProfile.UserID)
 		case "Profile__bio":
-			dest[i] = &joinScanProfile.Bio
+			dest[i] = orm.SkipNull(&joinScanProfile.Bio)
 		default:
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if joinScanPostsSeen {
+		if joinScanPostsPK.Valid {
+			joinScanPosts.ID = int(joinScanPostsPK.Int64)
+			v.Posts = []model.Post{joinScanPosts}
+		} else {
+			v.Posts = []model.Post{}
+		}
+	}
 	if joinScanProfilePK.Valid {
 		joinScanProfile.ID = int(joinScanProfilePK.Int64)
 		v.Profile = &joinScanProfile
+	} else {
+		v.Profile = nil
 	}
-	return v, err
+	return nil
 }
 
 func userColumnValuePairs(v *model.User, includesPK bool) ([]string, []any) {
@@ -83,6 +140,10 @@ func userColumnValuePairs(v *model.User, includesPK bool) ([]string, []any) {
 		[]any{v.Name, v.Email, v.CreatedAt}
 }
 
+func getUserPK(v *model.User) any {
+	return v.ID
+}
+
 func setUserPK(v *model.User, id int64) {
 	v.ID = int(id)
 }
@@ -92,6 +153,160 @@ func setUserCreatedAt(v *model.User, now time.Time) {
 		v.CreatedAt = now
 	}
 }
+
+// UsersByID returns a Scope matching rows whose id equals v.
+func UsersByID(v int) scope.Scope {
+	return scope.Where("id = ?", v)
+}
+
+// UsersByName returns a Scope matching rows whose name equals v.
+func UsersByName(v string) scope.Scope {
+	return scope.Where("name = ?", v)
+}
+
+// UsersByEmail returns a Scope matching rows whose email equals v.
+func UsersByEmail(v string) scope.Scope {
+	return scope.Where("email = ?", v)
+}
+
+// UsersByCreatedAt returns a Scope matching rows whose created_at equals v.
+func UsersByCreatedAt(v time.Time) scope.Scope {
+	return scope.Where("created_at = ?", v)
+}
+
+// UserSortColumn is a column of the users table that results can
+// be ordered by. Parse untrusted input with ParseUserSortColumn.
+type UserSortColumn string
+
+// Sort columns of the users table.
+const (
+	UserSortByID        UserSortColumn = "id"
+	UserSortByName      UserSortColumn = "name"
+	UserSortByEmail     UserSortColumn = "email"
+	UserSortByCreatedAt UserSortColumn = "created_at"
+)
+
+// ParseUserSortColumn returns the UserSortColumn named s, or an error
+// wrapping orm.ErrUnknownSortColumn.
+func ParseUserSortColumn(s string) (UserSortColumn, error) {
+	return orm.ParseSortColumn(s, UserSortByID, UserSortByName, UserSortByEmail, UserSortByCreatedAt)
+}
+
+// Asc returns a Scope ordering by c ascending.
+func (c UserSortColumn) Asc() scope.Scope { return scope.Asc(string(c)) }
+
+// Desc returns a Scope ordering by c descending.
+func (c UserSortColumn) Desc() scope.Scope { return scope.Desc(string(c)) }
+
+// UserRepository provides CRUD access to the users table.
+type UserRepository interface {
+	Create(ctx context.Context, v *model.User) error
+	FindByID(ctx context.Context, id int) (model.User, error)
+	FindAll(ctx context.Context, scopes ...scope.Scope) ([]model.User, error)
+	Update(ctx context.Context, v *model.User) error
+	Delete(ctx context.Context, id int) error
+}
+
+// NewUserRepository returns a UserRepository that runs its queries on db,
+// which may be a *orm.DB or an *orm.Tx.
+func NewUserRepository(db orm.Querier) UserRepository {
+	return &userRepository{db: db}
+}
+
+type userRepository struct {
+	db orm.Querier
+}
+
+func (r *userRepository) Create(ctx context.Context, v *model.User) error {
+	return Users(r.db).Create(ctx, v)
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int) (model.User, error) {
+	return orm.FindByID(ctx, Users(r.db), id)
+}
+
+func (r *userRepository) FindAll(ctx context.Context, scopes ...scope.Scope) ([]model.User, error) {
+	return Users(r.db).Scopes(scopes...).All(ctx)
+}
+
+func (r *userRepository) Update(ctx context.Context, v *model.User) error {
+	return Users(r.db).Update(ctx, v)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int) error {
+	return orm.DeleteByID(ctx, Users(r.db), id)
+}
+
+// MockUserRepository is a UserRepository for tests that need no database.
+// Each method records its arguments in the matching Calls field and returns
+// the result of the matching Func field, or zero values when it is nil.
+type MockUserRepository struct {
+	CreateFunc   func(ctx context.Context, v *model.User) error
+	FindByIDFunc func(ctx context.Context, id int) (model.User, error)
+	FindAllFunc  func(ctx context.Context, scopes ...scope.Scope) ([]model.User, error)
+	UpdateFunc   func(ctx context.Context, v *model.User) error
+	DeleteFunc   func(ctx context.Context, id int) error
+
+	mu            sync.Mutex
+	CreateCalls   []*model.User
+	FindByIDCalls []int
+	FindAllCalls  [][]scope.Scope
+	UpdateCalls   []*model.User
+	DeleteCalls   []int
+}
+
+var _ UserRepository = (*MockUserRepository)(nil)
+
+func (m *MockUserRepository) Create(ctx context.Context, v *model.User) error {
+	m.mu.Lock()
+	m.CreateCalls = append(m.CreateCalls, v)
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		return nil
+	}
+	return m.CreateFunc(ctx, v)
+}
+
+func (m *MockUserRepository) FindByID(ctx context.Context, id int) (model.User, error) {
+	m.mu.Lock()
+	m.FindByIDCalls = append(m.FindByIDCalls, id)
+	m.mu.Unlock()
+	if m.FindByIDFunc == nil {
+		var zero model.User
+		return zero, nil
+	}
+	return m.FindByIDFunc(ctx, id)
+}
+
+func (m *MockUserRepository) FindAll(ctx context.Context, scopes ...scope.Scope) ([]model.User, error) {
+	m.mu.Lock()
+	m.FindAllCalls = append(m.FindAllCalls, scopes)
+	m.mu.Unlock()
+	if m.FindAllFunc == nil {
+		return nil, nil
+	}
+	return m.FindAllFunc(ctx, scopes...)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, v *model.User) error {
+	m.mu.Lock()
+	m.UpdateCalls = append(m.UpdateCalls, v)
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		return nil
+	}
+	return m.UpdateFunc(ctx, v)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	m.DeleteCalls = append(m.DeleteCalls, id)
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		return nil
+	}
+	return m.DeleteFunc(ctx, id)
+}
 func preloadUserPosts(ctx context.Context, db orm.Querier, results []model.User) error {
 	if len(results) == 0 {
 		return nil
@@ -113,6 +328,17 @@ func preloadUserPosts(ctx context.Context, db orm.Querier, results []model.User)
 	}
 	return nil
 }
+
+// PreloadUserPosts loads the Posts relation into every element of
+// results, which may come from anywhere (a cache, another query, ...).
+func PreloadUserPosts(ctx context.Context, db orm.Querier, results []model.User) error {
+	return preloadUserPosts(ctx, db, results)
+}
+
+// mergeUserPosts appends the Posts that JoinPreload scanned into src to dst.
+func mergeUserPosts(dst, src *model.User) {
+	dst.Posts = append(dst.Posts, src.Posts...)
+}
 func preloadUserProfile(ctx context.Context, db orm.Querier, results []model.User) error {
 	if len(results) == 0 {
 		return nil
@@ -134,6 +360,12 @@ func preloadUserProfile(ctx context.Context, db orm.Querier, results []model.Use
 	}
 	return nil
 }
+
+// PreloadUserProfile loads the Profile relation into every element of
+// results, which may come from anywhere (a cache, another query, ...).
+func PreloadUserProfile(ctx context.Context, db orm.Querier, results []model.User) error {
+	return preloadUserProfile(ctx, db, results)
+}
 func preloadUserTags(ctx context.Context, db orm.Querier, results []model.User) error {
 	if len(results) == 0 {
 		return nil
@@ -170,3 +402,9 @@ func preloadUserTags(ctx context.Context, db orm.Querier, results []model.User)
 	}
 	return nil
 }
+
+// PreloadUserTags loads the Tags relation into every element of
+// results, which may come from anywhere (a cache, another query, ...).
+func PreloadUserTags(ctx context.Context, db orm.Querier, results []model.User) error {
+	return preloadUserTags(ctx, db, results)
+}
@@ -0,0 +1,58 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mickamy/ormgen/example/model"
+	"github.com/mickamy/ormgen/example/query"
+	"github.com/mickamy/ormgen/orm"
+)
+
+// renameUser stands in for a handler that depends on the repository
+// interface rather than a database.
+func renameUser(ctx context.Context, users query.UserRepository, id int, name string) error {
+	u, err := users.FindByID(ctx, id)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	u.Name = name
+	return users.Update(ctx, &u) //nolint:wrapcheck // pass through
+}
+
+func TestMockUserRepository(t *testing.T) {
+	t.Parallel()
+
+	mock := &query.MockUserRepository{
+		FindByIDFunc: func(_ context.Context, id int) (model.User, error) {
+			return model.User{ID: id, Name: "alice"}, nil
+		},
+	}
+	if err := renameUser(t.Context(), mock, 7, "bob"); err != nil {
+		t.Fatalf("renameUser: %v", err)
+	}
+
+	if len(mock.FindByIDCalls) != 1 || mock.FindByIDCalls[0] != 7 {
+		t.Errorf("FindByIDCalls = %v, want [7]", mock.FindByIDCalls)
+	}
+	if len(mock.UpdateCalls) != 1 || mock.UpdateCalls[0].Name != "bob" {
+		t.Errorf("UpdateCalls = %v, want one update renaming to bob", mock.UpdateCalls)
+	}
+}
+
+func TestMockUserRepositoryError(t *testing.T) {
+	t.Parallel()
+
+	mock := &query.MockUserRepository{
+		FindByIDFunc: func(context.Context, int) (model.User, error) {
+			return model.User{}, orm.ErrNotFound
+		},
+	}
+	if err := renameUser(t.Context(), mock, 7, "bob"); !errors.Is(err, orm.ErrNotFound) {
+		t.Errorf("err = %v, want orm.ErrNotFound", err)
+	}
+	if len(mock.UpdateCalls) != 0 {
+		t.Errorf("UpdateCalls = %v, want none", mock.UpdateCalls)
+	}
+}
@@ -0,0 +1,233 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ddlDialect holds the per-engine pieces RenderDDL needs.
+type ddlDialect struct {
+	quote     func(string) string
+	autoPK    func(goType string) string // column definition for an auto-increment integer PK
+	columnFor func(goType string) (sqlType string, ok bool)
+}
+
+var ddlDialects = map[string]ddlDialect{
+	"mysql": {
+		quote: func(s string) string { return "`" + s + "`" },
+		autoPK: func(goType string) string {
+			sqlType, _ := mysqlColumnType(goType)
+			return sqlType + " AUTO_INCREMENT PRIMARY KEY"
+		},
+		columnFor: mysqlColumnType,
+	},
+	"postgres": {
+		quote: func(s string) string { return `"` + s + `"` },
+		autoPK: func(goType string) string {
+			switch goType {
+			case "int8", "int16", "int32", "uint8", "uint16":
+				return "SERIAL PRIMARY KEY"
+			default:
+				return "BIGSERIAL PRIMARY KEY"
+			}
+		},
+		columnFor: postgresColumnType,
+	},
+}
+
+// RenderDDL generates CREATE TABLE statements for the given StructInfos.
+// dialect is "mysql" or "postgres". Go types are mapped to SQL types on a
+// best-effort basis; belongs_to relations become FOREIGN KEY constraints,
+// added by ALTER TABLE once every table exists, so that a model may refer
+// to one declared after it. A constraint referencing the target's primary
+// key column needs the target parsed with a single-column key; otherwise
+// it is left as a TODO comment. The output is a starting point for a schema,
+// not a migration system.
+func RenderDDL(infos []*StructInfo, dialect string, opt RenderOption) ([]byte, error) {
+	if len(infos) == 0 {
+		return nil, errors.New("no structs to render")
+	}
+	d, ok := ddlDialects[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unknown DDL dialect %q (use mysql or postgres)", dialect)
+	}
+
+	allInfos := make([]*StructInfo, 0, len(infos)+len(opt.PeerInfos))
+	allInfos = append(allInfos, infos...)
+	allInfos = append(allInfos, opt.PeerInfos...)
+
+	var b strings.Builder
+	b.WriteString("-- Code generated by ormgen; DO NOT EDIT.\n")
+	b.WriteString("-- Best-effort schema derived from Go models. Review before applying;\n")
+	b.WriteString("-- this is a starting point, not a migration system.\n")
+
+	created := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		created[info.TableName] = true
+	}
+
+	var foreignKeys []string
+	for _, info := range infos {
+		if _, err := info.optionalPrimaryKeyField(); err != nil {
+			return nil, err
+		}
+
+		pkFields := info.PrimaryKeyFields()
+		composite := len(pkFields) > 1
+
+		var defs []string
+		var comments []string
+		for _, f := range info.Fields {
+			def := d.quote(f.Column) + " "
+			if f.PrimaryKey && !composite && isIntType(f.GoType) {
+				def += d.autoPK(f.GoType)
+			} else {
+				sqlType, known := d.columnFor(strings.TrimPrefix(f.GoType, "*"))
+				def += sqlType
+				if !isNullableGoType(f.GoType) {
+					def += " NOT NULL"
+				}
+				if f.PrimaryKey && !composite {
+					def += " PRIMARY KEY"
+				}
+				if f.Server {
+					def += " DEFAULT CURRENT_TIMESTAMP"
+				}
+				if !known {
+					def += " /* TODO: unmapped Go type " + f.GoType + " */"
+				}
+			}
+			if f.Comment != "" {
+				if dialect == "mysql" {
+					def += " COMMENT " + sqlString(f.Comment)
+				} else {
+					comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;",
+						d.quote(info.TableName), d.quote(f.Column), sqlString(f.Comment)))
+				}
+			}
+			defs = append(defs, def)
+		}
+		if composite {
+			cols := make([]string, len(pkFields))
+			for i, f := range pkFields {
+				cols[i] = d.quote(f.Column)
+			}
+			defs = append(defs, "PRIMARY KEY ("+strings.Join(cols, ", ")+")")
+		}
+
+		for _, rel := range info.Relations {
+			if rel.RelType != "belongs_to" {
+				continue
+			}
+			targetTable := opt.Plurals.TableName(rel.TargetType)
+			var target *StructInfo
+			if rel.TargetImportPath == "" {
+				target = findStructInfo(allInfos, rel.TargetType)
+			}
+			if target != nil && target.TableName != "" {
+				targetTable = target.TableName
+			}
+			from := d.quote(info.TableName) + " (" + d.quote(rel.ForeignKey) + ")"
+			if target == nil {
+				foreignKeys = append(foreignKeys, fmt.Sprintf(
+					"-- TODO: foreign key %s to %s: %s is not parsed, so its primary key is unknown.",
+					from, d.quote(targetTable), rel.TargetType))
+				continue
+			}
+			targetPKs := target.PrimaryKeyFields()
+			if len(targetPKs) != 1 {
+				foreignKeys = append(foreignKeys, fmt.Sprintf(
+					"-- TODO: foreign key %s to %s: %s has no single-column primary key.",
+					from, d.quote(targetTable), rel.TargetType))
+				continue
+			}
+			var fk string
+			if !created[targetTable] {
+				// A peer model: its table comes from another schema file.
+				fk = fmt.Sprintf("-- %s is not created by this file; create it first.\n", d.quote(targetTable))
+			}
+			fk += fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (%s);",
+				d.quote(info.TableName), d.quote(rel.ForeignKey), d.quote(targetTable), d.quote(targetPKs[0].Column))
+			foreignKeys = append(foreignKeys, fk)
+		}
+
+		fmt.Fprintf(&b, "\nCREATE TABLE %s (\n\t%s\n);\n", d.quote(info.TableName), strings.Join(defs, ",\n\t"))
+		for _, c := range comments {
+			b.WriteString(c)
+			b.WriteByte('\n')
+		}
+	}
+
+	if len(foreignKeys) > 0 {
+		b.WriteByte('\n')
+		for _, fk := range foreignKeys {
+			b.WriteString(fk)
+			b.WriteByte('\n')
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+func mysqlColumnType(goType string) (string, bool) {
+	switch goType {
+	case "int", "int64", "uint", "uint64", "sql.NullInt64":
+		return "BIGINT", true
+	case "int32", "uint32", "sql.NullInt32":
+		return "INT", true
+	case "int16", "uint16", "sql.NullInt16":
+		return "SMALLINT", true
+	case "int8", "uint8", "sql.NullByte":
+		return "TINYINT", true
+	case "string", "sql.NullString":
+		return "VARCHAR(255)", true
+	case "bool", "sql.NullBool":
+		return "BOOLEAN", true
+	case "float32":
+		return "FLOAT", true
+	case "float64", "sql.NullFloat64":
+		return "DOUBLE", true
+	case "time.Time", "sql.NullTime":
+		return "DATETIME", true
+	case "[]byte":
+		return "BLOB", true
+	default:
+		return "TEXT", false
+	}
+}
+
+func postgresColumnType(goType string) (string, bool) {
+	switch goType {
+	case "int", "int64", "uint", "uint64", "sql.NullInt64":
+		return "BIGINT", true
+	case "int32", "uint32", "sql.NullInt32":
+		return "INTEGER", true
+	case "int8", "int16", "uint8", "uint16", "sql.NullInt16", "sql.NullByte":
+		return "SMALLINT", true
+	case "string", "sql.NullString":
+		return "TEXT", true
+	case "bool", "sql.NullBool":
+		return "BOOLEAN", true
+	case "float32":
+		return "REAL", true
+	case "float64", "sql.NullFloat64":
+		return "DOUBLE PRECISION", true
+	case "time.Time", "sql.NullTime":
+		return "TIMESTAMPTZ", true
+	case "[]byte":
+		return "BYTEA", true
+	default:
+		return "TEXT", false
+	}
+}
+
+// isNullableGoType reports whether goType can hold SQL NULL.
+func isNullableGoType(goType string) bool {
+	return strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "sql.Null")
+}
+
+// sqlString quotes s as a single-quoted SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
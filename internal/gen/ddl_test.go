@@ -0,0 +1,345 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mickamy/ormgen/internal/gen"
+)
+
+func TestRenderDDL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		dialect        string
+		checks         []string
+		negativeChecks []string
+	}{
+		{
+			name:    "mysql",
+			dialect: "mysql",
+			checks: []string{
+				"-- Code generated by ormgen; DO NOT EDIT.",
+				"not a migration system",
+				"CREATE TABLE `users` (",
+				"`id` BIGINT AUTO_INCREMENT PRIMARY KEY",
+				"`name` VARCHAR(255) NOT NULL",
+				"`active` BOOLEAN NOT NULL",
+				"`created_at` DATETIME NOT NULL",
+				"CREATE TABLE `posts` (",
+				"`user_id` BIGINT NOT NULL",
+			},
+			negativeChecks: []string{
+				"`posts` BIGINT", // relation fields are not columns
+				"FOREIGN KEY",
+			},
+		},
+		{
+			name:    "postgres",
+			dialect: "postgres",
+			checks: []string{
+				`CREATE TABLE "users" (`,
+				`"id" BIGSERIAL PRIMARY KEY`,
+				`"name" TEXT NOT NULL`,
+				`"created_at" TIMESTAMPTZ NOT NULL`,
+			},
+			negativeChecks: []string{
+				"AUTO_INCREMENT",
+				"`",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			infos, err := gen.Parse(testdataPath("user.go"))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			findStruct(t, infos, "User").TableName = "users"
+			findStruct(t, infos, "Post").TableName = "posts"
+
+			src, err := gen.RenderDDL(infos, tt.dialect, gen.RenderOption{})
+			if err != nil {
+				t.Fatalf("RenderDDL: %v", err)
+			}
+			ddl := string(src)
+
+			for _, want := range tt.checks {
+				if !strings.Contains(ddl, want) {
+					t.Errorf("missing %q in DDL:\n%s", want, ddl)
+				}
+			}
+			for _, bad := range tt.negativeChecks {
+				if strings.Contains(ddl, bad) {
+					t.Errorf("unexpected %q in DDL:\n%s", bad, ddl)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDDLForeignKeysAndNulls(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relations.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Author").TableName = "writers"
+	findStruct(t, infos, "Article").TableName = "articles"
+	findStruct(t, infos, "Comment").TableName = "comments"
+	comment := findStruct(t, infos, "Comment")
+
+	src, err := gen.RenderDDL([]*gen.StructInfo{findStruct(t, infos, "Article"), comment}, "mysql", gen.RenderOption{PeerInfos: infos})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	ddl := string(src)
+
+	checks := []string{
+		// Target table name comes from the peer StructInfo, not inflection.
+		"-- `writers` is not created by this file; create it first.\n" +
+			"ALTER TABLE `articles` ADD FOREIGN KEY (`author_id`) REFERENCES `writers` (`id`);",
+		// Pointer fields are nullable.
+		"`author_id` VARCHAR(255),",
+	}
+	for _, want := range checks {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("missing %q in DDL:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestRenderDDLForeignKeyToLaterTable(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relations.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	author := findStruct(t, infos, "Author")
+	author.TableName = "writers"
+	article := findStruct(t, infos, "Article")
+	article.TableName = "articles"
+
+	for _, dialect := range []string{"mysql", "postgres"} {
+		// The referencing model comes first.
+		src, err := gen.RenderDDL([]*gen.StructInfo{article, author}, dialect, gen.RenderOption{})
+		if err != nil {
+			t.Fatalf("RenderDDL %s: %v", dialect, err)
+		}
+		ddl := string(src)
+
+		fk := strings.Index(ddl, "ALTER TABLE")
+		if fk < 0 || fk < strings.LastIndex(ddl, "CREATE TABLE") {
+			t.Errorf("%s: foreign key must follow every CREATE TABLE:\n%s", dialect, ddl)
+		}
+		if strings.Contains(ddl[:max(fk, 0)], "FOREIGN KEY") {
+			t.Errorf("%s: unexpected inline FOREIGN KEY:\n%s", dialect, ddl)
+		}
+		if strings.Contains(ddl, "not created by this file") {
+			t.Errorf("%s: writers is created by this file:\n%s", dialect, ddl)
+		}
+	}
+}
+
+func TestRenderDDLForeignKeyTargetKey(t *testing.T) {
+	t.Parallel()
+
+	account := &gen.StructInfo{
+		Name:      "Account",
+		TableName: "accounts",
+		Fields:    []gen.FieldInfo{{Name: "Code", Column: "code", GoType: "string", PrimaryKey: true}},
+	}
+	membership := &gen.StructInfo{
+		Name:      "Membership",
+		TableName: "memberships",
+		Fields: []gen.FieldInfo{
+			{Name: "UserID", Column: "user_id", GoType: "int", PrimaryKey: true},
+			{Name: "GroupID", Column: "group_id", GoType: "int", PrimaryKey: true},
+		},
+	}
+	invoice := &gen.StructInfo{
+		Name:      "Invoice",
+		TableName: "invoices",
+		Fields: []gen.FieldInfo{
+			{Name: "ID", Column: "id", GoType: "int", PrimaryKey: true},
+			{Name: "AccountCode", Column: "account_code", GoType: "string"},
+			{Name: "MembershipID", Column: "membership_id", GoType: "int"},
+			{Name: "OwnerID", Column: "owner_id", GoType: "int"},
+		},
+		Relations: []gen.RelationInfo{
+			{FieldName: "Account", TargetType: "Account", RelType: "belongs_to", ForeignKey: "account_code", IsPointer: true},
+			{FieldName: "Membership", TargetType: "Membership", RelType: "belongs_to", ForeignKey: "membership_id", IsPointer: true},
+			{
+				FieldName: "Owner", TargetType: "User", TargetPkgAlias: "auth", TargetImportPath: "example.com/auth",
+				RelType: "belongs_to", ForeignKey: "owner_id", IsPointer: true,
+			},
+		},
+	}
+
+	src, err := gen.RenderDDL([]*gen.StructInfo{invoice, account, membership}, "postgres", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	ddl := string(src)
+
+	checks := []string{
+		`ALTER TABLE "invoices" ADD FOREIGN KEY ("account_code") REFERENCES "accounts" ("code");`,
+		`-- TODO: foreign key "invoices" ("membership_id") to "memberships": Membership has no single-column primary key.`,
+		`-- TODO: foreign key "invoices" ("owner_id") to "users": User is not parsed, so its primary key is unknown.`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("missing %q in DDL:\n%s", want, ddl)
+		}
+	}
+	if strings.Contains(ddl, `("id");`) {
+		t.Errorf("unexpected reference to an assumed id column:\n%s", ddl)
+	}
+	if n := strings.Count(ddl, "ADD FOREIGN KEY"); n != 1 {
+		t.Errorf("ADD FOREIGN KEY count = %d, want 1:\n%s", n, ddl)
+	}
+}
+
+func TestRenderDDLComments(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("comments.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	account := findStruct(t, infos, "Account")
+	account.TableName = "accounts"
+
+	mysql, err := gen.RenderDDL([]*gen.StructInfo{account}, "mysql", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL mysql: %v", err)
+	}
+	if want := "`name` VARCHAR(255) NOT NULL COMMENT 'Owner''s display name.'"; !strings.Contains(string(mysql), want) {
+		t.Errorf("missing %q in DDL:\n%s", want, mysql)
+	}
+
+	pg, err := gen.RenderDDL([]*gen.StructInfo{account}, "postgres", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL postgres: %v", err)
+	}
+	if want := `COMMENT ON COLUMN "accounts"."name" IS 'Owner''s display name.';`; !strings.Contains(string(pg), want) {
+		t.Errorf("missing %q in DDL:\n%s", want, pg)
+	}
+}
+
+func TestRenderDDLServerTimestamps(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("server_timestamps.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	ledger := findStruct(t, infos, "Ledger")
+	ledger.TableName = "ledgers"
+
+	src, err := gen.RenderDDL([]*gen.StructInfo{ledger}, "postgres", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	ddl := string(src)
+
+	checks := []string{
+		`"recorded_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`"settled_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("missing %q in DDL:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestRenderDDLTypeAliases(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("aliases.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	infos[0].TableName = "accounts"
+
+	src, err := gen.RenderDDL(infos, "mysql", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	ddl := string(src)
+
+	checks := []string{
+		"`id` BIGINT AUTO_INCREMENT PRIMARY KEY",
+		"`handle` VARCHAR(255) NOT NULL",
+		"`nickname` VARCHAR(255),",
+		"`created_at` DATETIME NOT NULL",
+	}
+	for _, want := range checks {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("missing %q in DDL:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestRenderDDLReadOnlyWithoutPK(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("readonly.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	tag := findStruct(t, infos, "Tag")
+	tag.TableName = "tags"
+
+	src, err := gen.RenderDDL([]*gen.StructInfo{tag}, "mysql", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	if want := "CREATE TABLE `tags` (\n\t`name` VARCHAR(255) NOT NULL\n);"; !strings.Contains(string(src), want) {
+		t.Errorf("missing %q in DDL:\n%s", want, src)
+	}
+}
+
+func TestRenderDDLCompositePK(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("composite_pk.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	infos[0].TableName = "memberships"
+
+	src, err := gen.RenderDDL(infos, "postgres", gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderDDL: %v", err)
+	}
+	want := `CREATE TABLE "memberships" (` + "\n\t" + `"tenant_id" BIGINT NOT NULL,` + "\n\t" +
+		`"user_id" BIGINT NOT NULL,` + "\n\t" + `"role" TEXT NOT NULL,` + "\n\t" +
+		`PRIMARY KEY ("tenant_id", "user_id")` + "\n);"
+	if !strings.Contains(string(src), want) {
+		t.Errorf("missing %q in DDL:\n%s", want, src)
+	}
+}
+
+func TestRenderDDLErrors(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if _, err := gen.RenderDDL(infos, "sqlite", gen.RenderOption{}); err == nil {
+		t.Error("expected error for unknown dialect")
+	}
+	if _, err := gen.RenderDDL(nil, "mysql", gen.RenderOption{}); err == nil {
+		t.Error("expected error for no structs")
+	}
+}
@@ -3,9 +3,14 @@ package gen
 import (
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/parser"
 	"go/token"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/mickamy/ormgen/internal/naming"
@@ -13,35 +18,64 @@ import (
 
 // FieldInfo holds parsed metadata for one struct field.
 type FieldInfo struct {
-	Name       string // Go field name, e.g. "ID"
-	Column     string // DB column name from `db:"id"` tag
-	GoType     string // Go type as string, e.g. "int", "string", "time.Time"
-	PrimaryKey bool   // true if tag contains "primaryKey"
-	CreatedAt  bool   // true if this is a createdAt timestamp field
-	UpdatedAt  bool   // true if this is an updatedAt timestamp field
+	Name       string   `json:"name"`                 // Go field name, e.g. "ID"
+	Column     string   `json:"column"`               // DB column name from `db:"id"` tag
+	GoType     string   `json:"goType"`               // Go type as string, e.g. "int", "string", "time.Time"
+	PrimaryKey bool     `json:"primaryKey,omitempty"` // true if tag contains "primaryKey"
+	CreatedAt  bool     `json:"createdAt,omitempty"`  // true if this is a createdAt timestamp field
+	UpdatedAt  bool     `json:"updatedAt,omitempty"`  // true if this is an updatedAt timestamp field
+	Server     bool     `json:"server,omitempty"`     // "server": the createdAt/updatedAt value is set by the database, not the Clock
+	DeletedAt  bool     `json:"deletedAt,omitempty"`  // true if this nullable timestamp marks soft-deleted rows
+	Version    bool     `json:"version,omitempty"`    // "version": integer column for optimistic locking
+	Generated  bool     `json:"generated,omitempty"`  // "generated": primary key value is generated by the client before INSERT
+	ReadOnly   bool     `json:"readOnly,omitempty"`   // "readonly": database-managed column, scanned but never written
+	Immutable  bool     `json:"immutable,omitempty"`  // "immutable": written by INSERT, never by UPDATE
+	TypeImport string   `json:"typeImport,omitempty"` // import path of the package qualifying GoType, e.g. "github.com/google/uuid"
+	Comment    string   `json:"comment,omitempty"`    // doc and trailing comments, e.g. "Display name shown in the UI."
+	Enum       bool     `json:"enum,omitempty"`       // true if GoType is a named string/integer type declared in the same file
+	EnumValues []string `json:"enumValues,omitempty"` // "enum:a|b": the only values the column accepts
+	Scanner    bool     `json:"scanner,omitempty"`    // true if GoType declares a Scan method in the package
+	Valuer     bool     `json:"valuer,omitempty"`     // true if GoType declares a Value method in the package
+	CustomType bool     `json:"customType,omitempty"` // true if GoType is declared in the package and the driver cannot convert it natively
 }
 
 // RelationInfo holds parsed metadata for a relation field.
 type RelationInfo struct {
-	FieldName        string // Go field name, e.g. "Posts" or "User"
-	TargetType       string // Target struct name, e.g. "Post" or "User"
-	TargetPkgAlias   string // Source file import alias (e.g. "amodel"). Empty for same-package types.
-	TargetImportPath string // Full import path (e.g. "github.com/.../auth/model"). Empty for same-package types.
-	RelType          string // "has_many", "belongs_to", "has_one", or "many_to_many"
-	ForeignKey       string // FK column name, e.g. "user_id"
-	IsSlice          bool   // true for has_many / many_to_many ([]Post)
-	IsPointer        bool   // true for belongs_to / has_one (*User)
-	JoinTable        string // many_to_many only: join table name, e.g. "user_tags"
-	References       string // many_to_many only: target FK in join table, e.g. "tag_id"
+	FieldName        string `json:"fieldName"`                  // Go field name, e.g. "Posts" or "User"
+	TargetType       string `json:"targetType"`                 // Target struct name, e.g. "Post" or "User"
+	TargetPkgAlias   string `json:"targetPkgAlias,omitempty"`   // Source file import alias (e.g. "amodel"). Empty for same-package types.
+	TargetImportPath string `json:"targetImportPath,omitempty"` // Full import path (e.g. "github.com/.../auth/model"). Empty for same-package types.
+	RelType          string `json:"relType"`                    // "has_many", "belongs_to", "has_one", "many_to_many", or "polymorphic"
+	ForeignKey       string `json:"foreignKey,omitempty"`       // FK column name, e.g. "user_id"
+	IsSlice          bool   `json:"isSlice,omitempty"`          // true for has_many / many_to_many ([]Post)
+	IsPointer        bool   `json:"isPointer,omitempty"`        // true for belongs_to / has_one (*User)
+	JoinTable        string `json:"joinTable,omitempty"`        // many_to_many only: join table name, e.g. "user_tags"
+	References       string `json:"references,omitempty"`       // many_to_many only: target FK in join table, e.g. "tag_id"
+	Through          string `json:"through,omitempty"`          // has_many only: relation field the targets are reached through, e.g. "Posts"
+	TypeColumn       string `json:"typeColumn,omitempty"`       // polymorphic only: column naming the target type, e.g. "commentable_type"
+	IDColumn         string `json:"idColumn,omitempty"`         // polymorphic only: column holding the target ID, e.g. "commentable_id"
+	TypeValue        string `json:"typeValue,omitempty"`        // polymorphic only: type column value selecting this target, e.g. "Post"
+	NoPreload        bool   `json:"noPreload,omitempty"`        // "preload:false": no preloader is generated or registered
+	NoJoin           bool   `json:"noJoin,omitempty"`           // "join:false": no JoinConfig is registered and no join scan is generated
 }
 
 // StructInfo holds parsed metadata for the target struct.
 type StructInfo struct {
-	Name      string         // Go struct name, e.g. "User"
-	Package   string         // Package name, e.g. "model"
-	Fields    []FieldInfo    // Non-skipped db fields
-	Relations []RelationInfo // Parsed rel tags
-	TableName string         // Set by the caller (from CLI flag)
+	Name      string         `json:"name"`                // Go struct name, e.g. "User"
+	Package   string         `json:"package"`             // Package name, e.g. "model"
+	Fields    []FieldInfo    `json:"fields"`              // Non-skipped db fields
+	Relations []RelationInfo `json:"relations,omitempty"` // Parsed rel tags
+	TableName string         `json:"tableName"`           // Set by the caller (from CLI flag)
+	Comment   string         `json:"comment,omitempty"`   // doc comment on the type declaration
+	ReadOnly  bool           `json:"readOnly,omitempty"`  // "//ormgen:readonly" directive: no write support, primary key optional
+
+	embeds []embedRef // embedded structs whose fields are not flattened into Fields yet
+}
+
+// embedRef is an embedded struct field, e.g. Base in "type User struct { Base }".
+type embedRef struct {
+	typeName string // "Base"
+	index    int    // position in Fields where its fields belong
 }
 
 // PrimaryKeyField returns the primary key field, or an error if none or
@@ -62,9 +96,56 @@ func (s *StructInfo) PrimaryKeyField() (*FieldInfo, error) {
 	return pk, nil
 }
 
+// PrimaryKeyFields returns every field tagged primaryKey, in declaration
+// order. More than one means a composite primary key.
+func (s *StructInfo) PrimaryKeyFields() []FieldInfo {
+	return filterFields(s.Fields, func(f FieldInfo) bool { return f.PrimaryKey })
+}
+
+// optionalPrimaryKeyField is like PrimaryKeyField but returns nil without
+// an error for a read-only struct that has no primary key, such as a view
+// or a single-column lookup table, and for a struct with a composite
+// primary key, which has no single key field.
+func (s *StructInfo) optionalPrimaryKeyField() (*FieldInfo, error) {
+	n := len(s.PrimaryKeyFields())
+	if (s.ReadOnly && n == 0) || n > 1 {
+		return nil, nil //nolint:nilnil // no single primary key is valid here
+	}
+	return s.PrimaryKeyField()
+}
+
+// ParseOption configures which struct tags the parser reads.
+type ParseOption struct {
+	// Tag is the struct tag key holding column options (default "db").
+	// With "gorm", values use GORM syntax: `gorm:"column:name;primaryKey"`.
+	Tag string
+
+	// RelTag is the struct tag key holding relation options (default "rel").
+	RelTag string
+}
+
+func (o ParseOption) tag() string {
+	if o.Tag == "" {
+		return "db"
+	}
+	return o.Tag
+}
+
+func (o ParseOption) relTag() string {
+	if o.RelTag == "" {
+		return "rel"
+	}
+	return o.RelTag
+}
+
 // Parse reads the Go file at path and returns StructInfo for every struct
 // that has at least one field with a db tag.
 func Parse(filePath string) ([]*StructInfo, error) {
+	return ParseWithOption(filePath, ParseOption{})
+}
+
+// ParseWithOption is like Parse but reads the struct tag keys given in opt.
+func ParseWithOption(filePath string, opt ParseOption) ([]*StructInfo, error) {
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
 	if err != nil {
@@ -73,9 +154,24 @@ func Parse(filePath string) ([]*StructInfo, error) {
 
 	pkg := file.Name.Name
 	importMap := buildImportMap(file)
+	enums := enumTypes(file)
+	aliases := aliasTypes(file)
+	types := newColumnTypes()
+	types.add(file)
 	var infos []*StructInfo
+	var declDoc *ast.CommentGroup
+	var fieldErr error
 
 	ast.Inspect(file, func(n ast.Node) bool {
+		if gd, ok := n.(*ast.GenDecl); ok {
+			// A lone "type X struct" keeps its doc comment on the GenDecl.
+			declDoc = nil
+			if len(gd.Specs) == 1 {
+				declDoc = gd.Doc
+			}
+			return true
+		}
+
 		ts, ok := n.(*ast.TypeSpec)
 		if !ok {
 			return true
@@ -86,40 +182,423 @@ func Parse(filePath string) ([]*StructInfo, error) {
 			return true
 		}
 
-		fields := parseStructFields(st)
-		relations := parseRelations(st, importMap)
-		if len(fields) == 0 {
+		fields, embeds := parseStructFields(st, opt)
+		relations := parseRelations(st, importMap, opt)
+		if len(fields) == 0 && len(embeds) == 0 {
 			return true
 		}
+		for i := range fields {
+			fields[i].GoType = resolveTypeAlias(aliases, fields[i].GoType)
+			fields[i].Enum = enums[fields[i].GoType] != ""
+			if len(fields[i].EnumValues) > 0 && enums[fields[i].GoType] != "string" && fieldErr == nil {
+				fieldErr = fmt.Errorf("%s.%s: enum values require a named string type declared in the same file, got %s",
+					ts.Name.Name, fields[i].Name, fields[i].GoType)
+			}
+			types.resolve(&fields[i])
+			base := strings.TrimPrefix(fields[i].GoType, "*")
+			if pkgName, _, ok := strings.Cut(strings.TrimLeft(base, "[]*"), "."); ok {
+				fields[i].TypeImport = importMap[pkgName]
+			}
+		}
+
+		doc := ts.Doc
+		if doc == nil {
+			doc = declDoc
+		}
 
 		infos = append(infos, &StructInfo{
 			Name:      ts.Name.Name,
 			Package:   pkg,
 			Fields:    fields,
 			Relations: relations,
+			Comment:   commentText(doc),
+			ReadOnly:  hasDirective(doc, "ormgen:readonly"),
+			embeds:    embeds,
 		})
 		return true
 	})
+	if fieldErr != nil {
+		return nil, fieldErr
+	}
+
+	// Structs embedded by another struct of the file are flattened into it
+	// and not generated themselves.
+	embedded := FlattenEmbedded(infos, nil)
+	infos = slices.DeleteFunc(infos, func(info *StructInfo) bool { return embedded[info.Name] })
 
 	return infos, nil
 }
 
-// parseStructFields extracts db-tagged fields from an AST struct type.
-func parseStructFields(st *ast.StructType) []FieldInfo {
+// hasDirective reports whether doc contains the "//name" directive line.
+// Directives are not part of doc.Text().
+func hasDirective(doc *ast.CommentGroup, name string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == "//"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// enumTypes maps the names of types declared in file whose underlying type
+// is a builtin string or integer to that builtin, e.g. "type Status string"
+// → "Status": "string". Aliases ("type X = string") are not enums.
+func enumTypes(file *ast.File) map[string]string {
+	enums := make(map[string]string)
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Assign.IsValid() || ts.TypeParams != nil {
+				continue
+			}
+			if ident, ok := ts.Type.(*ast.Ident); ok && (ident.Name == "string" || isIntType(ident.Name)) {
+				enums[ts.Name.Name] = ident.Name
+			}
+		}
+	}
+	return enums
+}
+
+// ColumnTypes indexes the named types a package declares and the Scan and
+// Value methods declared on them, which may live in different files, so
+// that custom column types can be checked against sql.Scanner and
+// driver.Valuer at build time.
+type ColumnTypes struct {
+	underlying map[string]ast.Expr        // type name → underlying type expression
+	methods    map[string]map[string]bool // receiver type → declared Scan/Value
+}
+
+func newColumnTypes() *ColumnTypes {
+	return &ColumnTypes{underlying: make(map[string]ast.Expr), methods: make(map[string]map[string]bool)}
+}
+
+// ParseColumnTypes indexes the types declared in the file at filePath and
+// in its peers, the files ParsePeers would read. Files that fail to parse
+// are skipped.
+func ParseColumnTypes(filePath string, opt PeerOption) *ColumnTypes {
+	types := newColumnTypes()
+	dir, base := filepath.Dir(filePath), filepath.Base(filePath)
+	for _, path := range append([]string{filePath}, peerFiles(dir, base, opt)...) {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			continue
+		}
+		types.add(file)
+	}
+	return types
+}
+
+// add records the type declarations and the Scan and Value methods of file,
+// on either a value or a pointer receiver. Aliases and generic types are
+// not recorded.
+func (c *ColumnTypes) add(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && !ts.Assign.IsValid() && ts.TypeParams == nil {
+					c.underlying[ts.Name.Name] = ts.Type
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) != 1 {
+				continue
+			}
+			if name := d.Name.Name; name != "Scan" && name != "Value" {
+				continue
+			}
+			recv := strings.TrimPrefix(typeToString(d.Recv.List[0].Type), "*")
+			if c.methods[recv] == nil {
+				c.methods[recv] = make(map[string]bool)
+			}
+			c.methods[recv][d.Name.Name] = true
+		}
+	}
+}
+
+// Resolve sets Scanner, Valuer and CustomType on the fields of infos from
+// the types indexed across the package, for column types declared in a
+// file other than the model's.
+func (c *ColumnTypes) Resolve(infos []*StructInfo) {
+	for _, info := range infos {
+		for i := range info.Fields {
+			c.resolve(&info.Fields[i])
+		}
+	}
+}
+
+func (c *ColumnTypes) resolve(f *FieldInfo) {
+	base := strings.TrimPrefix(f.GoType, "*")
+	f.Scanner = f.Scanner || c.methods[base]["Scan"]
+	f.Valuer = f.Valuer || c.methods[base]["Value"]
+	f.CustomType = f.CustomType || c.isCustom(base)
+}
+
+// isCustom reports whether name is a type declared in the package whose
+// underlying type is known and not one the driver converts by kind:
+// booleans, numbers, strings and byte slices. A type defined on another
+// package's type, e.g. "type Money decimal.Decimal", is not custom, since
+// its kind cannot be told from the source.
+func (c *ColumnTypes) isCustom(name string) bool {
+	// Bounded by len(c.underlying) so that an invalid cycle cannot loop forever.
+	for range len(c.underlying) {
+		expr, ok := c.underlying[name]
+		if !ok {
+			return false
+		}
+		switch t := expr.(type) {
+		case *ast.Ident:
+			if isNativeKind(t.Name) {
+				return false
+			}
+			name = t.Name
+		case *ast.ArrayType:
+			return t.Len != nil || !isNativeKind("[]"+typeToString(t.Elt))
+		case *ast.StructType, *ast.MapType:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// isNativeKind reports whether a type with the given underlying builtin is
+// converted by database/sql itself, through reflection on its kind.
+func isNativeKind(goType string) bool {
+	switch goType {
+	case "bool", "string", "float32", "float64", "[]byte", "[]uint8":
+		return true
+	default:
+		return isIntType(goType)
+	}
+}
+
+// aliasTypes returns the type aliases declared in file ("type X = string"),
+// mapped to the aliased type.
+func aliasTypes(file *ast.File) map[string]string {
+	aliases := make(map[string]string)
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || !ts.Assign.IsValid() || ts.TypeParams != nil {
+				continue
+			}
+			aliases[ts.Name.Name] = typeToString(ts.Type)
+		}
+	}
+	return aliases
+}
+
+// resolveTypeAlias replaces a same-file alias with the aliased type,
+// following chains of aliases. An alias denotes the identical type, so the
+// generated code keeps its meaning while PK, nullability and DDL decisions
+// see the underlying type.
+//
+//	type Code = string       // "Code" → "string"
+//	type MaybeCode = *Code   // "MaybeCode" → "*Code" (pointers are not followed)
+func resolveTypeAlias(aliases map[string]string, goType string) string {
+	// Bounded by len(aliases) so that an invalid cycle cannot loop forever.
+	for range len(aliases) {
+		target, ok := aliases[goType]
+		if !ok {
+			break
+		}
+		goType = target
+	}
+	return goType
+}
+
+// PeerOption controls which files ParsePeers considers.
+type PeerOption struct {
+	ParseOption
+
+	// IncludeTests includes _test.go files as peers.
+	IncludeTests bool
+
+	// BuildTags, when non-nil, enables build constraint evaluation: files
+	// whose //go:build line is not satisfied by these tags (plus the host
+	// GOOS and GOARCH) are skipped. When nil, constraints are ignored.
+	BuildTags []string
+}
+
+// ParsePeers parses the .go files in dir except excludeBase and returns
+// their StructInfos. Generated files (_gen.go, _gen_test.go) are always
+// skipped. Errors are silently ignored (peers are best-effort).
+func ParsePeers(dir, excludeBase string, opt PeerOption) []*StructInfo {
+	var peers []*StructInfo
+	for _, path := range peerFiles(dir, excludeBase, opt) {
+		peerInfos, err := ParseWithOption(path, opt.ParseOption)
+		if err != nil {
+			continue
+		}
+		peers = append(peers, peerInfos...)
+	}
+	return peers
+}
+
+// peerFiles returns the paths of the .go files in dir that ParsePeers reads.
+func peerFiles(dir, excludeBase string, opt PeerOption) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || name == excludeBase {
+			continue
+		}
+		if strings.HasSuffix(name, "_gen.go") || strings.HasSuffix(name, "_gen_test.go") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !opt.IncludeTests {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if opt.BuildTags != nil {
+			ok, err := MatchBuildTags(path, opt.BuildTags)
+			if err != nil || !ok {
+				continue
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// MatchBuildTags reports whether the //go:build constraint of the file at
+// filePath is satisfied by tags plus the host GOOS and GOARCH.
+// Files without a constraint always match.
+func MatchBuildTags(filePath string, tags []string) (bool, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("parse file: %w", err)
+	}
+
+	enabled := map[string]bool{runtime.GOOS: true, runtime.GOARCH: true}
+	for _, tag := range tags {
+		enabled[tag] = true
+	}
+
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				return false, fmt.Errorf("parse build constraint: %w", err)
+			}
+			return expr.Eval(func(tag string) bool { return enabled[tag] }), nil
+		}
+	}
+	return true, nil
+}
+
+// parseStructFields extracts db-tagged fields from an AST struct type,
+// along with the struct types it embeds by value from its own package,
+// whose fields FlattenEmbedded adds later.
+func parseStructFields(st *ast.StructType, opt ParseOption) ([]FieldInfo, []embedRef) {
 	fields := make([]FieldInfo, 0, len(st.Fields.List))
+	var embeds []embedRef
 	for _, field := range st.Fields.List {
-		fi, skip := parseField(field)
+		if ident, ok := field.Type.(*ast.Ident); ok && len(field.Names) == 0 && ident.IsExported() {
+			if field.Tag == nil || reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get(opt.tag()) != "-" {
+				embeds = append(embeds, embedRef{typeName: ident.Name, index: len(fields)})
+			}
+			continue
+		}
+		fi, skip := parseField(field, opt)
 		if skip {
 			continue
 		}
 		fields = append(fields, fi)
 	}
-	return fields
+	return fields, embeds
 }
 
-func parseField(field *ast.Field) (FieldInfo, bool) {
+// FlattenEmbedded adds the fields of embedded structs to the structs in
+// infos that embed them, in place of the embedded field. Embedded structs
+// are looked up among infos and peers, and may embed others in turn. As
+// with Go's field promotion, a field of the outer struct wins over an
+// embedded one with the same name, or here the same column. Embeds that
+// cannot be resolved are kept for a later call with more peers.
+//
+// It returns the names of the structs in infos that another struct in
+// infos embeds.
+func FlattenEmbedded(infos, peers []*StructInfo) map[string]bool {
+	byName := make(map[string]*StructInfo, len(infos)+len(peers))
+	for _, info := range slices.Concat(peers, infos) {
+		byName[info.Name] = info
+	}
+	embedded := make(map[string]bool)
+	for _, info := range infos {
+		for _, e := range info.embeds {
+			if slices.ContainsFunc(infos, func(s *StructInfo) bool { return s.Name == e.typeName }) {
+				embedded[e.typeName] = true
+			}
+		}
+	}
+	for _, info := range infos {
+		flattenEmbeds(info, byName, map[string]bool{info.Name: true})
+	}
+	return embedded
+}
+
+// flattenEmbeds resolves info's embeds from byName. visiting guards
+// against embedding cycles, which Go rejects but the parser may still see.
+func flattenEmbeds(info *StructInfo, byName map[string]*StructInfo, visiting map[string]bool) {
+	if len(info.embeds) == 0 {
+		return
+	}
+	own := info.Fields
+	var fields []FieldInfo
+	var unresolved []embedRef
+	next := 0
+	for _, e := range info.embeds {
+		fields = append(fields, own[next:e.index]...)
+		next = e.index
+		base, ok := byName[e.typeName]
+		if !ok || visiting[e.typeName] {
+			unresolved = append(unresolved, embedRef{typeName: e.typeName, index: len(fields)})
+			continue
+		}
+		visiting[e.typeName] = true
+		flattenEmbeds(base, byName, visiting)
+		delete(visiting, e.typeName)
+		for _, f := range base.Fields {
+			shadowed := func(o FieldInfo) bool { return o.Name == f.Name || o.Column == f.Column }
+			if !slices.ContainsFunc(own, shadowed) && !slices.ContainsFunc(fields, shadowed) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	info.Fields = append(fields, own[next:]...)
+	info.embeds = unresolved
+}
+
+func parseField(field *ast.Field, opt ParseOption) (FieldInfo, bool) {
 	if len(field.Names) == 0 {
-		return FieldInfo{}, true // embedded field, skip
+		return FieldInfo{}, true // embedded from another package or by pointer, skip
 	}
 
 	name := field.Names[0].Name
@@ -136,11 +615,17 @@ func parseField(field *ast.Field) (FieldInfo, bool) {
 	primaryKey := name == "ID"
 	createdAt := name == "CreatedAt"
 	updatedAt := name == "UpdatedAt"
+	deletedAt := name == "DeletedAt" && isNullableGoType(goType)
+	server := false
+	version := false
+	generated := false
+	readOnly := false
+	immutable := false
 
 	// Skip relation fields — they are handled by parseRelations.
 	if field.Tag != nil {
 		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
-		if _, ok := tag.Lookup("rel"); ok {
+		if _, ok := tag.Lookup(opt.relTag()); ok {
 			return FieldInfo{}, true
 		}
 	}
@@ -152,25 +637,42 @@ func parseField(field *ast.Field) (FieldInfo, bool) {
 		return FieldInfo{}, true
 	}
 
+	var enumValues []string
 	// Override with db tag if present.
 	if field.Tag != nil {
 		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
-		if dbTag, ok := tag.Lookup("db"); ok {
+		if dbTag, ok := tag.Lookup(opt.tag()); ok {
 			if dbTag == "-" {
 				return FieldInfo{}, true // explicitly skipped
 			}
-			parts := strings.Split(dbTag, ",")
-			if parts[0] != "" {
-				column = parts[0]
+			tagColumn, tagOpts := splitColumnTag(opt.tag(), dbTag)
+			if tagColumn != "" {
+				column = tagColumn
 			}
-			for _, opt := range parts[1:] {
-				switch opt {
+			for _, tagOpt := range tagOpts {
+				switch tagOpt {
 				case "primaryKey":
 					primaryKey = true
 				case "createdAt":
 					createdAt = true
 				case "updatedAt":
 					updatedAt = true
+				case "deletedAt":
+					deletedAt = true
+				case "version":
+					version = true
+				case "generated":
+					generated = true
+				case "server":
+					server = true
+				case "readonly":
+					readOnly = true
+				case "immutable":
+					immutable = true
+				default:
+					if values, ok := strings.CutPrefix(tagOpt, "enum:"); ok {
+						enumValues = strings.Split(values, "|")
+					}
 				}
 			}
 		}
@@ -183,11 +685,70 @@ func parseField(field *ast.Field) (FieldInfo, bool) {
 		PrimaryKey: primaryKey,
 		CreatedAt:  createdAt,
 		UpdatedAt:  updatedAt,
+		Server:     server && (createdAt || updatedAt),
+		DeletedAt:  deletedAt,
+		Version:    version,
+		Generated:  generated,
+		ReadOnly:   readOnly,
+		Immutable:  immutable,
+		EnumValues: enumValues,
+		Comment:    commentText(field.Doc, field.Comment),
 	}, false
 }
 
+// splitColumnTag splits a column tag value into the column name (possibly
+// empty) and its options, normalized to db tag option names.
+//
+//	db:"user_id,primaryKey"          → "user_id", [primaryKey]
+//	gorm:"column:user_id;primaryKey" → "user_id", [primaryKey]
+//	gorm:"->"                        → "", [readonly]
+//	gorm:"<-:create"                 → "", [immutable]
+func splitColumnTag(key, value string) (string, []string) {
+	if key != "gorm" {
+		parts := strings.Split(value, ",")
+		return parts[0], parts[1:]
+	}
+
+	var column string
+	var opts []string
+	for part := range strings.SplitSeq(value, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), ":")
+		switch strings.ToLower(k) {
+		case "column":
+			column = v
+		case "primarykey", "primary_key":
+			opts = append(opts, "primaryKey")
+		case "autocreatetime":
+			opts = append(opts, "createdAt")
+		case "autoupdatetime":
+			opts = append(opts, "updatedAt")
+		case "->":
+			if v == "" {
+				opts = append(opts, "readonly")
+			}
+		case "<-":
+			if v == "create" {
+				opts = append(opts, "immutable")
+			}
+		}
+	}
+	return column, opts
+}
+
+// commentText joins the text of the given comment groups, dropping nil
+// groups and surrounding whitespace.
+func commentText(groups ...*ast.CommentGroup) string {
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if text := strings.TrimSpace(g.Text()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 // parseRelations extracts rel-tagged fields from an AST struct type.
-func parseRelations(st *ast.StructType, importMap map[string]string) []RelationInfo {
+func parseRelations(st *ast.StructType, importMap map[string]string, opt ParseOption) []RelationInfo {
 	rels := make([]RelationInfo, 0, len(st.Fields.List))
 	for _, field := range st.Fields.List {
 		if len(field.Names) == 0 || field.Tag == nil {
@@ -195,7 +756,7 @@ func parseRelations(st *ast.StructType, importMap map[string]string) []RelationI
 		}
 
 		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
-		relTag, ok := tag.Lookup("rel")
+		relTag, ok := tag.Lookup(opt.relTag())
 		if !ok || relTag == "" {
 			continue
 		}
@@ -204,6 +765,13 @@ func parseRelations(st *ast.StructType, importMap map[string]string) []RelationI
 
 		// Parse rel tag: "has_many,foreign_key:user_id" or
 		// "many_to_many,join_table:user_tags,foreign_key:user_id,references:tag_id"
+		// "has_many,through:Posts,foreign_key:post_id" reaches the targets
+		// through another has_many relation of the same struct.
+		// "polymorphic,type_column:commentable_type,id_column:commentable_id"
+		// loads the target for rows whose type column holds "type_value:",
+		// which defaults to the target type name.
+		// "preload:false" and "join:false" opt out of the generated preloader
+		// and join registration respectively.
 		for part := range strings.SplitSeq(relTag, ",") {
 			part = strings.TrimSpace(part)
 			if k, v, found := strings.Cut(part, ":"); found {
@@ -214,6 +782,18 @@ func parseRelations(st *ast.StructType, importMap map[string]string) []RelationI
 					ri.JoinTable = v
 				case "references":
 					ri.References = v
+				case "through":
+					ri.Through = v
+				case "type_column":
+					ri.TypeColumn = v
+				case "id_column":
+					ri.IDColumn = v
+				case "type_value":
+					ri.TypeValue = v
+				case "preload":
+					ri.NoPreload = v == "false"
+				case "join":
+					ri.NoJoin = v == "false"
 				}
 			} else {
 				ri.RelType = part
@@ -232,6 +812,16 @@ func parseRelations(st *ast.StructType, importMap map[string]string) []RelationI
 			}
 		}
 
+		if ri.RelType == "polymorphic" {
+			if ri.TypeColumn == "" || ri.IDColumn == "" || ri.TargetType == "" || !ri.IsPointer {
+				continue
+			}
+			if ri.TypeValue == "" {
+				ri.TypeValue = ri.TargetType
+			}
+			rels = append(rels, ri)
+			continue
+		}
 		if ri.RelType == "" || ri.ForeignKey == "" || ri.TargetType == "" {
 			continue
 		}
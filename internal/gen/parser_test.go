@@ -2,7 +2,9 @@ package gen_test
 
 import (
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/mickamy/ormgen/internal/gen"
@@ -116,6 +118,23 @@ func TestParseNoPrimaryKey(t *testing.T) {
 	}
 }
 
+func TestParseCompositePrimaryKey(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("composite_pk.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	pks := infos[0].PrimaryKeyFields()
+	if len(pks) != 2 || pks[0].Column != "tenant_id" || pks[1].Column != "user_id" {
+		t.Errorf("PrimaryKeyFields = %+v, want tenant_id and user_id", pks)
+	}
+	if _, err := infos[0].PrimaryKeyField(); err == nil {
+		t.Error("PrimaryKeyField: expected error for composite primary key, got nil")
+	}
+}
+
 func TestParseInferredColumns(t *testing.T) {
 	t.Parallel()
 
@@ -152,6 +171,225 @@ func TestParseInferredColumns(t *testing.T) {
 	}
 }
 
+func TestParseColumnAccess(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("column_access.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		field         string
+		wantReadOnly  bool
+		wantImmutable bool
+	}{
+		{"ID", false, false},
+		{"Number", false, true},
+		{"Total", false, false},
+		{"TotalWithTax", true, false},
+		{"CreatedAt", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			for _, f := range findStruct(t, infos, "Order").Fields {
+				if f.Name == tt.field {
+					if f.ReadOnly != tt.wantReadOnly || f.Immutable != tt.wantImmutable {
+						t.Errorf("ReadOnly, Immutable = %v, %v, want %v, %v",
+							f.ReadOnly, f.Immutable, tt.wantReadOnly, tt.wantImmutable)
+					}
+					return
+				}
+			}
+			t.Fatalf("field %s not found", tt.field)
+		})
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("enum_values.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	member := findStruct(t, infos, "Member")
+	tests := []struct {
+		field int
+		want  []string
+	}{
+		{1, []string{"active", "inactive", "banned"}},
+		{2, nil},
+	}
+	for _, tt := range tests {
+		f := member.Fields[tt.field]
+		if !reflect.DeepEqual(f.EnumValues, tt.want) {
+			t.Errorf("%s.EnumValues = %v, want %v", f.Name, f.EnumValues, tt.want)
+		}
+		if !f.Enum {
+			t.Errorf("%s.Enum = false, want true", f.Name)
+		}
+	}
+	if got := member.Fields[1].Column; got != "status" {
+		t.Errorf("Status column = %q, want %q", got, "status")
+	}
+}
+
+func TestParseEnumValuesRequireStringType(t *testing.T) {
+	t.Parallel()
+
+	_, err := gen.Parse(testdataPath("enum_values_invalid.go"))
+	if err == nil || !strings.Contains(err.Error(), "Badge.Level: enum values require a named string type") {
+		t.Errorf("err = %v, want an enum type error", err)
+	}
+}
+
+func TestParseEmbeddedStructs(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("embedded.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	if want := []string{"Note", "Revision", "Draft"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("structs = %v, want %v (embedded bases are not generated)", names, want)
+	}
+
+	tests := []struct {
+		structName string
+		want       []string // Name:column, with * marking the primary key
+	}{
+		{"Note", []string{"*ID:id", "CreatedAt:created_at", "UpdatedAt:updated_at", "Title:title", "Body:body"}},
+		{"Revision", []string{"*ID:id", "UpdatedAt:updated_at", "UpdatedBy:updated_by", "Note:note", "CreatedAt:recorded_at"}},
+		{"Draft", []string{"*Key:key", "Title:title"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.structName, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, f := range findStruct(t, infos, tt.structName).Fields {
+				mark := ""
+				if f.PrimaryKey {
+					mark = "*"
+				}
+				got = append(got, mark+f.Name+":"+f.Column)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	revision := findStruct(t, infos, "Revision")
+	for _, f := range revision.Fields {
+		if f.Name == "CreatedAt" && (!f.CreatedAt || f.Column != "recorded_at") {
+			t.Errorf("Revision.CreatedAt = %+v, want the outer field as createdAt", f)
+		}
+	}
+}
+
+func TestFlattenEmbeddedFromPeers(t *testing.T) {
+	t.Parallel()
+
+	base := &gen.StructInfo{Name: "Base", Fields: []gen.FieldInfo{
+		{Name: "ID", Column: "id", GoType: "int64", PrimaryKey: true},
+	}}
+	infos, err := gen.Parse(testdataPath("embedded.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	draft := findStruct(t, infos, "Draft")
+	before := len(draft.Fields)
+
+	// Already flattened structs and opted-out embeds are left alone.
+	if embedded := gen.FlattenEmbedded(infos, []*gen.StructInfo{base}); len(embedded) != 0 {
+		t.Errorf("embedded = %v, want none", embedded)
+	}
+	if len(draft.Fields) != before {
+		t.Errorf("Draft fields = %+v, want unchanged", draft.Fields)
+	}
+}
+
+func TestParseServerTimestamps(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("server_timestamps.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		structName string
+		field      string
+		wantServer bool
+	}{
+		{"Event", "Name", false},
+		{"Event", "CreatedAt", true},
+		{"Event", "UpdatedAt", false},
+		{"Ledger", "RecordedAt", true},
+		{"Ledger", "SettledAt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.structName+"."+tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			for _, f := range findStruct(t, infos, tt.structName).Fields {
+				if f.Name == tt.field {
+					if f.Server != tt.wantServer {
+						t.Errorf("Server = %v, want %v", f.Server, tt.wantServer)
+					}
+					return
+				}
+			}
+			t.Fatalf("field %s not found", tt.field)
+		})
+	}
+}
+
+func TestParseSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("soft_delete.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		structName    string
+		field         string
+		wantDeletedAt bool
+	}{
+		{"Account", "Name", false},
+		{"Account", "DeletedAt", true},
+		{"Archive", "RemovedAt", true},
+		{"Audit", "DeletedAt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.structName+"."+tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			for _, f := range findStruct(t, infos, tt.structName).Fields {
+				if f.Name == tt.field {
+					if f.DeletedAt != tt.wantDeletedAt {
+						t.Errorf("DeletedAt = %v, want %v", f.DeletedAt, tt.wantDeletedAt)
+					}
+					return
+				}
+			}
+			t.Fatalf("field %s not found", tt.field)
+		})
+	}
+}
+
 func TestParseTimestamps(t *testing.T) {
 	t.Parallel()
 
@@ -255,9 +493,12 @@ func TestParseCustomTypes(t *testing.T) {
 		}
 
 		f := info.Fields[2]
-		if f.Name != "Topics" || f.Column != "topics" || f.GoType != "StringArray" {
+		if f.Name != "Topics" || f.Column != "topics" || f.GoType != "StringArray" || !f.CustomType {
 			t.Errorf("Fields[2] = %+v", f)
 		}
+		if info.Fields[1].CustomType {
+			t.Errorf("Fields[1] = %+v, want builtin string not custom", info.Fields[1])
+		}
 	})
 
 	t.Run("NoTagCustomType convention", func(t *testing.T) {
@@ -296,8 +537,8 @@ func TestParseRelations(t *testing.T) { //nolint:gocyclo // test function with m
 		t.Fatalf("Parse: %v", err)
 	}
 
-	if len(infos) != 6 {
-		t.Fatalf("len(infos) = %d, want 6", len(infos))
+	if len(infos) != 7 {
+		t.Fatalf("len(infos) = %d, want 7", len(infos))
 	}
 
 	t.Run("Author has_many Articles, has_one Profile, many_to_many Tags", func(t *testing.T) {
@@ -471,3 +712,375 @@ func TestParseInvalidFile(t *testing.T) {
 		t.Fatal("expected error for invalid file, got nil")
 	}
 }
+
+func TestParsePeers(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Dir(testdataPath("peers/article.go"))
+
+	names := func(infos []*gen.StructInfo) []string {
+		out := make([]string, len(infos))
+		for i, info := range infos {
+			out[i] = info.Name
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		opt  gen.PeerOption
+		want []string
+	}{
+		{
+			name: "default ignores build constraints and skips tests",
+			opt:  gen.PeerOption{},
+			want: []string{"Author", "Tagged"},
+		},
+		{
+			name: "include tests",
+			opt:  gen.PeerOption{IncludeTests: true},
+			want: []string{"Author", "Fixture", "Tagged"},
+		},
+		{
+			name: "build tags not satisfied",
+			opt:  gen.PeerOption{BuildTags: []string{}},
+			want: []string{"Author"},
+		},
+		{
+			name: "build tags satisfied",
+			opt:  gen.PeerOption{BuildTags: []string{"integration"}},
+			want: []string{"Author", "Tagged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := names(gen.ParsePeers(dir, "article.go", tt.opt))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParsePeers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBuildTaggedFile(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("peers/tagged.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "Tagged" {
+		t.Fatalf("infos = %+v, want [Tagged]", infos)
+	}
+}
+
+func TestMatchBuildTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		file string
+		tags []string
+		want bool
+	}{
+		{"peers/author.go", nil, true},
+		{"peers/tagged.go", nil, false},
+		{"peers/tagged.go", []string{"integration"}, true},
+		{"peers/tagged.go", []string{"other"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+strings.Join(tt.tags, ","), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := gen.MatchBuildTags(testdataPath(tt.file), tt.tags)
+			if err != nil {
+				t.Fatalf("MatchBuildTags: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchBuildTags(%s, %v) = %v, want %v", tt.file, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("comments.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	account := findStructInInfos(t, infos, "Account")
+	if account.Comment != "Account is a billing account." {
+		t.Errorf("Account.Comment = %q", account.Comment)
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"ID", "surrogate key"},
+		{"Name", "Owner's display name."},
+		{"Balance", "Balance in cents.\nnever negative"},
+		{"Plan", ""},
+	}
+	for _, tt := range tests {
+		var got *gen.FieldInfo
+		for i := range account.Fields {
+			if account.Fields[i].Name == tt.field {
+				got = &account.Fields[i]
+			}
+		}
+		if got == nil {
+			t.Fatalf("field %q not found", tt.field)
+		}
+		if got.Comment != tt.want {
+			t.Errorf("%s.Comment = %q, want %q", tt.field, got.Comment, tt.want)
+		}
+	}
+
+	invoice := findStructInInfos(t, infos, "Invoice")
+	if invoice.Comment != "Invoice is issued monthly." {
+		t.Errorf("Invoice.Comment = %q", invoice.Comment)
+	}
+}
+
+func TestParseEnumFields(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("enums.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	ticket := findStructInInfos(t, infos, "Ticket")
+	want := map[string]bool{
+		"ID":       false,
+		"Status":   true,
+		"Priority": true,
+		"Label":    false, // alias of string
+		"Tags":     false, // named slice
+		"Title":    false,
+	}
+	for _, f := range ticket.Fields {
+		w, ok := want[f.Name]
+		if !ok {
+			continue
+		}
+		if f.Enum != w {
+			t.Errorf("%s.Enum = %v, want %v", f.Name, f.Enum, w)
+		}
+	}
+}
+
+func TestParseReadOnlyDirective(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("readonly.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tag := findStructInInfos(t, infos, "Tag")
+	if !tag.ReadOnly {
+		t.Error("Tag.ReadOnly = false, want true")
+	}
+	if want := "Tag is a single-column lookup table."; tag.Comment != want {
+		t.Errorf("Tag.Comment = %q, want %q", tag.Comment, want)
+	}
+
+	users, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if users[0].ReadOnly {
+		t.Errorf("%s.ReadOnly = true, want false", users[0].Name)
+	}
+}
+
+func TestParseTypeAliases(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("aliases.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	account := findStructInInfos(t, infos, "Account")
+	want := map[string]string{
+		"ID":        "int64",
+		"Handle":    "string", // alias of an alias
+		"Nickname":  "sql.NullString",
+		"CreatedAt": "time.Time",
+	}
+	if len(account.Fields) != len(want) {
+		t.Fatalf("len(Fields) = %d, want %d", len(account.Fields), len(want))
+	}
+	for _, f := range account.Fields {
+		if f.GoType != want[f.Name] {
+			t.Errorf("%s.GoType = %q, want %q", f.Name, f.GoType, want[f.Name])
+		}
+		if f.Enum {
+			t.Errorf("%s.Enum = true, want false for an alias", f.Name)
+		}
+	}
+}
+
+func TestParseRelationOptions(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relation_opts.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	author := findStructInInfos(t, infos, "Author")
+	want := map[string]struct{ noPreload, noJoin bool }{
+		"Books":    {noPreload: true},
+		"Profile":  {noJoin: true},
+		"Archived": {noPreload: true, noJoin: true},
+		"Awards":   {noPreload: true},
+		"Mentor":   {noPreload: true, noJoin: true},
+	}
+	if len(author.Relations) != len(want) {
+		t.Fatalf("len(Relations) = %d, want %d", len(author.Relations), len(want))
+	}
+	for _, rel := range author.Relations {
+		w := want[rel.FieldName]
+		if rel.NoPreload != w.noPreload || rel.NoJoin != w.noJoin {
+			t.Errorf("%s: NoPreload=%v NoJoin=%v, want %v %v",
+				rel.FieldName, rel.NoPreload, rel.NoJoin, w.noPreload, w.noJoin)
+		}
+	}
+	for _, f := range author.Fields {
+		switch f.Name {
+		case "Books", "Profile", "Archived", "Awards", "Mentor":
+			t.Errorf("relation field %s should not be a column", f.Name)
+		}
+	}
+}
+
+func TestParseThroughRelations(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("through.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	author := findStructInInfos(t, infos, "Author")
+	if len(author.Relations) != 2 {
+		t.Fatalf("len(Relations) = %d, want 2", len(author.Relations))
+	}
+	if got := author.Relations[0]; got.FieldName != "Posts" || got.Through != "" {
+		t.Errorf("Relations[0] = %+v, want Posts without Through", got)
+	}
+	got := author.Relations[1]
+	if got.FieldName != "Comments" || got.RelType != "has_many" || got.Through != "Posts" ||
+		got.ForeignKey != "post_id" || got.TargetType != "Comment" || !got.IsSlice {
+		t.Errorf("Relations[1] = %+v, want has_many Comments through Posts on post_id", got)
+	}
+}
+
+func TestParsePolymorphicRelations(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("polymorphic.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	comment := findStructInInfos(t, infos, "Comment")
+	if len(comment.Relations) != 2 {
+		t.Fatalf("len(Relations) = %d, want 2", len(comment.Relations))
+	}
+	tests := []struct {
+		field, target, typeValue string
+	}{
+		{"Post", "Post", "Post"},    // type_value defaults to the target type
+		{"Photo", "Photo", "photo"}, // explicit type_value
+	}
+	for i, tt := range tests {
+		got := comment.Relations[i]
+		if got.FieldName != tt.field || got.RelType != "polymorphic" || got.TargetType != tt.target || !got.IsPointer {
+			t.Errorf("Relations[%d] = %+v, want polymorphic *%s", i, got, tt.target)
+		}
+		if got.TypeColumn != "commentable_type" || got.IDColumn != "commentable_id" || got.TypeValue != tt.typeValue {
+			t.Errorf("Relations[%d] columns = %q/%q/%q, want commentable_type/commentable_id/%s",
+				i, got.TypeColumn, got.IDColumn, got.TypeValue, tt.typeValue)
+		}
+		if got.ForeignKey != "" {
+			t.Errorf("Relations[%d].ForeignKey = %q, want empty", i, got.ForeignKey)
+		}
+	}
+}
+
+func TestParseWithAlternativeTags(t *testing.T) {
+	t.Parallel()
+
+	path := testdataPath("alt_tags.go")
+
+	gormInfos, err := gen.ParseWithOption(path, gen.ParseOption{Tag: "gorm", RelTag: "assoc"})
+	if err != nil {
+		t.Fatalf("ParseWithOption: %v", err)
+	}
+	invoice := findStructInInfos(t, gormInfos, "Invoice")
+
+	columns := make(map[string]gen.FieldInfo, len(invoice.Fields))
+	for _, f := range invoice.Fields {
+		columns[f.Name] = f
+	}
+	if _, ok := columns["Memo"]; ok {
+		t.Error(`Memo tagged gorm:"-" should be skipped`)
+	}
+	if got := columns["Key"]; got.Column != "invoice_key" || !got.PrimaryKey {
+		t.Errorf("Key = %+v, want column invoice_key, primary key", got)
+	}
+	if got := columns["Total"].Column; got != "total_cents" {
+		t.Errorf("Total.Column = %q, want %q (db tag must be ignored)", got, "total_cents")
+	}
+	if got := columns["Issued"]; got.Column != "issued" || !got.CreatedAt {
+		t.Errorf("Issued = %+v, want column issued, createdAt", got)
+	}
+	if got := columns["Number"]; !got.Immutable || got.ReadOnly {
+		t.Errorf(`Number = %+v, want immutable for gorm:"<-:create"`, got)
+	}
+	if got := columns["Balance"]; !got.ReadOnly || got.Immutable {
+		t.Errorf(`Balance = %+v, want readonly for gorm:"->"`, got)
+	}
+	if len(invoice.Relations) != 1 || invoice.Relations[0].FieldName != "Customer" {
+		t.Fatalf("Relations = %+v, want Customer", invoice.Relations)
+	}
+
+	sqlInfos, err := gen.ParseWithOption(path, gen.ParseOption{Tag: "sql"})
+	if err != nil {
+		t.Fatalf("ParseWithOption: %v", err)
+	}
+	customer := findStructInInfos(t, sqlInfos, "Customer")
+	pk, err := customer.PrimaryKeyField()
+	if err != nil {
+		t.Fatalf("PrimaryKeyField: %v", err)
+	}
+	if pk.Name != "ID" {
+		t.Errorf("pk = %s, want ID", pk.Name)
+	}
+	if got := customer.Fields[1].Column; got != "display_name" {
+		t.Errorf("Name.Column = %q, want %q", got, "display_name")
+	}
+
+	// With the default options the gorm tags are ignored.
+	defaultInfos, err := gen.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	invoice = findStructInInfos(t, defaultInfos, "Invoice")
+	for _, f := range invoice.Fields {
+		if f.Name == "Total" && f.Column != "ignored" {
+			t.Errorf("Total.Column = %q with default tag, want %q", f.Column, "ignored")
+		}
+	}
+}
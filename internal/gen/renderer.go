@@ -5,20 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"go/format"
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
 	"strings"
 	"text/template"
-	"unicode"
-
-	"github.com/jinzhu/inflection"
 
 	"github.com/mickamy/ormgen/internal/naming"
 )
 
 // RenderOption controls the output of RenderFile.
 type RenderOption struct {
-	DestPkg      string        // output package name (empty = same as source)
-	SourceImport string        // import path for source package (required when DestPkg is set)
-	PeerInfos    []*StructInfo // other structs in the same package (for join scan field lookups)
+	DestPkg      string         // output package name (empty = same as source)
+	SourceImport string         // import path for source package (required when DestPkg is set)
+	PeerInfos    []*StructInfo  // other structs in the same package (for join scan field lookups)
+	Plurals      naming.Plurals // singular→plural overrides for inferred relation target tables
+	Diff         bool           // emit a <Type>Diff helper per struct
+	SortColumns  bool           // emit a typed <Type>SortColumn per struct
+	ColumnScopes bool           // emit a typed <Factory>By<Field> equality scope per column
+	Repo         bool           // emit a <Type>Repository interface and implementation per writable struct
+	Mock         bool           // emit a Mock<Type>Repository per repository; requires Repo
+	HelperPrefix string         // prefix for unexported helpers, e.g. "model" → modelScanUser
 }
 
 // Render generates the Go source code for a single StructInfo.
@@ -34,6 +42,13 @@ func RenderFile(infos []*StructInfo, opt RenderOption) ([]byte, error) {
 		return nil, errors.New("no structs to render")
 	}
 
+	if opt.HelperPrefix != "" && !token.IsIdentifier(opt.HelperPrefix) {
+		return nil, fmt.Errorf("helper prefix %q is not a Go identifier", opt.HelperPrefix)
+	}
+	if opt.Mock && !opt.Repo {
+		return nil, errors.New("mocks are generated for repositories; enable Repo as well")
+	}
+
 	pkg := opt.DestPkg
 	if pkg == "" {
 		pkg = infos[0].Package
@@ -56,20 +71,103 @@ func RenderFile(infos []*StructInfo, opt RenderOption) ([]byte, error) {
 	}
 
 	structs := make([]templateData, 0, len(infos))
+	var enumTypes []*enumTypeData
+	enumTypesByName := make(map[string]*enumTypeData)
 	var allExtraImports []importEntry
-	seenImports := make(map[string]bool)
+	// database/sql is always imported, and time whenever needsTime is set.
+	seenImports := map[string]bool{"database/sql": true}
+	needsTime := false
 
 	for _, info := range infos {
-		pk, err := info.PrimaryKeyField()
+		pk, err := info.optionalPrimaryKeyField()
 		if err != nil {
 			return nil, err
 		}
+		var compositePK []string
+		if pkFields := info.PrimaryKeyFields(); len(pkFields) > 1 {
+			for _, f := range pkFields {
+				compositePK = append(compositePK, f.Column)
+			}
+		}
+		if pk == nil {
+			need := "a primary key"
+			if compositePK != nil {
+				need = "a single-column primary key"
+			}
+			for _, rel := range info.Relations {
+				if rel.RelType != "belongs_to" && rel.RelType != "polymorphic" {
+					return nil, fmt.Errorf("%s.%s: %s relation needs %s on %s", info.Name, rel.FieldName, rel.RelType, need, info.Name)
+				}
+			}
+		}
 
-		createdAtFields := filterFields(info.Fields, func(f FieldInfo) bool { return f.CreatedAt })
-		updatedAtFields := filterFields(info.Fields, func(f FieldInfo) bool { return f.UpdatedAt })
+		var createdAtFields, updatedAtFields, serverFields []FieldInfo
+		if !info.ReadOnly {
+			createdAtFields = filterFields(info.Fields, func(f FieldInfo) bool { return f.CreatedAt && !f.Server && !f.ReadOnly })
+			updatedAtFields = filterFields(info.Fields, func(f FieldInfo) bool { return f.UpdatedAt && !f.Server && !f.ReadOnly })
+			serverFields = filterFields(info.Fields, func(f FieldInfo) bool { return f.Server })
+		}
 		hasTimestamps := len(createdAtFields) > 0 || len(updatedAtFields) > 0
 
-		relations, extraImports := buildRelationData(info, pk, typePrefix, opt.SourceImport, opt.DestPkg, allInfos)
+		var versionField *FieldInfo
+		switch versionFields := filterFields(info.Fields, func(f FieldInfo) bool { return f.Version }); len(versionFields) {
+		case 0:
+		case 1:
+			if !isIntType(versionFields[0].GoType) {
+				return nil, fmt.Errorf("%s.%s: version field must be an integer, got %s", info.Name, versionFields[0].Name, versionFields[0].GoType)
+			}
+			if !info.ReadOnly {
+				versionField = &versionFields[0]
+			}
+		default:
+			return nil, fmt.Errorf("%s: multiple version fields: %s and %s", info.Name, versionFields[0].Name, versionFields[1].Name)
+		}
+
+		for _, f := range filterFields(info.Fields, func(f FieldInfo) bool { return f.ReadOnly || f.Immutable }) {
+			switch {
+			case f.ReadOnly && f.Immutable:
+				return nil, fmt.Errorf("%s.%s: field cannot be both readonly and immutable", info.Name, f.Name)
+			case f.ReadOnly && f.PrimaryKey:
+				return nil, fmt.Errorf("%s.%s: readonly field cannot be a primary key", info.Name, f.Name)
+			case f.Version:
+				return nil, fmt.Errorf("%s.%s: version field cannot be readonly or immutable", info.Name, f.Name)
+			case f.Immutable && f.UpdatedAt && !f.Server:
+				return nil, fmt.Errorf("%s.%s: updatedAt field cannot be immutable", info.Name, f.Name)
+			}
+		}
+
+		for _, f := range filterFields(info.Fields, func(f FieldInfo) bool { return f.Generated }) {
+			switch {
+			case !f.PrimaryKey || pk == nil:
+				return nil, fmt.Errorf("%s.%s: generated field must be the single-column primary key", info.Name, f.Name)
+			case isIntType(f.GoType):
+				return nil, fmt.Errorf("%s.%s: generated primary key must not be an integer, got %s", info.Name, f.Name, f.GoType)
+			}
+		}
+		var generatePKFunc string
+		if pk != nil && !info.ReadOnly && (pk.Generated || isUUIDType(pk.GoType)) {
+			generatePKFunc = helperName(opt.HelperPrefix, "generate"+info.Name+"PK")
+		}
+
+		var softDeleteColumn string
+		switch deletedAtFields := filterFields(info.Fields, func(f FieldInfo) bool { return f.DeletedAt }); len(deletedAtFields) {
+		case 0:
+		case 1:
+			softDeleteColumn = deletedAtFields[0].Column
+		default:
+			return nil, fmt.Errorf("%s: multiple deletedAt fields: %s and %s", info.Name, deletedAtFields[0].Name, deletedAtFields[1].Name)
+		}
+
+		if err := checkThroughRelations(info, allInfos); err != nil {
+			return nil, err
+		}
+		if err := checkPolymorphicRelations(info); err != nil {
+			return nil, err
+		}
+		if err := checkRelationKeyTypes(info, pk, allInfos); err != nil {
+			return nil, err
+		}
+		relations, extraImports := buildRelationData(info, pk, typePrefix, opt.HelperPrefix, opt.SourceImport, opt.DestPkg, opt.Plurals, allInfos)
 		for _, ei := range extraImports {
 			if !seenImports[ei.Path] {
 				seenImports[ei.Path] = true
@@ -82,27 +180,165 @@ func RenderFile(infos []*StructInfo, opt RenderOption) ([]byte, error) {
 			TableName:        info.TableName,
 			FactoryName:      naming.SnakeToCamel(info.TableName),
 			PK:               pk,
+			CompositePK:      compositePK,
 			Fields:           info.Fields,
-			ScanFunc:         unexportedName("scan" + info.Name),
-			ColValFunc:       unexportedName(info.Name + "ColumnValuePairs"),
-			SetPKFunc:        unexportedName("set" + info.Name + "PK"),
-			ColumnsVar:       unexportedName(naming.SnakeToCamel(info.TableName) + "Columns"),
-			IsIntPK:          isIntType(pk.GoType),
+			ScanFunc:         helperName(opt.HelperPrefix, "scan"+info.Name),
+			ScanIntoFunc:     helperName(opt.HelperPrefix, "scan"+info.Name+"Into"),
+			ColValFunc:       helperName(opt.HelperPrefix, info.Name+"ColumnValuePairs"),
+			SetPKFunc:        helperName(opt.HelperPrefix, "set"+info.Name+"PK"),
+			GetPKFunc:        helperName(opt.HelperPrefix, "get"+info.Name+"PK"),
+			ColumnsVar:       helperName(opt.HelperPrefix, naming.SnakeToCamel(info.TableName)+"Columns"),
+			TableConst:       info.Name + "Table",
+			ColumnNamesVar:   info.Name + "Columns",
+			IsIntPK:          pk != nil && !info.ReadOnly && isIntType(pk.GoType),
+			ReadOnly:         info.ReadOnly,
 			Relations:        relations,
-			SetCreatedAtFunc: unexportedName("set" + info.Name + "CreatedAt"),
-			SetUpdatedAtFunc: unexportedName("set" + info.Name + "UpdatedAt"),
+			SetCreatedAtFunc: helperName(opt.HelperPrefix, "set"+info.Name+"CreatedAt"),
+			SetUpdatedAtFunc: helperName(opt.HelperPrefix, "set"+info.Name+"UpdatedAt"),
 			CreatedAtFields:  createdAtFields,
 			UpdatedAtFields:  updatedAtFields,
 			HasTimestamps:    hasTimestamps,
+			ServerFields:     serverFields,
+			SoftDeleteColumn: softDeleteColumn,
+			VersionField:     versionField,
+			BumpVersionFunc:  helperName(opt.HelperPrefix, "bump"+info.Name+"Version"),
+			GeneratePKFunc:   generatePKFunc,
+		}
+		if len(serverFields) > 0 {
+			data.ServerTimestampsFunc = helperName(opt.HelperPrefix, info.Name+"ServerTimestamps")
+		}
+		if opt.SortColumns && len(info.Fields) > 0 {
+			data.SortColumnType = info.Name + "SortColumn"
+			data.ParseSortFunc = "Parse" + info.Name + "SortColumn"
+			for _, f := range info.Fields {
+				data.SortColumns = append(data.SortColumns, sortColumnData{
+					Name:   info.Name + "SortBy" + f.Name,
+					Column: f.Column,
+				})
+			}
+		}
+		for _, f := range info.Fields {
+			if f.Enum {
+				data.EnumScopes = append(data.EnumScopes, columnScopeData{
+					FuncName:  data.FactoryName + "With" + f.Name,
+					Column:    f.Column,
+					ParamType: typePrefix + f.GoType,
+				})
+			}
+			if len(f.EnumValues) == 0 {
+				continue
+			}
+			et, ok := enumTypesByName[f.GoType]
+			if !ok {
+				et = &enumTypeData{
+					TypeName:  typePrefix + f.GoType,
+					ValuesVar: f.GoType + "Values",
+					ParseFunc: "Parse" + f.GoType,
+					Values:    f.EnumValues,
+					Source:    info.Name + "." + f.Name,
+				}
+				enumTypesByName[f.GoType] = et
+				enumTypes = append(enumTypes, et)
+			} else if !slices.Equal(et.Values, f.EnumValues) {
+				return nil, fmt.Errorf("%s.%s: enum values of %s differ from those on %s", info.Name, f.Name, f.GoType, et.Source)
+			}
+			data.EnumChecks = append(data.EnumChecks, enumCheckData{Field: f.Name, Column: f.Column, ValuesVar: et.ValuesVar})
+		}
+		if len(data.EnumChecks) > 0 && !info.ReadOnly {
+			data.ValidateFunc = helperName(opt.HelperPrefix, "validate"+info.Name)
+		}
+		if opt.ColumnScopes {
+			for _, f := range info.Fields {
+				// A pointer field is compared by the value it points to:
+				// "= NULL" never matches, so nil is no useful argument.
+				f.GoType = strings.TrimPrefix(f.GoType, "*")
+				paramType, ei := qualifiedType(&f, typePrefix)
+				if ei != nil && ei.Path == "time" {
+					needsTime = true
+				} else if ei != nil && !seenImports[ei.Path] {
+					seenImports[ei.Path] = true
+					allExtraImports = append(allExtraImports, *ei)
+				}
+				data.ColumnScopes = append(data.ColumnScopes, columnScopeData{
+					FuncName:  data.FactoryName + "By" + f.Name,
+					Column:    f.Column,
+					ParamType: paramType,
+				})
+			}
+		}
+		if opt.Repo && !info.ReadOnly && pk != nil {
+			data.RepoInterface = info.Name + "Repository"
+			data.RepoCtor = "New" + info.Name + "Repository"
+			data.RepoStruct = helperName(opt.HelperPrefix, unexportedName(info.Name)+"Repository")
+			if opt.Mock {
+				data.MockType = "Mock" + info.Name + "Repository"
+			}
+			var ei *importEntry
+			data.RepoIDType, ei = qualifiedType(pk, typePrefix)
+			if ei != nil && !seenImports[ei.Path] {
+				seenImports[ei.Path] = true
+				allExtraImports = append(allExtraImports, *ei)
+			}
+		}
+		if opt.Diff {
+			data.DiffFunc = info.Name + "Diff"
+			for _, f := range info.Fields {
+				data.DiffFields = append(data.DiffFields, diffFieldData{
+					Name:    f.Name,
+					Column:  f.Column,
+					Compare: diffCompare(f.GoType),
+				})
+			}
 		}
 		structs = append(structs, data)
 	}
 
+	if err := checkNameCollisions(structs, enumTypes); err != nil {
+		return nil, err
+	}
+
 	hasRelations := false
+	hasScopes := false
+	hasRepos := false
+	hasMocks := false
 	fileHasTimestamps := false
+	needsReflect := false
+	needsDriver := false
+	var typeChecks []columnTypeCheck
+	seenTypeChecks := make(map[string]bool)
+	for _, info := range infos {
+		for _, f := range info.Fields {
+			typeName := typePrefix + strings.TrimPrefix(f.GoType, "*")
+			if (!f.Scanner && !f.Valuer && !f.CustomType) || seenTypeChecks[typeName] {
+				continue
+			}
+			seenTypeChecks[typeName] = true
+			check := columnTypeCheck{TypeName: typeName, Scanner: f.Scanner || f.CustomType, Valuer: f.Valuer || f.CustomType}
+			typeChecks = append(typeChecks, check)
+			needsDriver = needsDriver || check.Valuer
+		}
+	}
 	for _, s := range structs {
-		if len(s.Relations) > 0 {
-			hasRelations = true
+		for _, f := range s.DiffFields {
+			if f.Compare == "deep" {
+				needsReflect = true
+			}
+		}
+		for _, r := range s.Relations {
+			if !r.NoPreload {
+				hasRelations = true
+				hasScopes = true
+			}
+		}
+		if len(s.EnumScopes) > 0 || len(s.ColumnScopes) > 0 || len(s.SortColumns) > 0 {
+			hasScopes = true
+		}
+		if s.RepoInterface != "" {
+			hasRepos = true
+			hasScopes = true
+		}
+		if s.MockType != "" {
+			hasMocks = true
 		}
 		if s.HasTimestamps {
 			fileHasTimestamps = true
@@ -113,7 +349,14 @@ func RenderFile(infos []*StructInfo, opt RenderOption) ([]byte, error) {
 		Package:       pkg,
 		SourceImport:  opt.SourceImport,
 		HasRelations:  hasRelations,
-		HasTimestamps: fileHasTimestamps,
+		HasScopes:     hasScopes,
+		HasRepos:      hasRepos,
+		HasMocks:      hasMocks,
+		HasTimestamps: fileHasTimestamps || needsTime,
+		NeedsReflect:  needsReflect,
+		NeedsDriver:   needsDriver,
+		TypeChecks:    typeChecks,
+		EnumTypes:     enumTypes,
 		ExtraImports:  allExtraImports,
 		Structs:       structs,
 	}
@@ -139,70 +382,190 @@ type fileTemplateData struct {
 	Package       string
 	SourceImport  string
 	HasRelations  bool
-	HasTimestamps bool
+	HasScopes     bool // relations or enum scopes reference the scope package
+	HasRepos      bool // repositories reference context and scope
+	HasMocks      bool // mocks guard their recorded calls with sync.Mutex
+	HasTimestamps bool // timestamp setters or a time.Time column scope reference the time package
+	NeedsReflect  bool // a Diff helper falls back to reflect.DeepEqual
+	NeedsDriver   bool // a type check asserts driver.Valuer
+	TypeChecks    []columnTypeCheck
+	EnumTypes     []*enumTypeData
 	ExtraImports  []importEntry
 	Structs       []templateData
 }
 
 type templateData struct {
-	TypeName         string
-	TableName        string
-	FactoryName      string
-	PK               *FieldInfo
-	Fields           []FieldInfo
-	ScanFunc         string
-	ColValFunc       string
-	SetPKFunc        string
-	ColumnsVar       string
-	IsIntPK          bool
-	Relations        []relationTemplateData
-	SetCreatedAtFunc string
-	SetUpdatedAtFunc string
-	CreatedAtFields  []FieldInfo
-	UpdatedAtFields  []FieldInfo
-	HasTimestamps    bool
+	TypeName             string
+	TableName            string
+	FactoryName          string
+	PK                   *FieldInfo
+	CompositePK          []string // primary key columns when there is more than one; PK is nil
+	Fields               []FieldInfo
+	ScanFunc             string
+	ScanIntoFunc         string
+	ColValFunc           string
+	SetPKFunc            string
+	GetPKFunc            string
+	ColumnsVar           string
+	TableConst           string // "UserTable"
+	ColumnNamesVar       string // "UserColumns"
+	IsIntPK              bool
+	ReadOnly             bool // no write support; PK may be nil
+	Relations            []relationTemplateData
+	SetCreatedAtFunc     string
+	SetUpdatedAtFunc     string
+	CreatedAtFields      []FieldInfo
+	UpdatedAtFields      []FieldInfo
+	HasTimestamps        bool
+	ServerFields         []FieldInfo // timestamps tagged "server", set by the database
+	ServerTimestampsFunc string      // empty unless ServerFields is non-empty
+	SoftDeleteColumn     string      // deletedAt column; empty without soft delete
+	VersionField         *FieldInfo  // optimistic locking column; nil without one or for read-only models
+	BumpVersionFunc      string
+	GeneratePKFunc       string // empty unless the primary key is a UUID or tagged "generated"
+	ValidateFunc         string // empty unless a field is tagged with enum values
+	EnumChecks           []enumCheckData
+	EnumScopes           []columnScopeData
+	ColumnScopes         []columnScopeData // empty unless RenderOption.ColumnScopes is set
+	SortColumnType       string            // "UserSortColumn"; empty unless RenderOption.SortColumns is set
+	ParseSortFunc        string            // "ParseUserSortColumn"
+	SortColumns          []sortColumnData
+	DiffFunc             string // empty unless RenderOption.Diff is set
+	DiffFields           []diffFieldData
+	RepoInterface        string // "UserRepository"; empty unless RenderOption.Repo is set
+	RepoCtor             string // "NewUserRepository"
+	RepoStruct           string // "userRepository", the unexported implementation
+	RepoIDType           string // primary key parameter type, e.g. "int" or "uuid.UUID"
+	MockType             string // "MockUserRepository"; empty unless RenderOption.Mock is set
+}
+
+// columnTypeCheck is a compile-time assertion that a custom column type
+// implements sql.Scanner and driver.Valuer: both for a type the driver
+// cannot convert natively, or else the interfaces whose methods it declares.
+type columnTypeCheck struct {
+	TypeName string // "StringArray" or "model.StringArray"
+	Scanner  bool   // assert sql.Scanner on *TypeName
+	Valuer   bool   // assert driver.Valuer on TypeName
+}
+
+// enumTypeData is a type whose column is tagged with enum values, e.g.
+// `db:"status,enum:active|banned"`; its values and parser are generated
+// once per file.
+type enumTypeData struct {
+	TypeName  string   // "Status" or "model.Status"
+	ValuesVar string   // "StatusValues"
+	ParseFunc string   // "ParseStatus"
+	Values    []string // "active", "banned"
+	Source    string   // "User.Status", the field that declared the values first
+}
+
+// enumCheckData is an enum field checked by the generated validator.
+type enumCheckData struct {
+	Field     string // "Status"
+	Column    string // "status"
+	ValuesVar string // "StatusValues"
+}
+
+// diffFieldData describes how the Diff helper compares one column.
+type diffFieldData struct {
+	Name    string // Go field name
+	Column  string // column name
+	Compare string // "eq" (==), "time" (time.Time.Equal), or "deep" (reflect.DeepEqual)
+}
+
+// columnScopeData describes a typed equality scope for a column.
+type columnScopeData struct {
+	FuncName  string // "UsersWithStatus" or "UsersByEmail"
+	Column    string // "status"
+	ParamType string // "Status", "model.Status" or "string"
+}
+
+// sortColumnData is a constant of the generated sort column type.
+type sortColumnData struct {
+	Name   string // "UserSortByName"
+	Column string // "name"
 }
 
 type relationTemplateData struct {
-	FieldName        string // "Posts"
-	ParentType       string // "model.User" or "User" (parent struct type)
-	TargetType       string // "model.Post" or "Post"
-	TargetFactory    string // "Posts"
-	ForeignKey       string // "user_id"
-	ForeignKeyField  string // "UserID"
-	RelType          string // "has_many", "belongs_to", "has_one", or "many_to_many"
-	IsPointer        bool   // true if the source field is a pointer (e.g. *UserEmail)
-	PreloaderName    string // "preloadUserPosts"
-	KeyType          string // Go type for map key ("int")
-	ParentPKField    string // "ID"
-	JoinTargetTable  string
-	JoinTargetColumn string
-	JoinSourceTable  string
-	JoinSourceColumn string
-	FKIsPointer      bool   // true if the foreign key field is a pointer type (e.g. *string)
-	JoinTable        string // many_to_many only: "user_tags"
-	References       string // many_to_many only: "tag_id"
-	TargetTable      string // many_to_many only: target table name "tags"
-	TargetPKColumn   string // many_to_many only: target PK column "id"
-
-	// Join scan support (belongs_to / has_one, same-package only).
-	// nil when join scan is not supported (cross-package, has_many, many_to_many).
+	FieldName           string // "Posts"
+	ParentType          string // "model.User" or "User" (parent struct type)
+	TargetType          string // "model.Post" or "Post"
+	TargetFactory       string // "Posts"
+	ForeignKey          string // "user_id"
+	ForeignKeyField     string // "UserID"
+	RelType             string // "has_many", "belongs_to", "has_one", "many_to_many", or "polymorphic"
+	IsPointer           bool   // true if the source field is a pointer (e.g. *UserEmail)
+	PreloaderName       string // "preloadUserPosts"
+	PublicPreloaderName string // "PreloadUserPosts"
+	KeyType             string // Go type for map key ("int")
+	ParentPKField       string // "ID"
+	JoinTargetTable     string
+	JoinTargetColumn    string
+	JoinSourceTable     string
+	JoinSourceColumn    string
+	FKIsPointer         bool   // true if the foreign key field, on the parent or target, is a pointer type (e.g. *string)
+	JoinTable           string // many_to_many only: "user_tags"
+	References          string // many_to_many only: "tag_id"
+	TargetTable         string // many_to_many only: target table name "tags"
+	TargetPKColumn      string // many_to_many only: target PK column "id"
+	NoPreload           bool   // skip the preloader and its registration
+	NoJoin              bool   // skip RegisterJoin and join scan support
+
+	// has_many through support: the targets are loaded by their foreign key
+	// to the rows of another has_many relation. Empty unless Through is set.
+	Through            string // "Posts"
+	ThroughFactory     string // "Posts"
+	ThroughForeignKey  string // "author_id", on the intermediate struct
+	ThroughFKField     string // "AuthorID"
+	ThroughFKIsPointer bool   // true if ThroughFKField is a pointer type
+	ThroughKeyType     string // intermediate PK Go type, e.g. "int"
+	ThroughPKField     string // "ID"
+
+	// polymorphic support: ForeignKey and ForeignKeyField name the ID column,
+	// and only parents whose type column holds TypeValue load the target.
+	TypeField string // "CommentableType"
+	TypeValue string // "Post"
+
+	// Join scan support (belongs_to / has_one / has_many, same-package only).
+	// nil when join scan is not supported (cross-package, many_to_many).
 	JoinScanFields    []FieldInfo // target struct's DB fields
 	JoinSelectColumns []string    // target column names for JoinConfig.SelectColumns
 	JoinPKGoType      string      // target PK Go type, e.g. "int"
 	JoinPKName        string      // target PK Go field name, e.g. "ID"
 	JoinNullType      string      // nullable wrapper, e.g. "sql.NullInt64" (pointer only)
 	JoinNullField     string      // accessor on NullXxx, e.g. ".Int64" (pointer only)
+	MergerName        string      // has_many only: "mergeUserPosts", folds JoinPreload rows
+}
+
+// HasPointerJoinScan reports whether any pointer relation is filled by join scans.
+func (d templateData) HasPointerJoinScan() bool {
+	for _, r := range d.Relations {
+		if r.JoinScanFields != nil && r.IsPointer {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFields returns the fields written by INSERT and UPDATE: all but the
+// readonly ones.
+func (d templateData) WriteFields() []FieldInfo {
+	return filterFields(d.Fields, func(f FieldInfo) bool { return !f.ReadOnly })
 }
 
 func (d templateData) NonPKFields() []FieldInfo {
-	var fields []FieldInfo
+	return filterFields(d.Fields, func(f FieldInfo) bool { return !f.PrimaryKey && !f.ReadOnly })
+}
+
+// ImmutableColumns returns the columns written by INSERT only.
+func (d templateData) ImmutableColumns() []string {
+	var cols []string
 	for _, f := range d.Fields {
-		if !f.PrimaryKey {
-			fields = append(fields, f)
+		if f.Immutable {
+			cols = append(cols, f.Column)
 		}
 	}
-	return fields
+	return cols
 }
 
 func (d templateData) CreatedAtColumns() []string {
@@ -235,16 +598,25 @@ const fileTemplate = `// Code generated by ormgen; DO NOT EDIT.
 package {{.Package}}
 
 import (
-	{{- if .HasRelations}}
+	{{- if or .HasRelations .HasRepos}}
 	"context"
 	{{- end}}
 	"database/sql"
+	{{- if .NeedsDriver}}
+	"database/sql/driver"
+	{{- end}}
+	{{- if .NeedsReflect}}
+	"reflect"
+	{{- end}}
+	{{- if .HasMocks}}
+	"sync"
+	{{- end}}
 	{{- if .HasTimestamps}}
 	"time"
 	{{- end}}
 
 	"github.com/mickamy/ormgen/orm"
-	{{- if .HasRelations}}
+	{{- if .HasScopes}}
 	"github.com/mickamy/ormgen/scope"
 	{{- end}}
 	{{- if .SourceImport}}
@@ -258,16 +630,52 @@ import (
 	{{- end}}
 	{{- end}}
 )
+{{- if .TypeChecks}}
+
+// Custom column types are scanned and written as-is, so they must implement
+// sql.Scanner and driver.Valuer; a missing method or a wrong receiver or
+// signature fails here at build time instead of at the first query. Values
+// are passed to the driver as-is, so Value is checked on the value type.
+var (
+	{{- range .TypeChecks}}
+	{{- if .Scanner}}
+	_ sql.Scanner = (*{{.TypeName}})(nil)
+	{{- end}}
+	{{- if .Valuer}}
+	_ driver.Valuer = *new({{.TypeName}})
+	{{- end}}
+	{{- end}}
+)
+{{- end}}
+{{- range .EnumTypes}}
+
+// {{.ValuesVar}} lists the values a {{.TypeName}} column accepts, from its enum tag.
+var {{.ValuesVar}} = []{{.TypeName}}{ {{- range $i, $v := .Values}}{{if $i}}, {{end}}{{quote $v}}{{end -}} }
+
+// {{.ParseFunc}} returns s as a {{.TypeName}}, or an error wrapping
+// orm.ErrInvalidEnum if it is not one of {{.ValuesVar}}.
+func {{.ParseFunc}}(s string) ({{.TypeName}}, error) {
+	return orm.ParseEnum(s, {{.ValuesVar}}...)
+}
+{{- end}}
 {{range .Structs}}
 // {{.FactoryName}} returns a new Query for the {{.TableName}} table.
 func {{.FactoryName}}(db orm.Querier) *orm.Query[{{.TypeName}}] {
-	{{- if or .Relations .HasTimestamps}}
 	q := orm.NewQuery[{{.TypeName}}](
-		db, orm.ResolveTableName[{{.TypeName}}]("{{.TableName}}"), {{.ColumnsVar}}, "{{.PK.Column}}",
-		{{.ScanFunc}}, {{.ColValFunc}}, {{if .IsIntPK}}{{.SetPKFunc}}{{else}}nil{{end}},
+		db, orm.ResolveTableName[{{.TypeName}}]("{{.TableName}}"), {{.ColumnsVar}}, "{{if .PK}}{{.PK.Column}}{{end}}",
+		{{.ScanFunc}}, {{if .ReadOnly}}nil{{else}}{{.ColValFunc}}{{end}}, {{if .IsIntPK}}{{.SetPKFunc}}{{else}}nil{{end}},
 	)
+	{{- if .PK}}
+	q.RegisterPK({{.GetPKFunc}})
+	{{- end}}
+	{{- if .CompositePK}}
+	q.RegisterCompositePK({{range $i, $c := .CompositePK}}{{if $i}}, {{end}}{{quote $c}}{{end}})
+	{{- end}}
+	{{- if .ReadOnly}}
+	q.RegisterReadOnly()
+	{{- end}}
 	{{- range .Relations}}
-	{{- if ne .RelType "many_to_many"}}
+	{{- if not .NoJoin}}
 	q.RegisterJoin("{{.FieldName}}", orm.JoinConfig{
 		TargetTable: orm.ResolveTableName[{{.TargetType}}]("{{.JoinTargetTable}}"), TargetColumn: "{{.JoinTargetColumn}}",
 		SourceTable: orm.ResolveTableName[{{.ParentType}}]("{{.JoinSourceTable}}"), SourceColumn: "{{.JoinSourceColumn}}",
@@ -275,9 +683,14 @@ func {{.FactoryName}}(db orm.Querier) *orm.Query[{{.TypeName}}] {
 		SelectColumns: []string{ {{- range $i, $c := .JoinSelectColumns}}{{if $i}}, {{end}}{{quote $c}}{{end -}} },
 		{{- end}}
 	})
+	{{- if .MergerName}}
+	q.RegisterMerge("{{.FieldName}}", {{.MergerName}})
+	{{- end}}
 	{{- end}}
+	{{- if not .NoPreload}}
 	q.RegisterPreloader("{{.FieldName}}", {{.PreloaderName}})
 	{{- end}}
+	{{- end}}
 	{{- if .HasTimestamps}}
 	q.RegisterTimestamps(
 		{{if .CreatedAtFields}}[]string{ {{- range $i, $c := .CreatedAtColumns}}{{if $i}}, {{end}}{{quote $c}}{{end -}} }{{else}}nil{{end}},
@@ -286,24 +699,71 @@ func {{.FactoryName}}(db orm.Querier) *orm.Query[{{.TypeName}}] {
 		{{if .UpdatedAtFields}}{{.SetUpdatedAtFunc}}{{else}}nil{{end}},
 	)
 	{{- end}}
-	return q
-	{{- else}}
-	return orm.NewQuery[{{.TypeName}}](
-		db, orm.ResolveTableName[{{.TypeName}}]("{{.TableName}}"), {{.ColumnsVar}}, "{{.PK.Column}}",
-		{{.ScanFunc}}, {{.ColValFunc}}, {{if .IsIntPK}}{{.SetPKFunc}}{{else}}nil{{end}},
-	)
+	{{- if .ServerFields}}
+	q.RegisterServerTimestamps([]string{ {{- range $i, $f := .ServerFields}}{{if $i}}, {{end}}{{quote $f.Column}}{{end -}} }, {{.ServerTimestampsFunc}})
+	{{- end}}
+	{{- if and (not .ReadOnly) .ImmutableColumns}}
+	q.RegisterImmutable([]string{ {{- range $i, $c := .ImmutableColumns}}{{if $i}}, {{end}}{{quote $c}}{{end -}} })
+	{{- end}}
+	{{- if .SoftDeleteColumn}}
+	q.RegisterSoftDelete("{{.SoftDeleteColumn}}")
+	{{- end}}
+	{{- if .VersionField}}
+	q.RegisterVersion("{{.VersionField.Column}}", {{.BumpVersionFunc}})
 	{{- end}}
+	{{- if .GeneratePKFunc}}
+	q.RegisterPKGenerator({{.GeneratePKFunc}})
+	{{- end}}
+	{{- if .ValidateFunc}}
+	q.RegisterValidator({{.ValidateFunc}})
+	{{- end}}
+	{{- if not .ReadOnly}}
+	q.RegisterScanInto({{.ScanIntoFunc}})
+	{{- end}}
+	return q
 }
 
+// {{.TableConst}} is the inferred name of the {{.TableName}} table. A TableName
+// method on {{.TypeName}} still takes precedence at runtime.
+const {{.TableConst}} = "{{.TableName}}"
+
 var {{.ColumnsVar}} = []string{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}{{quote $f.Column}}{{end -}} }
+{{- if .Fields}}
+
+// {{.ColumnNamesVar}} holds the column name of each {{.TypeName}} field, for
+// building clauses without spelling columns out, e.g.
+// {{.ColumnNamesVar}}.{{(index .Fields 0).Name}}+" = ?".
+var {{.ColumnNamesVar}} = struct {
+	{{- range .Fields}}
+	{{.Name}} string
+	{{- end}}
+}{
+	{{- range .Fields}}
+	{{.Name}}: {{quote .Column}},
+	{{- end}}
+}
+{{- end}}
 
 func {{.ScanFunc}}(rows *sql.Rows) ({{.TypeName}}, error) {
-	cols, _ := rows.Columns()
 	var v {{.TypeName}}
+	err := {{.ScanIntoFunc}}(rows, &v)
+	return v, err
+}
+
+// {{.ScanIntoFunc}} scans the current row into v, so that callers can reuse
+// one destination across rows. Fields whose columns are not in the row are
+// left untouched{{if .HasPointerJoinScan}}, but join-scanned pointer relations are always
+// reset so that a reused v never keeps the previous row's relation{{end}}.
+func {{.ScanIntoFunc}}(rows *sql.Rows, v *{{.TypeName}}) error {
+	cols, _ := rows.Columns()
 	{{- range .Relations}}
 	{{- if and .JoinScanFields .IsPointer}}
 	var joinScan{{.FieldName}}PK {{.JoinNullType}}
 	var joinScan{{.FieldName}} {{.TargetType}}
+	{{- else if .MergerName}}
+	var joinScan{{.FieldName}}PK {{.JoinNullType}}
+	var joinScan{{.FieldName}} {{.TargetType}}
+	joinScan{{.FieldName}}Seen := false
 	{{- end}}
 	{{- end}}
 	dest := make([]any, len(cols))
@@ -318,12 +778,16 @@ func {{.ScanFunc}}(rows *sql.Rows) ({{.TypeName}}, error) {
 		{{- if and $rel.IsPointer $f.PrimaryKey}}
 		case "{{$rel.FieldName}}__{{$f.Column}}":
 			dest[i] = &joinScan{{$rel.FieldName}}PK
-		{{- else if $rel.IsPointer}}
+		{{- else if and $rel.MergerName $f.PrimaryKey}}
 		case "{{$rel.FieldName}}__{{$f.Column}}":
-			dest[i] = &joinScan{{$rel.FieldName}}.{{$f.Name}}
+			dest[i] = &joinScan{{$rel.FieldName}}PK
+			joinScan{{$rel.FieldName}}Seen = true
+		{{- else if or $rel.IsPointer $rel.MergerName}}
+		case "{{$rel.FieldName}}__{{$f.Column}}":
+			dest[i] = orm.SkipNull(&joinScan{{$rel.FieldName}}.{{$f.Name}})
 		{{- else}}
 		case "{{$rel.FieldName}}__{{$f.Column}}":
-			dest[i] = &v.{{$rel.FieldName}}.{{$f.Name}}
+			dest[i] = orm.SkipNull(&v.{{$rel.FieldName}}.{{$f.Name}})
 		{{- end}}
 		{{- end}}
 		{{- end}}
@@ -331,31 +795,63 @@ func {{.ScanFunc}}(rows *sql.Rows) ({{.TypeName}}, error) {
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
 	{{- range .Relations}}
 	{{- if and .JoinScanFields .IsPointer}}
 	if joinScan{{.FieldName}}PK.Valid {
 		joinScan{{.FieldName}}.{{.JoinPKName}} = {{.JoinPKGoType}}(joinScan{{.FieldName}}PK{{.JoinNullField}})
 		v.{{.FieldName}} = &joinScan{{.FieldName}}
+	} else {
+		v.{{.FieldName}} = nil
+	}
+	{{- else if .MergerName}}
+	if joinScan{{.FieldName}}Seen {
+		if joinScan{{.FieldName}}PK.Valid {
+			joinScan{{.FieldName}}.{{.JoinPKName}} = {{.JoinPKGoType}}(joinScan{{.FieldName}}PK{{.JoinNullField}})
+			v.{{.FieldName}} = []{{.TargetType}}{joinScan{{.FieldName}}}
+		} else {
+			v.{{.FieldName}} = []{{.TargetType}}{}
+		}
 	}
 	{{- end}}
 	{{- end}}
-	return v, err
+	return nil
 }
 
+{{- if not .ReadOnly}}
+
 func {{.ColValFunc}}(v *{{.TypeName}}, includesPK bool) ([]string, []any) {
 	if includesPK {
-		return []string{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}{{quote $f.Column}}{{end -}} },
-			[]any{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}v.{{$f.Name}}{{end -}} }
+		return []string{ {{- range $i, $f := .WriteFields}}{{if $i}}, {{end}}{{quote $f.Column}}{{end -}} },
+			[]any{ {{- range $i, $f := .WriteFields}}{{if $i}}, {{end}}v.{{$f.Name}}{{end -}} }
 	}
 	return []string{ {{- range $i, $f := .NonPKFields}}{{if $i}}, {{end}}{{quote $f.Column}}{{end -}} },
 		[]any{ {{- range $i, $f := .NonPKFields}}{{if $i}}, {{end}}v.{{$f.Name}}{{end -}} }
 }
+{{- end}}
+{{- if .PK}}
+
+func {{.GetPKFunc}}(v *{{.TypeName}}) any {
+	return v.{{.PK.Name}}
+}
+{{- end}}
 {{if .IsIntPK}}
 func {{.SetPKFunc}}(v *{{.TypeName}}, id int64) {
 	v.{{.PK.Name}} = {{.PK.GoType}}(id)
 }
 {{end}}
+{{- if .VersionField}}
+func {{.BumpVersionFunc}}(v *{{.TypeName}}) {
+	v.{{.VersionField.Name}}++
+}
+{{end}}
+{{- if .GeneratePKFunc}}
+func {{.GeneratePKFunc}}(v *{{.TypeName}}) {
+	orm.GenerateKey(&v.{{.PK.Name}})
+}
+{{end}}
 {{- if .CreatedAtFields}}
 func {{.SetCreatedAtFunc}}(v *{{.TypeName}}, now time.Time) {
 	{{- range .CreatedAtFields}}
@@ -382,8 +878,229 @@ func {{.SetUpdatedAtFunc}}(v *{{.TypeName}}, now time.Time) {
 	{{- end}}
 }
 {{- end}}
+{{- if .ServerFields}}
+func {{.ServerTimestampsFunc}}(v *{{.TypeName}}) []any {
+	return []any{ {{- range $i, $f := .ServerFields}}{{if $i}}, {{end}}&v.{{$f.Name}}{{end -}} }
+}
+{{- end}}
+{{- range .EnumScopes}}
+
+// {{.FuncName}} returns a Scope matching rows whose {{.Column}} equals v.
+func {{.FuncName}}(v {{.ParamType}}) scope.Scope {
+	return scope.Where("{{.Column}} = ?", v)
+}
+{{- end}}
+{{- range .ColumnScopes}}
+
+// {{.FuncName}} returns a Scope matching rows whose {{.Column}} equals v.
+func {{.FuncName}}(v {{.ParamType}}) scope.Scope {
+	return scope.Where("{{.Column}} = ?", v)
+}
+{{- end}}
+{{- if .ValidateFunc}}
+
+// {{.ValidateFunc}} rejects enum fields holding a value their tag does not
+// list, before {{.TypeName}} is written.
+func {{.ValidateFunc}}(v *{{.TypeName}}) error {
+	{{- range .EnumChecks}}
+	if err := orm.CheckEnum("{{.Column}}", v.{{.Field}}, {{.ValuesVar}}...); err != nil {
+		return err
+	}
+	{{- end}}
+	return nil
+}
+{{- end}}
+{{- if .SortColumns}}
+{{- $sortType := .SortColumnType}}
+
+// {{.SortColumnType}} is a column of the {{.TableName}} table that results can
+// be ordered by. Parse untrusted input with {{.ParseSortFunc}}.
+type {{.SortColumnType}} string
+
+// Sort columns of the {{.TableName}} table.
+const (
+	{{- range .SortColumns}}
+	{{.Name}} {{$sortType}} = {{quote .Column}}
+	{{- end}}
+)
+
+// {{.ParseSortFunc}} returns the {{.SortColumnType}} named s, or an error
+// wrapping orm.ErrUnknownSortColumn.
+func {{.ParseSortFunc}}(s string) ({{.SortColumnType}}, error) {
+	return orm.ParseSortColumn(s, {{range $i, $c := .SortColumns}}{{if $i}}, {{end}}{{$c.Name}}{{end}})
+}
+
+// Asc returns a Scope ordering by c ascending.
+func (c {{.SortColumnType}}) Asc() scope.Scope { return scope.Asc(string(c)) }
+
+// Desc returns a Scope ordering by c descending.
+func (c {{.SortColumnType}}) Desc() scope.Scope { return scope.Desc(string(c)) }
+{{- end}}
+{{- if .DiffFunc}}
+
+// {{.DiffFunc}} returns the columns whose values differ between before and
+// after, with the corresponding old and new values.
+func {{.DiffFunc}}(before, after *{{.TypeName}}) (cols []string, oldVals, newVals []any) {
+	{{- range .DiffFields}}
+	if {{if eq .Compare "eq"}}before.{{.Name}} != after.{{.Name}}{{else if eq .Compare "time"}}!before.{{.Name}}.Equal(after.{{.Name}}){{else}}!reflect.DeepEqual(before.{{.Name}}, after.{{.Name}}){{end}} {
+		cols = append(cols, {{quote .Column}})
+		oldVals = append(oldVals, before.{{.Name}})
+		newVals = append(newVals, after.{{.Name}})
+	}
+	{{- end}}
+	return cols, oldVals, newVals
+}
+{{- end}}
+{{- if .RepoInterface}}
+
+// {{.RepoInterface}} provides CRUD access to the {{.TableName}} table.
+type {{.RepoInterface}} interface {
+	Create(ctx context.Context, v *{{.TypeName}}) error
+	FindByID(ctx context.Context, id {{.RepoIDType}}) ({{.TypeName}}, error)
+	FindAll(ctx context.Context, scopes ...scope.Scope) ([]{{.TypeName}}, error)
+	Update(ctx context.Context, v *{{.TypeName}}) error
+	Delete(ctx context.Context, id {{.RepoIDType}}) error
+}
+
+// {{.RepoCtor}} returns a {{.RepoInterface}} that runs its queries on db,
+// which may be a *orm.DB or an *orm.Tx.
+func {{.RepoCtor}}(db orm.Querier) {{.RepoInterface}} {
+	return &{{.RepoStruct}}{db: db}
+}
+
+type {{.RepoStruct}} struct {
+	db orm.Querier
+}
+
+func (r *{{.RepoStruct}}) Create(ctx context.Context, v *{{.TypeName}}) error {
+	return {{.FactoryName}}(r.db).Create(ctx, v)
+}
+
+func (r *{{.RepoStruct}}) FindByID(ctx context.Context, id {{.RepoIDType}}) ({{.TypeName}}, error) {
+	return orm.FindByID(ctx, {{.FactoryName}}(r.db), id)
+}
+
+func (r *{{.RepoStruct}}) FindAll(ctx context.Context, scopes ...scope.Scope) ([]{{.TypeName}}, error) {
+	return {{.FactoryName}}(r.db).Scopes(scopes...).All(ctx)
+}
+
+func (r *{{.RepoStruct}}) Update(ctx context.Context, v *{{.TypeName}}) error {
+	return {{.FactoryName}}(r.db).Update(ctx, v)
+}
+
+func (r *{{.RepoStruct}}) Delete(ctx context.Context, id {{.RepoIDType}}) error {
+	return orm.DeleteByID(ctx, {{.FactoryName}}(r.db), id)
+}
+{{- end}}
+{{- if .MockType}}
+
+// {{.MockType}} is a {{.RepoInterface}} for tests that need no database.
+// Each method records its arguments in the matching Calls field and returns
+// the result of the matching Func field, or zero values when it is nil.
+type {{.MockType}} struct {
+	CreateFunc   func(ctx context.Context, v *{{.TypeName}}) error
+	FindByIDFunc func(ctx context.Context, id {{.RepoIDType}}) ({{.TypeName}}, error)
+	FindAllFunc  func(ctx context.Context, scopes ...scope.Scope) ([]{{.TypeName}}, error)
+	UpdateFunc   func(ctx context.Context, v *{{.TypeName}}) error
+	DeleteFunc   func(ctx context.Context, id {{.RepoIDType}}) error
+
+	mu            sync.Mutex
+	CreateCalls   []*{{.TypeName}}
+	FindByIDCalls []{{.RepoIDType}}
+	FindAllCalls  [][]scope.Scope
+	UpdateCalls   []*{{.TypeName}}
+	DeleteCalls   []{{.RepoIDType}}
+}
+
+var _ {{.RepoInterface}} = (*{{.MockType}})(nil)
+
+func (m *{{.MockType}}) Create(ctx context.Context, v *{{.TypeName}}) error {
+	m.mu.Lock()
+	m.CreateCalls = append(m.CreateCalls, v)
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		return nil
+	}
+	return m.CreateFunc(ctx, v)
+}
+
+func (m *{{.MockType}}) FindByID(ctx context.Context, id {{.RepoIDType}}) ({{.TypeName}}, error) {
+	m.mu.Lock()
+	m.FindByIDCalls = append(m.FindByIDCalls, id)
+	m.mu.Unlock()
+	if m.FindByIDFunc == nil {
+		var zero {{.TypeName}}
+		return zero, nil
+	}
+	return m.FindByIDFunc(ctx, id)
+}
+
+func (m *{{.MockType}}) FindAll(ctx context.Context, scopes ...scope.Scope) ([]{{.TypeName}}, error) {
+	m.mu.Lock()
+	m.FindAllCalls = append(m.FindAllCalls, scopes)
+	m.mu.Unlock()
+	if m.FindAllFunc == nil {
+		return nil, nil
+	}
+	return m.FindAllFunc(ctx, scopes...)
+}
+
+func (m *{{.MockType}}) Update(ctx context.Context, v *{{.TypeName}}) error {
+	m.mu.Lock()
+	m.UpdateCalls = append(m.UpdateCalls, v)
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		return nil
+	}
+	return m.UpdateFunc(ctx, v)
+}
+
+func (m *{{.MockType}}) Delete(ctx context.Context, id {{.RepoIDType}}) error {
+	m.mu.Lock()
+	m.DeleteCalls = append(m.DeleteCalls, id)
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		return nil
+	}
+	return m.DeleteFunc(ctx, id)
+}
+{{- end}}
 {{- range .Relations}}
-{{- if eq .RelType "has_many"}}
+{{- if .NoPreload}}
+{{- else if .Through}}
+func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.ParentType}}) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]{{.KeyType}}, len(results))
+	for i := range results {
+		ids[i] = results[i].{{.ParentPKField}}
+	}
+	through, err := {{.ThroughFactory}}(db).Scopes(scope.In("{{.ThroughForeignKey}}", ids)).All(ctx)
+	if err != nil {
+		return err
+	}
+	throughIDs := make([]{{.ThroughKeyType}}, len(through))
+	parentOf := make(map[{{.ThroughKeyType}}]{{.KeyType}}, len(through))
+	for i := range through {
+		throughIDs[i] = through[i].{{.ThroughPKField}}
+		parentOf[through[i].{{.ThroughPKField}}] = {{if .ThroughFKIsPointer}}*{{end}}through[i].{{.ThroughFKField}}
+	}
+	related, err := {{.TargetFactory}}(db).Scopes(scope.In("{{.ForeignKey}}", throughIDs)).All(ctx)
+	if err != nil {
+		return err
+	}
+	byParent := make(map[{{.KeyType}}][]{{.TargetType}})
+	for _, r := range related {
+		parent := parentOf[{{if .FKIsPointer}}*{{end}}r.{{.ForeignKeyField}}]
+		byParent[parent] = append(byParent[parent], r)
+	}
+	for i := range results {
+		results[i].{{.FieldName}} = byParent[results[i].{{.ParentPKField}}]
+	}
+	return nil
+}
+{{- else if eq .RelType "has_many"}}
 func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.ParentType}}) error {
 	if len(results) == 0 {
 		return nil
@@ -398,7 +1115,7 @@ func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.Parent
 	}
 	byFK := make(map[{{.KeyType}}][]{{.TargetType}})
 	for _, r := range related {
-		byFK[r.{{.ForeignKeyField}}] = append(byFK[r.{{.ForeignKeyField}}], r)
+		byFK[{{if .FKIsPointer}}*{{end}}r.{{.ForeignKeyField}}] = append(byFK[{{if .FKIsPointer}}*{{end}}r.{{.ForeignKeyField}}], r)
 	}
 	for i := range results {
 		results[i].{{.FieldName}} = byFK[results[i].{{.ParentPKField}}]
@@ -421,12 +1138,12 @@ func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.Parent
 	{{- if .IsPointer}}
 	byFK := make(map[{{.KeyType}}]*{{.TargetType}})
 	for i := range related {
-		byFK[related[i].{{.ForeignKeyField}}] = &related[i]
+		byFK[{{if .FKIsPointer}}*{{end}}related[i].{{.ForeignKeyField}}] = &related[i]
 	}
 	{{- else}}
 	byFK := make(map[{{.KeyType}}]{{.TargetType}})
 	for _, r := range related {
-		byFK[r.{{.ForeignKeyField}}] = r
+		byFK[{{if .FKIsPointer}}*{{end}}r.{{.ForeignKeyField}}] = r
 	}
 	{{- end}}
 	for i := range results {
@@ -471,6 +1188,44 @@ func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.Parent
 	}
 	return nil
 }
+{{- else if eq .RelType "polymorphic"}}
+func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.ParentType}}) error {
+	ids := make([]{{.KeyType}}, 0, len(results))
+	for i := range results {
+		{{- if .FKIsPointer}}
+		if results[i].{{.TypeField}} == {{printf "%q" .TypeValue}} && results[i].{{.ForeignKeyField}} != nil {
+			ids = append(ids, *results[i].{{.ForeignKeyField}})
+		}
+		{{- else}}
+		if results[i].{{.TypeField}} == {{printf "%q" .TypeValue}} {
+			ids = append(ids, results[i].{{.ForeignKeyField}})
+		}
+		{{- end}}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	related, err := {{.TargetFactory}}(db).Scopes(scope.In("id", ids)).All(ctx)
+	if err != nil {
+		return err
+	}
+	byPK := make(map[{{.KeyType}}]*{{.TargetType}}, len(related))
+	for i := range related {
+		byPK[related[i].ID] = &related[i]
+	}
+	for i := range results {
+		{{- if .FKIsPointer}}
+		if results[i].{{.TypeField}} == {{printf "%q" .TypeValue}} && results[i].{{.ForeignKeyField}} != nil {
+			results[i].{{.FieldName}} = byPK[*results[i].{{.ForeignKeyField}}]
+		}
+		{{- else}}
+		if results[i].{{.TypeField}} == {{printf "%q" .TypeValue}} {
+			results[i].{{.FieldName}} = byPK[results[i].{{.ForeignKeyField}}]
+		}
+		{{- end}}
+	}
+	return nil
+}
 {{- else}}
 func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.ParentType}}) error {
 	if len(results) == 0 {
@@ -516,10 +1271,25 @@ func {{.PreloaderName}}(ctx context.Context, db orm.Querier, results []{{.Parent
 	return nil
 }
 {{- end}}
+{{- if not .NoPreload}}
+
+// {{.PublicPreloaderName}} loads the {{.FieldName}} relation into every element of
+// results, which may come from anywhere (a cache, another query, ...).
+func {{.PublicPreloaderName}}(ctx context.Context, db orm.Querier, results []{{.ParentType}}) error {
+	return {{.PreloaderName}}(ctx, db, results)
+}
+{{- end}}
+{{- if .MergerName}}
+
+// {{.MergerName}} appends the {{.FieldName}} that JoinPreload scanned into src to dst.
+func {{.MergerName}}(dst, src *{{.ParentType}}) {
+	dst.{{.FieldName}} = append(dst.{{.FieldName}}, src.{{.FieldName}}...)
+}
+{{- end}}
 {{- end}}
 {{end}}`
 
-func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport, destPkg string, allInfos []*StructInfo) ([]relationTemplateData, []importEntry) {
+func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, helperPrefix, sourceImport, destPkg string, plurals naming.Plurals, allInfos []*StructInfo) ([]relationTemplateData, []importEntry) {
 	if len(info.Relations) == 0 {
 		return nil, nil
 	}
@@ -529,7 +1299,7 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 	var extraImports []importEntry
 
 	for _, rel := range info.Relations {
-		targetTable := inflection.Plural(naming.CamelToSnake(rel.TargetType))
+		targetTable := plurals.TableName(rel.TargetType)
 		targetFactory := naming.SnakeToCamel(targetTable)
 
 		// Resolve the Go field name for the FK column by looking it up in the
@@ -541,10 +1311,13 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 		// Determine type prefix for the target type.
 		targetTypePrefix := typePrefix
 		isCrossPkg := rel.TargetImportPath != "" && rel.TargetImportPath != sourceImport
+		// A relation with neither a join nor a preloader never mentions its
+		// target type in generated code, so its package must not be imported.
+		usesTarget := !rel.NoPreload || (!rel.NoJoin && rel.RelType != "many_to_many" && rel.RelType != "polymorphic")
 		if isCrossPkg {
 			alias := resolveAlias(rel.TargetImportPath, sourceImport)
 			targetTypePrefix = alias + "."
-			if !seen[rel.TargetImportPath] {
+			if usesTarget && !seen[rel.TargetImportPath] {
 				seen[rel.TargetImportPath] = true
 				parts := strings.Split(rel.TargetImportPath, "/")
 				lastSeg := parts[len(parts)-1]
@@ -558,7 +1331,7 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 
 		// For cross-package relations with a separate dest package, the target
 		// factory lives in the external query package, not the current one.
-		if isCrossPkg && destPkg != "" && sourceImport != "" {
+		if isCrossPkg && !rel.NoPreload && destPkg != "" && sourceImport != "" {
 			extQueryImport := replaceLastSegment(rel.TargetImportPath, destPkg)
 			destQueryImport := replaceLastSegment(sourceImport, destPkg)
 			if extQueryImport != destQueryImport {
@@ -575,22 +1348,35 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 			}
 		}
 
+		var parentPKField string
+		if pk != nil { // nil only for read-only models, which have belongs_to and polymorphic relations only
+			parentPKField = pk.Name
+		}
+
 		rd := relationTemplateData{
-			FieldName:       rel.FieldName,
-			ParentType:      typePrefix + info.Name,
-			TargetType:      targetTypePrefix + rel.TargetType,
-			TargetFactory:   targetFactory,
-			ForeignKey:      rel.ForeignKey,
-			ForeignKeyField: fkField,
-			RelType:         rel.RelType,
-			IsPointer:       rel.IsPointer,
-			PreloaderName:   unexportedName("preload" + info.Name + rel.FieldName),
-			ParentPKField:   pk.Name,
+			FieldName:           rel.FieldName,
+			ParentType:          typePrefix + info.Name,
+			TargetType:          targetTypePrefix + rel.TargetType,
+			TargetFactory:       targetFactory,
+			ForeignKey:          rel.ForeignKey,
+			ForeignKeyField:     fkField,
+			RelType:             rel.RelType,
+			IsPointer:           rel.IsPointer,
+			PreloaderName:       helperName(helperPrefix, "preload"+info.Name+rel.FieldName),
+			PublicPreloaderName: "Preload" + info.Name + rel.FieldName,
+			ParentPKField:       parentPKField,
+			NoPreload:           rel.NoPreload,
+			NoJoin:              rel.NoJoin || rel.RelType == "many_to_many" || rel.RelType == "polymorphic" || rel.Through != "",
 		}
 
 		switch rel.RelType {
 		case "has_many", "has_one":
 			rd.KeyType = pk.GoType
+			if target := findStructInfo(allInfos, rel.TargetType); target != nil && !isCrossPkg {
+				// A nullable FK, e.g. a tree's parent_id, is never NULL in
+				// rows matched by IN, so the preloader dereferences it.
+				rd.FKIsPointer = strings.HasPrefix(lookupFieldType(target, rel.ForeignKey), "*")
+			}
 			rd.JoinTargetTable = targetTable
 			rd.JoinTargetColumn = rel.ForeignKey
 			rd.JoinSourceTable = info.TableName
@@ -601,6 +1387,17 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 			rd.References = rel.References
 			rd.TargetTable = targetTable
 			rd.TargetPKColumn = "id" // convention
+		case "polymorphic":
+			// checkPolymorphicRelations has verified both columns.
+			idType := lookupFieldType(info, rel.IDColumn)
+			if strings.HasPrefix(idType, "*") {
+				rd.FKIsPointer = true
+				idType = idType[1:]
+			}
+			rd.KeyType = idType
+			rd.ForeignKey = rel.IDColumn
+			rd.TypeField = lookupFieldName(info, rel.TypeColumn)
+			rd.TypeValue = rel.TypeValue
 		default: // belongs_to
 			fkType := lookupFieldType(info, rel.ForeignKey)
 			if strings.HasPrefix(fkType, "*") {
@@ -614,9 +1411,31 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 			rd.JoinSourceColumn = rel.ForeignKey
 		}
 
-		// Populate join scan fields for belongs_to / has_one when the target
-		// struct is in the same package (available in allInfos).
-		if (rel.RelType == "belongs_to" || rel.RelType == "has_one") && !isCrossPkg {
+		if rel.Through != "" {
+			// checkThroughRelations has verified the intermediate relation.
+			for _, thr := range info.Relations {
+				if thr.FieldName != rel.Through {
+					continue
+				}
+				throughInfo := findStructInfo(allInfos, thr.TargetType)
+				throughPK, _ := throughInfo.PrimaryKeyField()
+				rd.Through = rel.Through
+				rd.ThroughFactory = naming.SnakeToCamel(plurals.TableName(thr.TargetType))
+				rd.ThroughForeignKey = thr.ForeignKey
+				rd.ThroughFKField = lookupFieldName(throughInfo, thr.ForeignKey)
+				rd.ThroughFKIsPointer = strings.HasPrefix(lookupFieldType(throughInfo, thr.ForeignKey), "*")
+				rd.ThroughKeyType = throughPK.GoType
+				rd.ThroughPKField = throughPK.Name
+			}
+		}
+
+		// Populate join scan fields for belongs_to / has_one / has_many when
+		// the target struct is in the same package (available in allInfos).
+		// has_many needs a target key to tell a child from a NULL match, and
+		// a value slice to append to.
+		joinScannable := rel.RelType == "belongs_to" || rel.RelType == "has_one" ||
+			rel.RelType == "has_many" && !rel.IsPointer
+		if joinScannable && !isCrossPkg && !rd.NoJoin {
 			if targetInfo := findStructInfo(allInfos, rel.TargetType); targetInfo != nil {
 				rd.JoinScanFields = targetInfo.Fields
 				rd.JoinSelectColumns = make([]string, len(targetInfo.Fields))
@@ -629,6 +1448,10 @@ func buildRelationData(info *StructInfo, pk *FieldInfo, typePrefix, sourceImport
 					if rel.IsPointer {
 						rd.JoinNullType, rd.JoinNullField = nullTypeFor(targetPK.GoType)
 					}
+					if rel.RelType == "has_many" && (targetPK.GoType == "string" || isIntType(targetPK.GoType)) {
+						rd.JoinNullType, rd.JoinNullField = nullTypeFor(targetPK.GoType)
+						rd.MergerName = helperName(helperPrefix, "merge"+info.Name+rel.FieldName)
+					}
 				}
 			}
 		}
@@ -683,6 +1506,9 @@ func resolveFieldName(rel RelationInfo, parentInfo *StructInfo, allInfos []*Stru
 		if name := lookupFieldName(parentInfo, rel.ForeignKey); name != "" {
 			return name
 		}
+	case "polymorphic":
+		// The ID column stands in for the FK column on the parent struct.
+		return lookupFieldName(parentInfo, rel.IDColumn)
 	case "has_many", "has_one":
 		// FK column is on the target struct.
 		if targetInfo := findStructInfo(allInfos, rel.TargetType); targetInfo != nil {
@@ -712,13 +1538,245 @@ func lookupFieldType(info *StructInfo, column string) string {
 	return "int" // fallback
 }
 
+// checkThroughRelations reports an error unless every has_many through
+// relation of info names a has_many relation of info whose target is in
+// allInfos with a single-column primary key, which the two-step preloader
+// needs to collect the intermediate keys. The foreign key of a target in
+// the source package must have that key's type, as the preloader looks the
+// intermediate rows up by it; checkRelationKeyTypes skips through
+// relations.
+func checkThroughRelations(info *StructInfo, allInfos []*StructInfo) error {
+	for _, rel := range info.Relations {
+		if rel.Through == "" {
+			continue
+		}
+		if rel.RelType != "has_many" || !rel.IsSlice {
+			return fmt.Errorf("%s.%s: through is only supported on has_many relations", info.Name, rel.FieldName)
+		}
+		var thr *RelationInfo
+		for i := range info.Relations {
+			if info.Relations[i].FieldName == rel.Through {
+				thr = &info.Relations[i]
+			}
+		}
+		switch {
+		case thr == nil:
+			return fmt.Errorf("%s.%s: through relation %s not found on %s", info.Name, rel.FieldName, rel.Through, info.Name)
+		case thr.RelType != "has_many" || thr.Through != "":
+			return fmt.Errorf("%s.%s: through relation %s must be a direct has_many relation", info.Name, rel.FieldName, rel.Through)
+		case thr.TargetImportPath != "" || findStructInfo(allInfos, thr.TargetType) == nil:
+			return fmt.Errorf("%s.%s: through target %s must be declared in the source package", info.Name, rel.FieldName, thr.TargetType)
+		}
+		throughInfo := findStructInfo(allInfos, thr.TargetType)
+		throughPK, err := throughInfo.PrimaryKeyField()
+		if err != nil {
+			return fmt.Errorf("%s.%s: through target %s needs a single-column primary key", info.Name, rel.FieldName, thr.TargetType)
+		}
+		target := findStructInfo(allInfos, rel.TargetType)
+		if rel.TargetImportPath != "" || target == nil {
+			continue
+		}
+		fk := findFieldByColumn(target, rel.ForeignKey)
+		if fk == nil {
+			continue
+		}
+		if fkType := strings.TrimPrefix(fk.GoType, "*"); fkType != throughPK.GoType {
+			return fmt.Errorf("%s.%s: foreign key %s.%s is %s but primary key %s.%s is %s",
+				info.Name, rel.FieldName, target.Name, fk.Name, fkType, throughInfo.Name, throughPK.Name, throughPK.GoType)
+		}
+	}
+	return nil
+}
+
+// checkPolymorphicRelations reports an error unless the type and ID columns
+// of every polymorphic relation of info are fields of info, the type column
+// a non-pointer one the preloader can compare with the type value.
+func checkPolymorphicRelations(info *StructInfo) error {
+	for _, rel := range info.Relations {
+		if rel.RelType != "polymorphic" {
+			continue
+		}
+		typeField := findFieldByColumn(info, rel.TypeColumn)
+		switch {
+		case typeField == nil:
+			return fmt.Errorf("%s.%s: type column %s not found on %s", info.Name, rel.FieldName, rel.TypeColumn, info.Name)
+		case strings.HasPrefix(typeField.GoType, "*"):
+			return fmt.Errorf("%s.%s: type column %s must not be a pointer", info.Name, rel.FieldName, rel.TypeColumn)
+		case findFieldByColumn(info, rel.IDColumn) == nil:
+			return fmt.Errorf("%s.%s: id column %s not found on %s", info.Name, rel.FieldName, rel.IDColumn, info.Name)
+		}
+	}
+	return nil
+}
+
+// checkRelationKeyTypes reports a relation whose foreign key field and the
+// primary key it refers to have different Go types. Preloaders key a map by
+// one and look it up by the other, so a mismatch would not compile or would
+// silently match nothing. Targets outside the source package are not
+// parsed and are not checked, nor are many_to_many join tables.
+func checkRelationKeyTypes(info *StructInfo, pk *FieldInfo, allInfos []*StructInfo) error {
+	for _, rel := range info.Relations {
+		if rel.TargetImportPath != "" || rel.Through != "" {
+			continue
+		}
+		target := findStructInfo(allInfos, rel.TargetType)
+		if target == nil {
+			continue
+		}
+
+		var fkOwner, pkOwner *StructInfo
+		var fk, refPK *FieldInfo
+		switch rel.RelType {
+		case "belongs_to":
+			fkOwner, pkOwner = info, target
+			fk = findFieldByColumn(info, rel.ForeignKey)
+			refPK, _ = target.PrimaryKeyField()
+		case "has_many", "has_one":
+			fkOwner, pkOwner = target, info
+			fk = findFieldByColumn(target, rel.ForeignKey)
+			refPK = pk
+		case "polymorphic":
+			fkOwner, pkOwner = info, target
+			fk = findFieldByColumn(info, rel.IDColumn)
+			refPK, _ = target.PrimaryKeyField()
+		default:
+			continue
+		}
+		if fk == nil || refPK == nil {
+			continue
+		}
+		if fkType := strings.TrimPrefix(fk.GoType, "*"); fkType != refPK.GoType {
+			return fmt.Errorf("%s.%s: foreign key %s.%s is %s but primary key %s.%s is %s",
+				info.Name, rel.FieldName, fkOwner.Name, fk.Name, fkType, pkOwner.Name, refPK.Name, refPK.GoType)
+		}
+	}
+	return nil
+}
+
+func findFieldByColumn(info *StructInfo, column string) *FieldInfo {
+	for i := range info.Fields {
+		if info.Fields[i].Column == column {
+			return &info.Fields[i]
+		}
+	}
+	return nil
+}
+
+// declaredNames returns the package-level identifiers the template declares
+// for d, each paired with a description of what it is for error messages.
+func (d templateData) declaredNames() [][2]string {
+	names := [][2]string{
+		{d.FactoryName, "factory for " + d.TypeName},
+		{d.TableConst, "table constant for " + d.TypeName},
+		{d.ColumnsVar, "column list for " + d.TypeName},
+		{d.ScanFunc, "scan function for " + d.TypeName},
+		{d.ScanIntoFunc, "scan-into function for " + d.TypeName},
+	}
+	if len(d.Fields) > 0 {
+		names = append(names, [2]string{d.ColumnNamesVar, "column names for " + d.TypeName})
+	}
+	if !d.ReadOnly {
+		names = append(names, [2]string{d.ColValFunc, "column/value function for " + d.TypeName})
+	}
+	if d.PK != nil {
+		names = append(names, [2]string{d.GetPKFunc, "primary key getter for " + d.TypeName})
+	}
+	if d.IsIntPK {
+		names = append(names, [2]string{d.SetPKFunc, "primary key setter for " + d.TypeName})
+	}
+	if d.VersionField != nil {
+		names = append(names, [2]string{d.BumpVersionFunc, "version incrementer for " + d.TypeName})
+	}
+	if d.GeneratePKFunc != "" {
+		names = append(names, [2]string{d.GeneratePKFunc, "primary key generator for " + d.TypeName})
+	}
+	if len(d.CreatedAtFields) > 0 {
+		names = append(names, [2]string{d.SetCreatedAtFunc, "created_at setter for " + d.TypeName})
+	}
+	if len(d.UpdatedAtFields) > 0 {
+		names = append(names, [2]string{d.SetUpdatedAtFunc, "updated_at setter for " + d.TypeName})
+	}
+	for _, e := range d.EnumScopes {
+		names = append(names, [2]string{e.FuncName, "enum scope for " + d.TypeName + "." + e.Column})
+	}
+	for _, c := range d.ColumnScopes {
+		names = append(names, [2]string{c.FuncName, "column scope for " + d.TypeName + "." + c.Column})
+	}
+	if d.SortColumnType != "" {
+		names = append(names,
+			[2]string{d.SortColumnType, "sort column type for " + d.TypeName},
+			[2]string{d.ParseSortFunc, "sort column parser for " + d.TypeName},
+		)
+		for _, c := range d.SortColumns {
+			names = append(names, [2]string{c.Name, "sort column for " + d.TypeName + "." + c.Column})
+		}
+	}
+	if d.ServerTimestampsFunc != "" {
+		names = append(names, [2]string{d.ServerTimestampsFunc, "server timestamp fields for " + d.TypeName})
+	}
+	if d.ValidateFunc != "" {
+		names = append(names, [2]string{d.ValidateFunc, "validator for " + d.TypeName})
+	}
+	if d.DiffFunc != "" {
+		names = append(names, [2]string{d.DiffFunc, "diff helper for " + d.TypeName})
+	}
+	if d.RepoInterface != "" {
+		names = append(names,
+			[2]string{d.RepoInterface, "repository interface for " + d.TypeName},
+			[2]string{d.RepoCtor, "repository constructor for " + d.TypeName},
+			[2]string{d.RepoStruct, "repository implementation for " + d.TypeName},
+		)
+	}
+	if d.MockType != "" {
+		names = append(names, [2]string{d.MockType, "repository mock for " + d.TypeName})
+	}
+	for _, r := range d.Relations {
+		if r.MergerName != "" {
+			names = append(names, [2]string{r.MergerName, "join merge helper for " + d.TypeName + "." + r.FieldName})
+		}
+		if r.NoPreload {
+			continue
+		}
+		names = append(names,
+			[2]string{r.PreloaderName, "preloader for " + d.TypeName + "." + r.FieldName},
+			[2]string{r.PublicPreloaderName, "exported preloader for " + d.TypeName + "." + r.FieldName},
+		)
+	}
+	return names
+}
+
+// checkNameCollisions reports an error if two generated declarations share
+// a name. Without this check the collision would only surface as a compile
+// error in the generated file.
+func checkNameCollisions(structs []templateData, enums []*enumTypeData) error {
+	seen := make(map[string]string)
+	for _, e := range enums {
+		seen[e.ValuesVar] = "enum values of " + e.TypeName
+		seen[e.ParseFunc] = "enum parser for " + e.TypeName
+	}
+	for _, d := range structs {
+		for _, n := range d.declaredNames() {
+			if prev, ok := seen[n[0]]; ok {
+				return fmt.Errorf("generated name %s for %s collides with %s", n[0], n[1], prev)
+			}
+			seen[n[0]] = n[1]
+		}
+	}
+	return nil
+}
+
 func unexportedName(s string) string {
-	if s == "" {
-		return s
+	return naming.LowerFirstWord(s)
+}
+
+// helperName returns the unexported name of a generated helper. With a
+// prefix, the prefix leads and s is joined in CamelCase: ("model",
+// "scanUser") → "modelScanUser".
+func helperName(prefix, s string) string {
+	if prefix == "" {
+		return unexportedName(s)
 	}
-	runes := []rune(s)
-	runes[0] = unicode.ToLower(runes[0])
-	return string(runes)
+	return unexportedName(prefix) + strings.ToUpper(s[:1]) + s[1:]
 }
 
 func filterFields(fields []FieldInfo, pred func(FieldInfo) bool) []FieldInfo {
@@ -747,6 +1805,49 @@ func nullTypeFor(goType string) (nullType, nullField string) {
 	return "sql.NullInt64", ".Int64"
 }
 
+// diffCompare picks the comparison the Diff helper uses for goType:
+// == for builtin scalars, Equal for time.Time (== also compares the
+// location), and reflect.DeepEqual for everything else (pointers, slices,
+// custom types that may not be comparable).
+func diffCompare(goType string) string {
+	switch {
+	case goType == "string" || goType == "bool" || goType == "float32" || goType == "float64" || isIntType(goType):
+		return "eq"
+	case goType == "time.Time":
+		return "time"
+	default:
+		return "deep"
+	}
+}
+
+// qualifiedType returns the Go type of f as written in the generated file,
+// and the import it needs when the type comes from another package.
+// Predeclared types are used as-is; types declared next to the model get
+// typePrefix.
+func qualifiedType(f *FieldInfo, typePrefix string) (string, *importEntry) {
+	base := strings.TrimPrefix(f.GoType, "*")
+	ptr := f.GoType[:len(f.GoType)-len(base)]
+	pkgName, _, qualified := strings.Cut(base, ".")
+	switch {
+	case qualified && f.TypeImport != "":
+		ei := &importEntry{Path: f.TypeImport}
+		if path.Base(f.TypeImport) != pkgName {
+			ei.Alias = pkgName
+		}
+		return f.GoType, ei
+	case qualified || types.Universe.Lookup(base) != nil:
+		return f.GoType, nil
+	default:
+		return ptr + typePrefix + base, nil
+	}
+}
+
+// isUUIDType reports whether goType is a UUID type, such as uuid.UUID from
+// github.com/google/uuid or a UUID type declared in the model package.
+func isUUIDType(goType string) bool {
+	return goType == "UUID" || (strings.HasSuffix(goType, ".UUID") && !strings.HasPrefix(goType, "*"))
+}
+
 func isIntType(goType string) bool {
 	switch goType {
 	case "int", "int8", "int16", "int32", "int64",
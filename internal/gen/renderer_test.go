@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/mickamy/ormgen/internal/gen"
+	"github.com/mickamy/ormgen/internal/naming"
 )
 
 func findStruct(t *testing.T, infos []*gen.StructInfo, name string) *gen.StructInfo {
@@ -50,15 +51,23 @@ func TestRenderUser(t *testing.T) {
 		"package testdata",
 		"func Users(db orm.Querier) *orm.Query[User]",
 		`orm.ResolveTableName[User]("users")`,
+		`const UserTable = "users"`,
 		"usersColumns",
 		"scanUser",
 		"userColumnValuePairs",
 		"setUserPK",
+		"q.RegisterPK(getUserPK)",
+		"func getUserPK(v *User) any {\n\treturn v.ID\n}",
 		`case "id":`,
 		`case "name":`,
 		`case "created_at":`,
 		"dest[i] = &v.ID",
 		"dest[i] = &v.CreatedAt",
+		// scanUser delegates to scanUserInto, which fills a caller-provided value.
+		"func scanUserInto(rows *sql.Rows, v *User) error {",
+		"err := scanUserInto(rows, &v)",
+		// Unknown columns (e.g. added to the table but not the struct) are discarded.
+		"default:\n\t\t\tdest[i] = new(any)",
 		"v.ID = int(id)",
 		// User has CreatedAt and UpdatedAt by convention
 		"setUserCreatedAt",
@@ -125,6 +134,85 @@ func TestRenderNoPK(t *testing.T) {
 	}
 }
 
+func TestRenderCompositePK(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("composite_pk.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	infos[0].TableName = "memberships"
+
+	src, err := gen.Render(infos[0])
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	code := string(src)
+
+	want := `membershipsColumns, "",` + "\n\t\tscanMembership, membershipColumnValuePairs, nil,\n\t)\n\tq.RegisterCompositePK(\"tenant_id\", \"user_id\")"
+	if !strings.Contains(code, want) {
+		t.Errorf("missing %q in generated code:\n%s", want, code)
+	}
+	for _, unwanted := range []string{"getMembershipPK", "setMembershipPK"} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("unexpected %q in generated code:\n%s", unwanted, code)
+		}
+	}
+
+	infos[0].Relations = []gen.RelationInfo{{FieldName: "Grants", TargetType: "Grant", RelType: "has_many", ForeignKey: "membership_id", IsSlice: true}}
+	_, err = gen.Render(infos[0])
+	if want := "Membership.Grants: has_many relation needs a single-column primary key on Membership"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestRenderNameCollision(t *testing.T) {
+	t.Parallel()
+
+	pkOnly := func(name, table string, extra ...gen.FieldInfo) *gen.StructInfo {
+		return &gen.StructInfo{
+			Name:      name,
+			Package:   "model",
+			TableName: table,
+			Fields:    append([]gen.FieldInfo{{Name: "ID", Column: "id", GoType: "int", PrimaryKey: true}}, extra...),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		infos []*gen.StructInfo
+		want  string
+	}{
+		{
+			name:  "same table",
+			infos: []*gen.StructInfo{pkOnly("User", "users"), pkOnly("Member", "users")},
+			want:  "generated name Users for factory for Member collides with factory for User",
+		},
+		{
+			name: "factory and enum scope",
+			infos: []*gen.StructInfo{
+				pkOnly("Ticket", "tickets", gen.FieldInfo{Name: "Status", Column: "status", GoType: "Status", Enum: true}),
+				pkOnly("TicketStatus", "tickets_with_status"),
+			},
+			want: "generated name TicketsWithStatus for factory for TicketStatus collides with enum scope for Ticket.status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := gen.RenderFile(tt.infos, gen.RenderOption{})
+			if err == nil {
+				t.Fatal("expected collision error, got nil")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("err = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderFileMultipleStructs(t *testing.T) {
 	t.Parallel()
 
@@ -179,6 +267,7 @@ func TestRenderRelations(t *testing.T) {
 	findStruct(t, infos, "Tag").TableName = "tags"
 	findStruct(t, infos, "Comment").TableName = "comments"
 	findStruct(t, infos, "QRImage").TableName = "qr_images"
+	findStruct(t, infos, "Pseudonym").TableName = "pseudonyms"
 
 	src, err := gen.RenderFile(infos, gen.RenderOption{})
 	if err != nil {
@@ -203,6 +292,11 @@ func TestRenderRelations(t *testing.T) {
 		"Authors(db)",
 		// has_one preloader
 		"func preloadAuthorProfile(ctx context.Context, db orm.Querier, results []Author)",
+		// exported wrappers for slices fetched elsewhere
+		"func PreloadAuthorArticles(ctx context.Context, db orm.Querier, results []Author) error {",
+		"return preloadAuthorArticles(ctx, db, results)",
+		"func PreloadArticleAuthor(ctx context.Context, db orm.Querier, results []Article) error {",
+		"func PreloadAuthorTags(ctx context.Context, db orm.Querier, results []Author) error {",
 		// RegisterJoin with ResolveTableName
 		`q.RegisterJoin("Articles"`,
 		`q.RegisterJoin("Profile"`,
@@ -229,13 +323,13 @@ func TestRenderRelations(t *testing.T) {
 		// Imports
 		`"context"`,
 		`"github.com/mickamy/ormgen/scope"`,
-		// Nullable FK belongs_to (Comment.Author with *string FK)
+		// Nullable FK belongs_to (Comment.Author with *string FK to Pseudonym's string PK)
 		"func preloadCommentAuthor(ctx context.Context, db orm.Querier, results []Comment)",
 		// Should dereference pointer FK
 		"if results[i].AuthorID != nil {",
 		"ids = append(ids, *results[i].AuthorID)",
 		// Map key should be string, not *string
-		"byPK := make(map[string]*Author)",
+		"byPK := make(map[string]*Pseudonym)",
 		// Assignment should check nil
 		"results[i].Author = byPK[*results[i].AuthorID]",
 	}
@@ -261,6 +355,47 @@ func TestRenderRelations(t *testing.T) {
 	}
 }
 
+func TestRenderColumnNames(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	user := findStruct(t, infos, "User")
+	user.TableName = "users"
+
+	src, err := gen.Render(user)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := `// UserColumns holds the column name of each User field, for
+// building clauses without spelling columns out, e.g.
+// UserColumns.ID+" = ?".
+var UserColumns = struct {
+	ID        string
+	Name      string
+	Email     string
+	Role      string
+	Active    string
+	CreatedAt string
+	UpdatedAt string
+}{
+	ID:        "id",
+	Name:      "name",
+	Email:     "email",
+	Role:      "role",
+	Active:    "active",
+	CreatedAt: "created_at",
+	UpdatedAt: "updated_at",
+}
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("missing column names block in generated code:\n%s", src)
+	}
+}
+
 func TestRenderTimestamps(t *testing.T) {
 	t.Parallel()
 
@@ -315,6 +450,179 @@ func TestRenderTimestamps(t *testing.T) {
 	}
 }
 
+func TestRenderServerTimestamps(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("server_timestamps.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Event").TableName = "events"
+	findStruct(t, infos, "Ledger").TableName = "ledgers"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	checks := []string{
+		// Event: server createdAt, client updatedAt
+		`q.RegisterServerTimestamps([]string{"created_at"}, eventServerTimestamps)`,
+		"func eventServerTimestamps(v *Event) []any {\n\treturn []any{&v.CreatedAt}\n}",
+		"q.RegisterTimestamps(\n\t\tnil,\n\t\tnil,\n\t\t[]string{\"updated_at\"},\n\t\tsetEventUpdatedAt,",
+		// Ledger: both server-managed, so no Clock setters at all
+		`q.RegisterServerTimestamps([]string{"recorded_at", "settled_at"}, ledgerServerTimestamps)`,
+		"return []any{&v.RecordedAt, &v.SettledAt}",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	negativeChecks := []string{
+		"setEventCreatedAt",
+		"setLedgerCreatedAt",
+		"setLedgerUpdatedAt",
+	}
+	for _, unwanted := range negativeChecks {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("unexpected %q in generated code:\n%s", unwanted, code)
+		}
+	}
+}
+
+func TestRenderSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("soft_delete.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Account").TableName = "accounts"
+	findStruct(t, infos, "Archive").TableName = "archives"
+	findStruct(t, infos, "Audit").TableName = "audits"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	for _, want := range []string{
+		`q.RegisterSoftDelete("deleted_at")`,
+		`q.RegisterSoftDelete("removed_at")`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+	if n := strings.Count(code, "q.RegisterSoftDelete("); n != 2 {
+		t.Errorf("RegisterSoftDelete calls = %d, want 2 (not for Audit)", n)
+	}
+
+	archive := findStruct(t, infos, "Archive")
+	archive.Fields = append(archive.Fields, gen.FieldInfo{Name: "PurgedAt", Column: "purged_at", GoType: "*time.Time", DeletedAt: true})
+	if _, err := gen.Render(archive); err == nil {
+		t.Error("expected error for multiple deletedAt fields, got nil")
+	}
+}
+
+func TestRenderVersion(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("version.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	doc := findStruct(t, infos, "Document")
+	doc.TableName = "documents"
+
+	if !doc.Fields[2].Version {
+		t.Fatalf("Fields[2] = %+v, want Version", doc.Fields[2])
+	}
+
+	src, err := gen.Render(doc)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	code := string(src)
+	checks := []string{
+		`q.RegisterVersion("version", bumpDocumentVersion)`,
+		"func bumpDocumentVersion(v *Document) {\n\tv.Version++\n}",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	doc.Fields[2].GoType = "string"
+	if _, err := gen.Render(doc); err == nil {
+		t.Error("expected error for a non-integer version field, got nil")
+	}
+}
+
+func TestRenderGeneratedPK(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("generated_keys.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	session := findStruct(t, infos, "Session")
+	session.TableName = "sessions"
+	apiKey := findStruct(t, infos, "APIKey")
+	apiKey.TableName = "api_keys"
+
+	if !apiKey.Fields[0].Generated {
+		t.Fatalf("Fields[0] = %+v, want Generated", apiKey.Fields[0])
+	}
+
+	src, err := gen.RenderFile([]*gen.StructInfo{session, apiKey}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+	checks := []string{
+		"q.RegisterPKGenerator(generateSessionPK)",
+		"func generateSessionPK(v *Session) {\n\torm.GenerateKey(&v.ID)\n}",
+		"q.RegisterPKGenerator(generateAPIKeyPK)",
+		"func generateAPIKeyPK(v *APIKey) {\n\torm.GenerateKey(&v.Key)\n}",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	session.Fields[0].GoType = "uuid.UUID"
+	if src, err := gen.Render(session); err != nil || !strings.Contains(string(src), "q.RegisterPKGenerator(generateSessionPK)") {
+		t.Errorf("uuid.UUID primary key: err = %v, want a PK generator", err)
+	}
+
+	session.Fields[0].GoType = "string"
+	if src, err := gen.Render(session); err != nil || strings.Contains(string(src), "RegisterPKGenerator") {
+		t.Errorf("untagged string primary key: err = %v, want no PK generator", err)
+	}
+
+	apiKey.Fields[0].GoType = "int64"
+	if _, err := gen.Render(apiKey); err == nil {
+		t.Error("expected error for a generated integer primary key, got nil")
+	}
+
+	apiKey.Fields[0].GoType = "string"
+	apiKey.Fields[1].Generated = true
+	if _, err := gen.Render(apiKey); err == nil {
+		t.Error("expected error for a generated non-key field, got nil")
+	}
+}
+
 func TestRenderFileCrossPackage(t *testing.T) {
 	t.Parallel()
 
@@ -371,6 +679,7 @@ func TestRenderJoinScan(t *testing.T) {
 	findStruct(t, infos, "Tag").TableName = "tags"
 	findStruct(t, infos, "Comment").TableName = "comments"
 	findStruct(t, infos, "QRImage").TableName = "qr_images"
+	findStruct(t, infos, "Pseudonym").TableName = "pseudonyms"
 
 	src, err := gen.RenderFile(infos, gen.RenderOption{})
 	if err != nil {
@@ -380,11 +689,11 @@ func TestRenderJoinScan(t *testing.T) {
 	code := string(src)
 
 	checks := []string{
-		// belongs_to (non-pointer): Article.Author — scan directly into v.Author.Field
+		// belongs_to (non-pointer): Article.Author — scan into v.Author.Field, NULL-safe for LEFT JOIN
 		`case "Author__id":`,
-		`dest[i] = &v.Author.ID`,
+		`dest[i] = orm.SkipNull(&v.Author.ID)`,
 		`case "Author__name":`,
-		`dest[i] = &v.Author.Name`,
+		`dest[i] = orm.SkipNull(&v.Author.Name)`,
 		// SelectColumns in RegisterJoin for belongs_to
 		`SelectColumns: []string{"id", "name"},`,
 		// has_one (pointer): Author.Profile — uses NullInt64 + temp struct
@@ -393,19 +702,17 @@ func TestRenderJoinScan(t *testing.T) {
 		`case "Profile__id":`,
 		`dest[i] = &joinScanProfilePK`,
 		`case "Profile__bio":`,
-		`dest[i] = &joinScanProfile.Bio`,
+		`dest[i] = orm.SkipNull(&joinScanProfile.Bio)`,
 		`if joinScanProfilePK.Valid {`,
 		`joinScanProfile.ID = int(joinScanProfilePK.Int64)`,
 		`v.Profile = &joinScanProfile`,
+		// A reused destination must not keep the previous row's relation.
+		"} else {\n\t\tv.Profile = nil\n\t}",
 		// SelectColumns in RegisterJoin for has_one
 		`SelectColumns: []string{"id", "author_id", "bio"},`,
 	}
 
 	negativeChecks := []string{
-		// has_many (Articles) should NOT have SelectColumns
-		// Check that Articles RegisterJoin doesn't have SelectColumns by
-		// looking for a pattern that would only appear if has_many got SelectColumns.
-		`SelectColumns: []string{"id", "author_id", "title"},`,
 		// many_to_many (Tags) should NOT have join scan
 		`joinScanTag`,
 	}
@@ -422,6 +729,98 @@ func TestRenderJoinScan(t *testing.T) {
 	}
 }
 
+func TestRenderJoinScanHasMany(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relations.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	findStruct(t, infos, "Author").TableName = "authors"
+	findStruct(t, infos, "Article").TableName = "articles"
+	findStruct(t, infos, "Profile").TableName = "profiles"
+	findStruct(t, infos, "Tag").TableName = "tags"
+	findStruct(t, infos, "Comment").TableName = "comments"
+	findStruct(t, infos, "QRImage").TableName = "qr_images"
+	findStruct(t, infos, "Pseudonym").TableName = "pseudonyms"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	checks := []string{
+		`SelectColumns: []string{"id", "author_id", "title"},`,
+		`q.RegisterMerge("Articles", mergeAuthorArticles)`,
+		`func scanAuthorInto(rows *sql.Rows, v *Author) error {
+	cols, _ := rows.Columns()
+	var joinScanArticlesPK sql.NullInt64
+	var joinScanArticles Article
+	joinScanArticlesSeen := false
+	var joinScanProfilePK sql.NullInt64
+	var joinScanProfile Profile
+	dest := make([]any, len(cols))
+	for i, col := range cols {
+		switch col {
+		case "id":
+			dest[i] = &v.ID
+		case "name":
+			dest[i] = &v.Name
+		case "Articles__id":
+			dest[i] = &joinScanArticlesPK
+			joinScanArticlesSeen = true
+		case "Articles__author_id":
+			dest[i] = orm.SkipNull(&joinScanArticles.AuthorID)
+		case "Articles__title":
+			dest[i] = orm.SkipNull(&joinScanArticles.Title)
+		case "Profile__id":
+			dest[i] = &joinScanProfilePK
+		case "Profile__author_id":
+			dest[i] = orm.SkipNull(&joinScanProfile.AuthorID)
+		case "Profile__bio":
+			dest[i] = orm.SkipNull(&joinScanProfile.Bio)
+		default:
+			dest[i] = new(any)
+		}
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if joinScanArticlesSeen {
+		if joinScanArticlesPK.Valid {
+			joinScanArticles.ID = int(joinScanArticlesPK.Int64)
+			v.Articles = []Article{joinScanArticles}
+		} else {
+			v.Articles = []Article{}
+		}
+	}
+	if joinScanProfilePK.Valid {
+		joinScanProfile.ID = int(joinScanProfilePK.Int64)
+		v.Profile = &joinScanProfile
+	} else {
+		v.Profile = nil
+	}
+	return nil
+}`,
+		`func mergeAuthorArticles(dst, src *Author) {
+	dst.Articles = append(dst.Articles, src.Articles...)
+}`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	// many_to_many relations are never join-scanned, so they get no merger.
+	if strings.Contains(code, "mergeAuthorTags") {
+		t.Errorf("unexpected merger for many_to_many relation:\n%s", code)
+	}
+}
+
 func TestRenderCrossPackageRelations(t *testing.T) {
 	t.Parallel()
 
@@ -492,3 +891,977 @@ func TestRenderCrossPackageRelations(t *testing.T) {
 		t.Errorf("unexpected bare %q in generated code:\n%s", "OAuthAccounts(db)", code)
 	}
 }
+
+func TestRenderIsDialectAgnostic(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relations.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	findStruct(t, infos, "Author").TableName = "authors"
+	findStruct(t, infos, "Article").TableName = "articles"
+	findStruct(t, infos, "Profile").TableName = "profiles"
+	findStruct(t, infos, "Tag").TableName = "tags"
+	findStruct(t, infos, "Comment").TableName = "comments"
+	findStruct(t, infos, "QRImage").TableName = "qr_images"
+	findStruct(t, infos, "Pseudonym").TableName = "pseudonyms"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	// Generated code must leave all dialect decisions to the Querier at
+	// runtime so the same factories work against MySQL and PostgreSQL.
+	negativeChecks := []string{
+		"`",
+		"$1",
+		"RETURNING",
+		"ON DUPLICATE KEY",
+		"ON CONFLICT",
+		"orm.MySQL",
+		"orm.PostgreSQL",
+	}
+	for _, unwanted := range negativeChecks {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("unexpected dialect-specific %q in generated code:\n%s", unwanted, code)
+		}
+	}
+}
+
+func TestRenderTrickyTypeNames(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("tricky_names.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for _, info := range infos {
+		info.TableName = naming.TableName(info.Name)
+	}
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "tricky_names_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	checks := []string{
+		// APIKey
+		"func APIKeys(db orm.Querier) *orm.Query[APIKey]",
+		`orm.ResolveTableName[APIKey]("api_keys")`,
+		`var apiKeysColumns = []string{"id", "api_key_id", "secret"}`,
+		"func apiKeyColumnValuePairs(v *APIKey, includesPK bool)",
+		// OAuthToken
+		"func OAuthTokens(db orm.Querier) *orm.Query[OAuthToken]",
+		`orm.ResolveTableName[OAuthToken]("oauth_tokens")`,
+		`var oauthTokensColumns = []string{"id", "oauth_user_id", "access_token"}`,
+		"func oauthTokenColumnValuePairs(v *OAuthToken, includesPK bool)",
+		// URLMapping
+		"func URLMappings(db orm.Querier) *orm.Query[URLMapping]",
+		`orm.ResolveTableName[URLMapping]("url_mappings")`,
+		`var urlMappingsColumns = []string{"id", "source_url", "target_url"}`,
+		"func urlMappingColumnValuePairs(v *URLMapping, includesPK bool)",
+		// IPv4Range
+		"func IPv4Ranges(db orm.Querier) *orm.Query[IPv4Range]",
+		`orm.ResolveTableName[IPv4Range]("ipv4_ranges")`,
+		`var ipv4RangesColumns = []string{"id", "ipv4_from", "ipv4_to"}`,
+		"func ipv4RangeColumnValuePairs(v *IPv4Range, includesPK bool)",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+}
+
+func TestRenderTypeAliases(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("aliases.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	infos[0].TableName = "accounts"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{Diff: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	checks := []string{
+		// An int alias PK is auto-generated.
+		"scanAccount, accountColumnValuePairs, setAccountPK,",
+		"func setAccountPK(v *Account, id int64) {\n\tv.ID = int64(id)",
+		// A time.Time alias is compared with Equal.
+		"!before.CreatedAt.Equal(after.CreatedAt)",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+}
+
+func TestRenderReadOnly(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("readonly.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Tag").TableName = "tags"
+	findStruct(t, infos, "ActiveMember").TableName = "active_members"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "readonly_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	checks := []string{
+		// PK-less: empty PK column, no column/value or PK functions.
+		`tagsColumns, "",` + "\n\t\tscanTag, nil, nil,\n\t)\n\tq.RegisterReadOnly()\n\treturn q",
+		// With a PK: PK getter only, no setter.
+		`activeMembersColumns, "id",` + "\n\t\tscanActiveMember, nil, nil,\n\t)\n\tq.RegisterPK(getActiveMemberPK)\n\tq.RegisterReadOnly()",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	negativeChecks := []string{
+		"getTagPK",
+		"tagColumnValuePairs",
+		"activeMemberColumnValuePairs",
+		"setActiveMemberPK",
+		"RegisterTimestamps",
+		`"time"`,
+	}
+	for _, unwanted := range negativeChecks {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("unexpected %q in generated code:\n%s", unwanted, code)
+		}
+	}
+}
+
+func TestRenderReadOnlyWithoutPKRejectsParentRelations(t *testing.T) {
+	t.Parallel()
+
+	info := &gen.StructInfo{
+		Name:      "Category",
+		Package:   "model",
+		TableName: "categories",
+		ReadOnly:  true,
+		Fields:    []gen.FieldInfo{{Name: "Code", Column: "code", GoType: "string"}},
+		Relations: []gen.RelationInfo{{FieldName: "Items", TargetType: "Item", RelType: "has_many", ForeignKey: "category_code", IsSlice: true}},
+	}
+
+	_, err := gen.RenderFile([]*gen.StructInfo{info}, gen.RenderOption{})
+	if err == nil {
+		t.Fatal("expected error for has_many on a read-only struct without primary key, got nil")
+	}
+	if want := "Category.Items: has_many relation needs a primary key on Category"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
+
+func TestRenderPluralOverrides(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relations.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	plurals := naming.Plurals{"profile": "profile"}
+	for _, info := range infos {
+		info.TableName = plurals.TableName(info.Name)
+	}
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{Plurals: plurals})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+
+	checks := []string{
+		// Factory for the overridden table
+		"func Profile(db orm.Querier) *orm.Query[Profile]",
+		`orm.ResolveTableName[Profile]("profile")`,
+		// Relation target table also uses the override
+		`TargetTable: orm.ResolveTableName[Profile]("profile"), TargetColumn: "author_id",`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, `"profiles"`) {
+		t.Errorf("unexpected default plural %q in generated code:\n%s", "profiles", code)
+	}
+}
+
+func TestRenderEnumScopes(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("enums.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Ticket").TableName = "tickets"
+
+	tests := []struct {
+		name   string
+		opt    gen.RenderOption
+		checks []string
+	}{
+		{
+			name: "same package",
+			opt:  gen.RenderOption{},
+			checks: []string{
+				`"github.com/mickamy/ormgen/scope"`,
+				"func TicketsWithStatus(v Status) scope.Scope {",
+				`return scope.Where("status = ?", v)`,
+				"func TicketsWithPriority(v Priority) scope.Scope {",
+				`return scope.Where("prio = ?", v)`,
+			},
+		},
+		{
+			name: "cross package",
+			opt:  gen.RenderOption{DestPkg: "query", SourceImport: "github.com/example/model"},
+			checks: []string{
+				"func TicketsWithStatus(v model.Status) scope.Scope {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := gen.RenderFile(infos, tt.opt)
+			if err != nil {
+				t.Fatalf("RenderFile: %v", err)
+			}
+			code := string(src)
+
+			fset := token.NewFileSet()
+			if _, err := parser.ParseFile(fset, "enums_gen.go", src, 0); err != nil {
+				t.Fatalf("generated code does not parse: %v\n%s", err, code)
+			}
+
+			for _, want := range tt.checks {
+				if !strings.Contains(code, want) {
+					t.Errorf("missing %q in generated code:\n%s", want, code)
+				}
+			}
+			for _, bad := range []string{"TicketsWithLabel", "TicketsWithTags", "TicketsWithTitle", "TicketsWithOwner"} {
+				if strings.Contains(code, bad) {
+					t.Errorf("unexpected %q in generated code:\n%s", bad, code)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderNoScopeImportWithoutScopes(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("comments.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	account := findStruct(t, infos, "Account")
+	account.TableName = "accounts"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{account}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	if strings.Contains(string(src), `"github.com/mickamy/ormgen/scope"`) {
+		t.Errorf("unexpected scope import:\n%s", src)
+	}
+}
+
+func TestRenderHelperPrefix(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	user := findStruct(t, infos, "User")
+	user.TableName = "users"
+	post := findStruct(t, infos, "Post")
+	post.TableName = "posts"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{user, post}, gen.RenderOption{HelperPrefix: "Model"})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	checks := []string{
+		"var modelUsersColumns = []string{",
+		"func modelScanUser(rows *sql.Rows) (User, error) {",
+		"func modelUserColumnValuePairs(",
+		"func modelSetUserPK(v *User, id int64) {",
+		"func modelPreloadUserPosts(",
+		"func PreloadUserPosts(",
+		"func Users(db orm.Querier) *orm.Query[User] {",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "func scanUser(") {
+		t.Errorf("unprefixed helper in generated code:\n%s", code)
+	}
+
+	if _, err := gen.RenderFile([]*gen.StructInfo{user}, gen.RenderOption{HelperPrefix: "my-pkg"}); err == nil {
+		t.Error("expected error for a prefix that is not an identifier")
+	}
+}
+
+func TestRenderRelationKeyTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("key_types.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for _, info := range infos {
+		info.TableName = naming.Plurals(nil).TableName(info.Name)
+	}
+	owner := findStruct(t, infos, "Owner")
+	pet := findStruct(t, infos, "Pet")
+	collar := findStruct(t, infos, "Collar")
+
+	tests := []struct {
+		name    string
+		render  *gen.StructInfo
+		wantErr string
+	}{
+		{
+			name:    "has_many",
+			render:  owner,
+			wantErr: "Owner.Pets: foreign key Pet.OwnerID is int32 but primary key Owner.ID is int64",
+		},
+		{
+			name:    "belongs_to",
+			render:  pet,
+			wantErr: "Pet.Owner: foreign key Pet.OwnerID is int32 but primary key Owner.ID is int64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := gen.RenderFile([]*gen.StructInfo{tt.render}, gen.RenderOption{PeerInfos: infos})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A pointer FK matches the primary key type it points to.
+	if _, err := gen.RenderFile([]*gen.StructInfo{collar}, gen.RenderOption{PeerInfos: infos}); err != nil {
+		t.Errorf("RenderFile(Collar): %v", err)
+	}
+}
+
+func TestRenderSortColumns(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("comments.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	account := findStruct(t, infos, "Account")
+	account.TableName = "accounts"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{account}, gen.RenderOption{SortColumns: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	checks := []string{
+		`"github.com/mickamy/ormgen/scope"`,
+		"type AccountSortColumn string",
+		`AccountSortByID      AccountSortColumn = "id"`,
+		`AccountSortByBalance AccountSortColumn = "balance"`,
+		"func ParseAccountSortColumn(s string) (AccountSortColumn, error) {",
+		"return orm.ParseSortColumn(s, AccountSortByID, AccountSortByName, AccountSortByBalance, AccountSortByPlan)",
+		"func (c AccountSortColumn) Asc() scope.Scope { return scope.Asc(string(c)) }",
+		"func (c AccountSortColumn) Desc() scope.Scope { return scope.Desc(string(c)) }",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	// Without the option, no sort column type is emitted.
+	src, err = gen.RenderFile([]*gen.StructInfo{account}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	if strings.Contains(string(src), "AccountSortColumn") {
+		t.Errorf("unexpected AccountSortColumn without SortColumns option:\n%s", src)
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	user := findStruct(t, infos, "User")
+	user.TableName = "users"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{user}, gen.RenderOption{Diff: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	checks := []string{
+		"func UserDiff(before, after *User) (cols []string, oldVals, newVals []any) {",
+		"if before.Name != after.Name {",
+		`cols = append(cols, "name")`,
+		"oldVals = append(oldVals, before.Name)",
+		"newVals = append(newVals, after.Name)",
+		"if before.Active != after.Active {",
+		"if !before.CreatedAt.Equal(after.CreatedAt) {",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, `"reflect"`) {
+		t.Errorf("reflect should not be imported when all columns are comparable:\n%s", code)
+	}
+
+	// Without the option, no Diff helper is emitted.
+	src, err = gen.RenderFile([]*gen.StructInfo{user}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	if strings.Contains(string(src), "UserDiff") {
+		t.Errorf("unexpected UserDiff without Diff option:\n%s", src)
+	}
+}
+
+func TestRenderDiffDeepEqualFallback(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("custom_types.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	repo := findStruct(t, infos, "Repository")
+	repo.TableName = "repositories"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{repo}, gen.RenderOption{Diff: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "custom_types_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	checks := []string{
+		`"reflect"`,
+		"if !reflect.DeepEqual(before.Topics, after.Topics) {",
+		"if before.ID != after.ID {",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+}
+
+func TestRenderColumnTypeChecks(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("column_types.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	place := findStruct(t, infos, "Place")
+	place.TableName = "places"
+
+	tests := []struct {
+		name           string
+		opt            gen.RenderOption
+		checks         []string
+		negativeChecks []string
+	}{
+		{
+			name: "same package",
+			opt:  gen.RenderOption{},
+			checks: []string{
+				`"database/sql/driver"`,
+				"sql.Scanner   = (*Labels)(nil)",
+				"driver.Valuer = *new(Labels)",
+				// Point lacks Value and Plain both methods: asserted anyway so
+				// that the build fails.
+				"sql.Scanner   = (*Point)(nil)",
+				"driver.Valuer = *new(Point)",
+				"sql.Scanner   = (*Plain)(nil)",
+				"driver.Valuer = *new(Plain)",
+			},
+			negativeChecks: []string{
+				"(*Kind)",
+				"new(Kind)",
+			},
+		},
+		{
+			name: "cross package",
+			opt:  gen.RenderOption{SourceImport: "github.com/example/model", DestPkg: "query"},
+			checks: []string{
+				"sql.Scanner   = (*model.Labels)(nil)",
+				"driver.Valuer = *new(model.Labels)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := gen.RenderFile([]*gen.StructInfo{place}, tt.opt)
+			if err != nil {
+				t.Fatalf("RenderFile: %v", err)
+			}
+			code := string(src)
+
+			// Labels and Extra share one check.
+			if n := strings.Count(code, "_ sql.Scanner"); n != 3 {
+				t.Errorf("got %d sql.Scanner checks, want 3:\n%s", n, code)
+			}
+
+			for _, want := range tt.checks {
+				if !strings.Contains(code, want) {
+					t.Errorf("missing %q in generated code:\n%s", want, code)
+				}
+			}
+			for _, unwanted := range tt.negativeChecks {
+				if strings.Contains(code, unwanted) {
+					t.Errorf("unexpected %q in generated code:\n%s", unwanted, code)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderColumnTypeChecksAcrossFiles(t *testing.T) {
+	t.Parallel()
+
+	path := testdataPath("columntypes/place.go")
+	infos, err := gen.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	gen.ParseColumnTypes(path, gen.PeerOption{}).Resolve(infos)
+	place := findStruct(t, infos, "Place")
+	place.TableName = "places"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{place}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	checks := []string{
+		// Labels is declared in types.go and scanned by scan.go.
+		"sql.Scanner   = (*Labels)(nil)",
+		"driver.Valuer = *new(Labels)",
+		// Point declares neither method, so the generated code must not build.
+		"sql.Scanner   = (*Point)(nil)",
+		"driver.Valuer = *new(Point)",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "(*Kind)") || strings.Contains(code, "new(Kind)") {
+		t.Errorf("unexpected check of native Kind in generated code:\n%s", code)
+	}
+}
+
+func TestRenderNoDriverImportWithoutCustomTypes(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	user := findStruct(t, infos, "User")
+	user.TableName = "users"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{user}, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	for _, unwanted := range []string{`"database/sql/driver"`, "sql.Scanner"} {
+		if strings.Contains(string(src), unwanted) {
+			t.Errorf("unexpected %q in generated code:\n%s", unwanted, src)
+		}
+	}
+}
+
+func TestRenderRelationOptions(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("relation_opts.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	author := findStruct(t, infos, "Author")
+	author.TableName = "authors"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{author}, gen.RenderOption{PeerInfos: infos})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	code := string(src)
+
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "relation_opts_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	checks := []string{
+		`q.RegisterJoin("Books", orm.JoinConfig{`,
+		`q.RegisterPreloader("Profile", preloadAuthorProfile)`,
+		"func preloadAuthorProfile(",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in generated code:\n%s", want, code)
+		}
+	}
+
+	for _, bad := range []string{
+		`q.RegisterPreloader("Books"`,
+		"func preloadAuthorBooks(",
+		"func PreloadAuthorBooks(",
+		`q.RegisterJoin("Profile"`,
+		`"Profile__`,
+		`"Archived"`,
+		"preloadAuthorArchived",
+		"preloadAuthorAwards",
+		`"Mentor"`,
+		"preloadAuthorMentor",
+		"joinScanMentor",
+	} {
+		if strings.Contains(code, bad) {
+			t.Errorf("unexpected %q in generated code:\n%s", bad, code)
+		}
+	}
+}
+
+func TestRenderSelfReferentialGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("self_ref.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	category := findStruct(t, infos, "Category")
+	category.TableName = "categories"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "self_ref.golden", src)
+}
+
+func parseThrough(t *testing.T) []*gen.StructInfo {
+	t.Helper()
+
+	infos, err := gen.Parse(testdataPath("through.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Author").TableName = "authors"
+	findStruct(t, infos, "Post").TableName = "posts"
+	findStruct(t, infos, "Comment").TableName = "comments"
+	return infos
+}
+
+func TestRenderHasManyThroughGolden(t *testing.T) {
+	t.Parallel()
+
+	src, err := gen.RenderFile(parseThrough(t), gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "through.golden", src)
+}
+
+func TestRenderHasManyThroughErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		through string
+		relType string
+		wantErr string
+	}{
+		{"unknown relation", "Articles", "has_many", "through relation Articles not found on Author"},
+		{"not has_many", "Posts", "has_one", "through is only supported on has_many relations"},
+		{"nested through", "Comments", "has_many", "through relation Comments must be a direct has_many relation"},
+	}
+	for _, tt := range tests {
+		infos := parseThrough(t)
+		rel := &findStruct(t, infos, "Author").Relations[1]
+		rel.Through = tt.through
+		rel.RelType = tt.relType
+		_, err := gen.RenderFile(infos, gen.RenderOption{})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderHasManyThroughKeyTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	infos := parseThrough(t)
+	// Comment.PostID must match Post.ID (int64), not Author.ID (int).
+	findStruct(t, infos, "Comment").Fields[1].GoType = "*int"
+
+	_, err := gen.RenderFile(infos, gen.RenderOption{})
+	want := "Author.Comments: foreign key Comment.PostID is int but primary key Post.ID is int64"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func parsePolymorphic(t *testing.T) []*gen.StructInfo {
+	t.Helper()
+
+	infos, err := gen.Parse(testdataPath("polymorphic.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Comment").TableName = "comments"
+	findStruct(t, infos, "Post").TableName = "posts"
+	findStruct(t, infos, "Photo").TableName = "photos"
+	return infos
+}
+
+func TestRenderPolymorphicGolden(t *testing.T) {
+	t.Parallel()
+
+	src, err := gen.RenderFile(parsePolymorphic(t), gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "polymorphic.golden", src)
+}
+
+func TestRenderPolymorphicErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		typeColumn string
+		idColumn   string
+		wantErr    string
+	}{
+		{"unknown type column", "kind", "commentable_id", "type column kind not found on Comment"},
+		{"unknown id column", "commentable_type", "target_id", "id column target_id not found on Comment"},
+		{"key type mismatch", "commentable_type", "body", "foreign key Comment.Body is string but primary key Post.ID is int"},
+	}
+	for _, tt := range tests {
+		infos := parsePolymorphic(t)
+		rel := &findStruct(t, infos, "Comment").Relations[0]
+		rel.TypeColumn = tt.typeColumn
+		rel.IDColumn = tt.idColumn
+		_, err := gen.RenderFile(infos, gen.RenderOption{})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderColumnAccessGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("column_access.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Order").TableName = "orders"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "column_access.golden", src)
+}
+
+func TestRenderColumnAccessErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(f *gen.FieldInfo)
+		field   int
+		wantErr string
+	}{
+		{"both", func(f *gen.FieldInfo) { f.Immutable = true }, 3, "cannot be both readonly and immutable"},
+		{"readonly primary key", func(f *gen.FieldInfo) { f.ReadOnly = true }, 0, "readonly field cannot be a primary key"},
+		{"readonly version", func(f *gen.FieldInfo) { f.Version = true }, 3, "version field cannot be readonly or immutable"},
+		{"immutable updatedAt", func(f *gen.FieldInfo) { f.UpdatedAt = true }, 1, "updatedAt field cannot be immutable"},
+	}
+	for _, tt := range tests {
+		infos, err := gen.Parse(testdataPath("column_access.go"))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		order := findStruct(t, infos, "Order")
+		tt.modify(&order.Fields[tt.field])
+		_, err = gen.RenderFile(infos, gen.RenderOption{})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderColumnScopesGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("column_scopes.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Account").TableName = "accounts"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{ColumnScopes: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "column_scopes.golden", src)
+}
+
+func TestRenderColumnScopesDestPkg(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("column_scopes.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Account").TableName = "accounts"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{
+		ColumnScopes: true,
+		DestPkg:      "query",
+		SourceImport: "github.com/example/model",
+	})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"func AccountsByID(v int64) scope.Scope {",
+		"func AccountsByEmail(v string) scope.Scope {\n\treturn scope.Where(\"email = ?\", v)\n}",
+		"func AccountsByPlan(v model.Plan) scope.Scope {",
+		"func AccountsByOwnerKey(v guuid.UUID) scope.Scope {",
+		"func AccountsByClosedAt(v time.Time) scope.Scope {",
+		`guuid "github.com/google/uuid"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q", want)
+		}
+	}
+}
+
+func TestRenderEmbeddedGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("embedded.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Note").TableName = "notes"
+	findStruct(t, infos, "Revision").TableName = "revisions"
+	findStruct(t, infos, "Draft").TableName = "drafts"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "embedded.golden", src)
+}
+
+func TestRenderEnumValuesGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("enum_values.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Member").TableName = "members"
+	findStruct(t, infos, "Invite").TableName = "invites"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "enum_values.golden", src)
+}
+
+func TestRenderEnumValuesMismatch(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("enum_values.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Member").TableName = "members"
+	invite := findStruct(t, infos, "Invite")
+	invite.TableName = "invites"
+	invite.Fields[1].EnumValues = []string{"active", "revoked"}
+
+	_, err = gen.RenderFile(infos, gen.RenderOption{})
+	if err == nil || !strings.Contains(err.Error(), "enum values of MemberStatus differ from those on Member.Status") {
+		t.Errorf("err = %v, want an enum mismatch error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package gen_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/mickamy/ormgen/internal/gen"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestRenderRepoGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("repo.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "Customer").TableName = "customers"
+	findStruct(t, infos, "Device").TableName = "devices"
+	findStruct(t, infos, "CustomerTotal").TableName = "customer_totals"
+
+	src, err := gen.RenderFile(infos, gen.RenderOption{
+		DestPkg:      "query",
+		SourceImport: "github.com/example/app/model",
+		Repo:         true,
+	})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+
+	checkGolden(t, "repo.golden", src)
+}
+
+func TestRenderMockGolden(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("repo.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	customer := findStruct(t, infos, "Customer")
+	customer.TableName = "customers"
+
+	src, err := gen.RenderFile([]*gen.StructInfo{customer}, gen.RenderOption{Repo: true, Mock: true})
+	if err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	checkGolden(t, "mock.golden", src)
+
+	if _, err := gen.RenderFile([]*gen.StructInfo{customer}, gen.RenderOption{Mock: true}); err == nil {
+		t.Error("Mock without Repo: expected an error, got nil")
+	}
+}
+
+// checkGolden compares src with testdata/name, rewriting the file first
+// when the test runs with -update.
+func checkGolden(t *testing.T, name string, src []byte) {
+	t.Helper()
+
+	golden := testdataPath(name)
+	if *update {
+		if err := os.WriteFile(golden, src, 0o600); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if string(src) != string(want) {
+		t.Errorf("generated code differs from %s; rerun with -update to accept:\n%s", golden, src)
+	}
+}
+
+func TestRenderRepoNameCollision(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("repo.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	customer := findStruct(t, infos, "Customer")
+	customer.TableName = "customers"
+	clash := *customer
+	clash.Name = "Legacy"
+	clash.TableName = "customer_repository" // factory CustomerRepository
+
+	if _, err := gen.RenderFile([]*gen.StructInfo{customer, &clash}, gen.RenderOption{Repo: true}); err == nil {
+		t.Error("expected a name collision error, got nil")
+	}
+}
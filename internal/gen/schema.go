@@ -0,0 +1,33 @@
+package gen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON document written by RenderSchema.
+// It is bumped when a field is renamed or removed; new fields may be added
+// without a bump.
+const SchemaVersion = 1
+
+// Schema is the JSON document written by RenderSchema.
+type Schema struct {
+	Version int           `json:"version"`
+	Structs []*StructInfo `json:"structs"`
+}
+
+// RenderSchema serializes the parsed structs, their fields and relations as
+// indented JSON, for tools (migration diffing, docs, other generators) that
+// consume ormgen's view of the models without importing this package.
+// TableName must already be set on each StructInfo, as for RenderFile.
+func RenderSchema(infos []*StructInfo) ([]byte, error) {
+	if len(infos) == 0 {
+		return nil, errors.New("no structs to render")
+	}
+	b, err := json.MarshalIndent(Schema{Version: SchemaVersion, Structs: infos}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+	return append(b, '\n'), nil
+}
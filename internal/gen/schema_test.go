@@ -0,0 +1,84 @@
+package gen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mickamy/ormgen/internal/gen"
+)
+
+func TestRenderSchema(t *testing.T) {
+	t.Parallel()
+
+	infos, err := gen.Parse(testdataPath("user.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	findStruct(t, infos, "User").TableName = "users"
+	findStruct(t, infos, "Post").TableName = "posts"
+
+	src, err := gen.RenderSchema(infos)
+	if err != nil {
+		t.Fatalf("RenderSchema: %v", err)
+	}
+
+	// Decode generically so the test pins the JSON names, not the Go ones.
+	var doc struct {
+		Version int `json:"version"`
+		Structs []struct {
+			Name      string `json:"name"`
+			TableName string `json:"tableName"`
+			Fields    []struct {
+				Name       string `json:"name"`
+				Column     string `json:"column"`
+				GoType     string `json:"goType"`
+				PrimaryKey bool   `json:"primaryKey"`
+				CreatedAt  bool   `json:"createdAt"`
+			} `json:"fields"`
+			Relations []struct {
+				FieldName  string `json:"fieldName"`
+				TargetType string `json:"targetType"`
+				RelType    string `json:"relType"`
+				ForeignKey string `json:"foreignKey"`
+			} `json:"relations"`
+		} `json:"structs"`
+	}
+	if err := json.Unmarshal(src, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, src)
+	}
+
+	if doc.Version != gen.SchemaVersion {
+		t.Errorf("version = %d, want %d", doc.Version, gen.SchemaVersion)
+	}
+	if len(doc.Structs) != 2 {
+		t.Fatalf("len(structs) = %d, want 2", len(doc.Structs))
+	}
+	user := doc.Structs[0]
+	if user.Name != "User" || user.TableName != "users" {
+		t.Errorf("structs[0] = %s/%s, want User/users", user.Name, user.TableName)
+	}
+	if f := user.Fields[0]; f.Name != "ID" || f.Column != "id" || f.GoType != "int" || !f.PrimaryKey {
+		t.Errorf("fields[0] = %+v, want the int primary key id", f)
+	}
+	var sawCreatedAt bool
+	for _, f := range user.Fields {
+		sawCreatedAt = sawCreatedAt || (f.Column == "created_at" && f.CreatedAt)
+	}
+	if !sawCreatedAt {
+		t.Errorf("created_at is not flagged as createdAt: %+v", user.Fields)
+	}
+	if len(user.Relations) != 1 {
+		t.Fatalf("len(relations) = %d, want 1", len(user.Relations))
+	}
+	if r := user.Relations[0]; r.FieldName != "Posts" || r.TargetType != "Post" || r.RelType != "has_many" || r.ForeignKey != "user_id" {
+		t.Errorf("relations[0] = %+v, want Posts has_many Post via user_id", r)
+	}
+}
+
+func TestRenderSchemaEmpty(t *testing.T) {
+	t.Parallel()
+
+	if _, err := gen.RenderSchema(nil); err == nil {
+		t.Error("expected error for no structs")
+	}
+}
@@ -0,0 +1,23 @@
+package testdata
+
+import (
+	"database/sql"
+	"time"
+)
+
+type AccountID = int64
+
+type Handle = string
+
+type Username = Handle
+
+type MaybeName = sql.NullString
+
+type Stamp = time.Time
+
+type Account struct {
+	ID        AccountID `db:"id,primaryKey"`
+	Handle    Username  `db:"handle"`
+	Nickname  MaybeName
+	CreatedAt Stamp
+}
@@ -0,0 +1,19 @@
+package testdata
+
+import "time"
+
+type Invoice struct {
+	Key        int       `gorm:"column:invoice_key;primaryKey"`
+	Total      int       `gorm:"column:total_cents" db:"ignored"`
+	Memo       string    `gorm:"-"`
+	Issued     time.Time `gorm:"autoCreateTime"`
+	Number     string    `gorm:"<-:create"`
+	Balance    int       `gorm:"->"`
+	Customer   *Customer `gorm:"-" assoc:"belongs_to,foreign_key:customer_id"`
+	CustomerID int
+}
+
+type Customer struct {
+	ID   int    `sql:"id,primaryKey"`
+	Name string `sql:"display_name"`
+}
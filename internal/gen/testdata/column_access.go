@@ -0,0 +1,11 @@
+package testdata
+
+import "time"
+
+type Order struct {
+	ID           int       `db:"id,primaryKey"`
+	Number       string    `db:"number,immutable"`        // assigned once, e.g. an external reference
+	Total        int       `db:"total"`                   // written by INSERT and UPDATE
+	TotalWithTax int       `db:"total_with_tax,readonly"` // generated column
+	CreatedAt    time.Time `db:"created_at,readonly"`     // DEFAULT now(), never set by the Clock
+}
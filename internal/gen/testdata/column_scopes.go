@@ -0,0 +1,19 @@
+package testdata
+
+import (
+	"time"
+
+	guuid "github.com/google/uuid"
+)
+
+type Plan string
+
+type Account struct {
+	ID        int64
+	Email     string
+	Age       int
+	Plan      Plan
+	OwnerKey  guuid.UUID `db:"owner_key"`
+	CreatedAt time.Time
+	ClosedAt  *time.Time
+}
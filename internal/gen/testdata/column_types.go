@@ -0,0 +1,37 @@
+package testdata
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+type Labels []string
+
+func (l *Labels) Scan(src any) error {
+	s, _ := src.(string)
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+func (l Labels) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+type Point struct {
+	X, Y float64
+}
+
+func (p *Point) Scan(_ any) error { return nil }
+
+type Plain []string
+
+type Kind string
+
+type Place struct {
+	ID      int    `db:"id,primaryKey"`
+	Labels  Labels `db:"labels"`
+	Extra   Labels `db:"extra"`
+	Origin  Point  `db:"origin"`
+	Aliases Plain  `db:"aliases"`
+	Kind    Kind   `db:"kind"`
+}
@@ -0,0 +1,8 @@
+package columntypes
+
+type Place struct {
+	ID     int    `db:"id,primaryKey"`
+	Labels Labels `db:"labels"`
+	Origin Point  `db:"origin"`
+	Kind   Kind   `db:"kind"`
+}
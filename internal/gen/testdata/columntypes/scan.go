@@ -0,0 +1,9 @@
+package columntypes
+
+import "strings"
+
+func (l *Labels) Scan(src any) error {
+	s, _ := src.(string)
+	*l = strings.Split(s, ",")
+	return nil
+}
@@ -0,0 +1,18 @@
+package columntypes
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+type Labels []string
+
+func (l Labels) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+type Point struct {
+	X, Y float64
+}
+
+type Kind string
@@ -0,0 +1,19 @@
+package testdata
+
+// Account is a billing account.
+type Account struct {
+	ID int // surrogate key
+	// Owner's display name.
+	Name string
+	// Balance in cents.
+	Balance int64 // never negative
+	Plan    string
+}
+
+type (
+	// Invoice is issued monthly.
+	Invoice struct {
+		ID     int
+		Amount int64
+	}
+)
@@ -0,0 +1,7 @@
+package testdata
+
+type Membership struct {
+	TenantID int    `db:"tenant_id,primaryKey"`
+	UserID   int    `db:"user_id,primaryKey"`
+	Role     string `db:"role"`
+}
@@ -0,0 +1,34 @@
+package testdata
+
+import "time"
+
+// Base holds the columns every model shares.
+type Base struct {
+	ID        int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Audited adds audit columns on top of Base.
+type Audited struct {
+	Base
+	UpdatedBy string `db:"updated_by"`
+}
+
+type Note struct {
+	Base
+	Title string `db:"title"`
+	Body  string `db:"body"`
+}
+
+type Revision struct {
+	Audited
+	Note      string    `db:"note"`
+	CreatedAt time.Time `db:"recorded_at"` // shadows Base.CreatedAt
+}
+
+type Draft struct {
+	Base  `db:"-"`
+	Key   string `db:"key,primaryKey"`
+	Title string `db:"title"`
+}
@@ -0,0 +1,18 @@
+package testdata
+
+type MemberStatus string
+
+type MemberRole string
+
+type Member struct {
+	ID     int64
+	Status MemberStatus `db:"status,enum:active|inactive|banned"`
+	Role   MemberRole   `db:"role"`
+}
+
+// Invite shares MemberStatus, and therefore its values and parser, with Member.
+type Invite struct {
+	ID     int64
+	Status MemberStatus `db:"status,enum:active|inactive|banned"`
+	Email  string
+}
@@ -0,0 +1,8 @@
+package testdata
+
+type Level int
+
+type Badge struct {
+	ID    int64
+	Level Level `db:"level,enum:1|2|3"`
+}
@@ -0,0 +1,19 @@
+package testdata
+
+type Status string
+
+type Priority int
+
+type Label = string
+
+type Tags []string
+
+type Ticket struct {
+	ID       int
+	Status   Status
+	Priority Priority `db:"prio"`
+	Owner    *Status
+	Label    Label
+	Tags     Tags
+	Title    string
+}
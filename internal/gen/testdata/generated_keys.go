@@ -0,0 +1,15 @@
+package testdata
+
+type UUID [16]byte
+
+// Session's UUID primary key is generated by the client.
+type Session struct {
+	ID     UUID `db:"id,primaryKey"`
+	UserID int
+}
+
+// APIKey's string primary key is generated because of its tag.
+type APIKey struct {
+	Key   string `db:"key,primaryKey,generated"`
+	Label string
+}
@@ -0,0 +1,22 @@
+package testdata
+
+// Owner.Pets and Pet.Owner disagree on the key type: Pet.OwnerID is int32
+// while Owner.ID is int64.
+type Owner struct {
+	ID   int64
+	Name string
+	Pets []Pet `rel:"has_many,foreign_key:owner_id"`
+}
+
+type Pet struct {
+	ID      int64
+	OwnerID int32
+	Owner   *Owner `rel:"belongs_to,foreign_key:owner_id"`
+}
+
+// Collar's nullable FK matches Pet.ID once the pointer is stripped.
+type Collar struct {
+	ID    int
+	PetID *int64
+	Pet   *Pet `rel:"belongs_to,foreign_key:pet_id"`
+}
@@ -0,0 +1,7 @@
+package peers
+
+type Article struct {
+	ID       int
+	AuthorID int
+	Author   *Author `rel:"belongs_to,foreign_key:author_id"`
+}
@@ -0,0 +1,6 @@
+package peers
+
+type Author struct {
+	ID   int
+	Name string
+}
@@ -0,0 +1,6 @@
+package peers
+
+type Fixture struct {
+	ID    int
+	Label string
+}
@@ -0,0 +1,8 @@
+//go:build integration
+
+package peers
+
+type Tagged struct {
+	ID   int
+	Name string
+}
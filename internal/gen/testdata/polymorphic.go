@@ -0,0 +1,21 @@
+package testdata
+
+// Comment belongs to either a Post or a Photo, named by CommentableType.
+type Comment struct {
+	ID              int
+	CommentableType string
+	CommentableID   int
+	Body            string
+	Post            *Post  `rel:"polymorphic,type_column:commentable_type,id_column:commentable_id"`
+	Photo           *Photo `rel:"polymorphic,type_column:commentable_type,id_column:commentable_id,type_value:photo"`
+}
+
+type Post struct {
+	ID    int
+	Title string
+}
+
+type Photo struct {
+	ID  int
+	URL string
+}
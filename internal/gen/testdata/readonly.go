@@ -0,0 +1,19 @@
+package testdata
+
+import "time"
+
+// Tag is a single-column lookup table.
+//
+//ormgen:readonly
+type Tag struct {
+	Name string `db:"name"`
+}
+
+// ActiveMember is backed by a view.
+//
+//ormgen:readonly
+type ActiveMember struct {
+	ID        int    `db:"id,primaryKey"`
+	Name      string `db:"name"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,29 @@
+package testdata
+
+type Author struct {
+	ID       int
+	Name     string
+	Books    []Book  `rel:"has_many,foreign_key:author_id,preload:false"`
+	Profile  *Bio    `rel:"has_one,foreign_key:author_id,join:false"`
+	Archived []Book  `rel:"has_many,foreign_key:author_id,preload:false,join:false"`
+	Awards   []Award `rel:"many_to_many,join_table:author_awards,foreign_key:author_id,references:award_id,preload:false"`
+	Mentor   *Author `rel:"belongs_to,foreign_key:mentor_id,join:false,preload:false"`
+	MentorID *int
+}
+
+type Book struct {
+	ID       int
+	AuthorID int
+	Title    string
+}
+
+type Bio struct {
+	ID       int
+	AuthorID int
+	Text     string
+}
+
+type Award struct {
+	ID   int
+	Name string
+}
@@ -34,8 +34,8 @@ type Comment struct {
 	ID       int
 	AuthorID *string `db:"author_id"`
 	Body     string
-	// belongs_to with nullable FK
-	Author *Author `rel:"belongs_to,foreign_key:author_id"`
+	// belongs_to with nullable FK to a string primary key
+	Author *Pseudonym `rel:"belongs_to,foreign_key:author_id"`
 }
 
 // QRImage tests acronym field names resolved by struct lookup, not SnakeToCamel.
@@ -46,3 +46,9 @@ type QRImage struct {
 	// belongs_to: FK field name should be "AuthorID" (not "AuthorId")
 	Author Author `rel:"belongs_to,foreign_key:author_id"`
 }
+
+// Pseudonym has a string primary key, referenced by Comment's nullable FK.
+type Pseudonym struct {
+	ID   string
+	Name string
+}
@@ -0,0 +1,28 @@
+package testdata
+
+import (
+	"time"
+
+	guuid "github.com/google/uuid"
+)
+
+// Customer gets a repository keyed by its int64 primary key.
+type Customer struct {
+	ID        int64     `db:"id,primaryKey"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Device is keyed by a UUID from an aliased import.
+type Device struct {
+	ID   guuid.UUID `db:"id,primaryKey"`
+	Name string     `db:"name"`
+}
+
+// CustomerTotal is a view; read-only models get no repository.
+//
+//ormgen:readonly
+type CustomerTotal struct {
+	CustomerID int64 `db:"customer_id,primaryKey"`
+	Total      int64 `db:"total"`
+}
@@ -0,0 +1,10 @@
+package testdata
+
+// Category forms a tree through parent_id.
+type Category struct {
+	ID       int        `db:"id,primaryKey"`
+	ParentID *int       `db:"parent_id"`
+	Name     string     `db:"name"`
+	Parent   *Category  `db:"-" rel:"belongs_to,foreign_key:parent_id"`
+	Children []Category `db:"-" rel:"has_many,foreign_key:parent_id"`
+}
@@ -0,0 +1,16 @@
+package testdata
+
+import "time"
+
+type Event struct {
+	ID        int       `db:"id,primaryKey"`
+	Name      string    `db:"name,server"` // ignored: not a timestamp
+	CreatedAt time.Time `db:"created_at,server"`
+	UpdatedAt time.Time // set by the Clock
+}
+
+type Ledger struct {
+	ID         int        `db:"id,primaryKey"`
+	RecordedAt time.Time  `db:"recorded_at,createdAt,server"`
+	SettledAt  *time.Time `db:"settled_at,updatedAt,server"`
+}
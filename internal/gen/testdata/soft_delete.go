@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Account struct {
+	ID        int        `db:"id,primaryKey"`
+	Name      string     `db:"name"`
+	DeletedAt *time.Time // convention
+}
+
+type Archive struct {
+	ID        int          `db:"id,primaryKey"`
+	RemovedAt sql.NullTime `db:"removed_at,deletedAt"`
+}
+
+type Audit struct {
+	ID        int       `db:"id,primaryKey"`
+	DeletedAt time.Time // not nullable, so not a soft-delete column
+}
@@ -0,0 +1,21 @@
+package testdata
+
+// Author reaches the comments on their posts through Posts.
+type Author struct {
+	ID       int
+	Name     string
+	Posts    []Post    `rel:"has_many,foreign_key:author_id"`
+	Comments []Comment `rel:"has_many,through:Posts,foreign_key:post_id"`
+}
+
+type Post struct {
+	ID       int64
+	AuthorID int
+	Title    string
+}
+
+type Comment struct {
+	ID     int
+	PostID *int64
+	Body   string
+}
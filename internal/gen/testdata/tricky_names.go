@@ -0,0 +1,25 @@
+package testdata
+
+type APIKey struct {
+	ID       int
+	APIKeyID string
+	Secret   string
+}
+
+type OAuthToken struct {
+	ID          int
+	OAuthUserID int
+	AccessToken string
+}
+
+type URLMapping struct {
+	ID        int
+	SourceURL string
+	TargetURL string
+}
+
+type IPv4Range struct {
+	ID       int
+	IPv4From string
+	IPv4To   string
+}
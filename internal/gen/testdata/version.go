@@ -0,0 +1,7 @@
+package testdata
+
+type Document struct {
+	ID      int    `db:"id,primaryKey"`
+	Title   string `db:"title"`
+	Version int64  `db:"version,version"`
+}
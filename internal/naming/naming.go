@@ -1,8 +1,12 @@
 package naming
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/jinzhu/inflection"
 )
 
 // commonInitialisms maps lowercase words to their Go-idiomatic CamelCase form.
@@ -10,12 +14,12 @@ var commonInitialisms = map[string]string{
 	"id": "ID", "url": "URL", "api": "API", "http": "HTTP",
 	"json": "JSON", "xml": "XML", "sql": "SQL", "html": "HTML",
 	"ip": "IP", "tcp": "TCP", "udp": "UDP", "uuid": "UUID",
-	"oauth": "OAuth",
+	"oauth": "OAuth", "ipv4": "IPv4", "ipv6": "IPv6",
 }
 
 // SnakeToCamel converts a snake_case string to CamelCase.
-// Common initialisms use their Go-idiomatic form:
-// "user_id" → "UserID", "oauth_token" → "OAuthToken".
+// Common initialisms use their Go-idiomatic form, including their plurals:
+// "user_id" → "UserID", "oauth_token" → "OAuthToken", "urls" → "URLs".
 func SnakeToCamel(s string) string {
 	parts := strings.Split(s, "_")
 	var b strings.Builder
@@ -25,6 +29,8 @@ func SnakeToCamel(s string) string {
 		}
 		if rep, ok := commonInitialisms[p]; ok {
 			b.WriteString(rep)
+		} else if rep, ok := commonInitialisms[strings.TrimSuffix(p, "s")]; ok && strings.HasSuffix(p, "s") {
+			b.WriteString(rep + "s")
 		} else {
 			b.WriteString(strings.ToUpper(p[:1]) + p[1:])
 		}
@@ -65,3 +71,142 @@ func CamelToSnake(s string) string {
 	}
 	return b.String()
 }
+
+// TableName converts a Go type name to a snake_case plural table name.
+// e.g. "User" → "users", "UserProfile" → "user_profiles", "APIKey" → "api_keys"
+func TableName(typeName string) string {
+	return Plurals(nil).TableName(typeName)
+}
+
+// Plurals maps snake_case singulars to their plural form. It overrides
+// inflection for words the default rules get wrong (e.g. "datum" → "data")
+// or that should stay as-is (e.g. "media" → "media").
+type Plurals map[string]string
+
+// TableName is like the package-level TableName but consults p first.
+func (p Plurals) TableName(typeName string) string {
+	return p.Plural(CamelToSnake(typeName))
+}
+
+// Plural returns the plural form of a snake_case name.
+// Precedence: an override for the whole name, then an override for its
+// last word ("user_media" matches "media"), then inflection.Plural.
+func (p Plurals) Plural(snake string) string {
+	if plural, ok := p[snake]; ok {
+		return plural
+	}
+	if i := strings.LastIndex(snake, "_"); i >= 0 {
+		if plural, ok := p[snake[i+1:]]; ok {
+			return snake[:i+1] + plural
+		}
+	}
+	return inflection.Plural(snake)
+}
+
+// LowerFirstWord lowercases the first word of a CamelCase identifier,
+// keeping leading initialisms together so the result stays idiomatic:
+// "UserPosts" → "userPosts", "APIKeys" → "apiKeys", "OAuthTokens" → "oauthTokens".
+func LowerFirstWord(s string) string {
+	snake := CamelToSnake(s)
+	first, _, _ := strings.Cut(snake, "_")
+	n := len([]rune(first))
+	if n == 0 {
+		return s
+	}
+	runes := []rune(s)
+	return strings.ToLower(string(runes[:n])) + string(runes[n:])
+}
+
+// ParsePluralsYAML reads overrides written as a flat YAML mapping, one
+// "singular: plural" pair per line:
+//
+//	# irregular plurals
+//	datum: data
+//	media: "media"
+//
+// Comments, blank lines, a leading "---" and quoted scalars are accepted.
+// Nested mappings, lists, anchors and block scalars are reported as
+// errors: the file is only ever a flat map, which does not warrant a YAML
+// dependency.
+func ParsePluralsYAML(data []byte) (Plurals, error) {
+	plurals := make(Plurals)
+	for i, line := range strings.Split(string(data), "\n") {
+		n := i + 1
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || (trimmed == "---" && len(plurals) == 0) {
+			continue
+		}
+		if trimmed != line {
+			return nil, fmt.Errorf("line %d: nested values are not supported", n)
+		}
+		key, rest, err := yamlScalar(line, true)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		after, ok := strings.CutPrefix(rest, ":")
+		if !ok || after != "" && after[0] != ' ' && after[0] != '\t' {
+			return nil, fmt.Errorf("line %d: want \"singular: plural\"", n)
+		}
+		value, rest, err := yamlScalar(strings.TrimLeft(after, " \t"), false)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if rest = strings.TrimLeft(rest, " \t"); rest != "" && rest[0] != '#' {
+			return nil, fmt.Errorf("line %d: unexpected %q after the value", n, rest)
+		}
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("line %d: want \"singular: plural\"", n)
+		}
+		plurals[key] = value
+	}
+	return plurals, nil
+}
+
+// yamlScalar reads the quoted or plain scalar at the start of s and returns
+// it with the rest of s. A plain key ends before ": "; a plain value ends
+// before " #".
+func yamlScalar(s string, isKey bool) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				v, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid quoted string %s", s[:i+1])
+				}
+				return v, s[i+1:], nil
+			}
+		}
+		return "", "", fmt.Errorf("unterminated quoted string %s", s)
+	case strings.HasPrefix(s, "'"):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), s[i+1:], nil
+		}
+		return "", "", fmt.Errorf("unterminated quoted string %s", s)
+	case s != "" && strings.ContainsRune("-[]{}&*!|>%@`", rune(s[0])):
+		return "", "", fmt.Errorf("unsupported YAML syntax %q", s)
+	}
+	end := len(s)
+	if isKey {
+		if i := strings.Index(s, ":"); i >= 0 {
+			end = i
+		}
+	} else if i := strings.Index(s, " #"); i >= 0 {
+		end = i
+	}
+	return strings.TrimRight(s[:end], " \t"), s[end:], nil
+}
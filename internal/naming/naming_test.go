@@ -1,6 +1,7 @@
 package naming_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/mickamy/ormgen/internal/naming"
@@ -20,6 +21,11 @@ func TestSnakeToCamel(t *testing.T) {
 		{"http_server", "HTTPServer"},
 		{"oauth_token", "OAuthToken"},
 		{"user_oauth_accounts", "UserOAuthAccounts"},
+		{"api_keys", "APIKeys"},
+		{"url_mappings", "URLMappings"},
+		{"ipv4_ranges", "IPv4Ranges"},
+		{"user_ids", "UserIDs"},
+		{"status", "Status"},
 		{"", ""},
 	}
 
@@ -51,9 +57,15 @@ func TestCamelToSnake(t *testing.T) {
 		{"UserOAuthAccount", "user_oauth_account"},
 		{"userProfile", "user_profile"},
 		{"S3Object", "s3_object"},
-		{"QRImageID", "qr_image_id"},  // digit boundary + acronym
-		{"QrImageId", "qr_image_id"},  // non-acronym variant also works
+		{"QRImageID", "qr_image_id"}, // digit boundary + acronym
+		{"QrImageId", "qr_image_id"}, // non-acronym variant also works
 		{"EC2Instance", "ec2_instance"},
+		{"APIKey", "api_key"},
+		{"OAuthToken", "oauth_token"},
+		{"URLMapping", "url_mapping"},
+		{"IPv4Range", "ipv4_range"},
+		{"IPv6Addr", "ipv6_addr"},
+		{"api_key", "api_key"}, // already snake_case
 		{"A", "a"},
 		{"", ""},
 	}
@@ -69,3 +81,144 @@ func TestCamelToSnake(t *testing.T) {
 		})
 	}
 }
+
+func TestTableName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"User", "users"},
+		{"UserProfile", "user_profiles"},
+		{"Category", "categories"},
+		{"APIKey", "api_keys"},
+		{"OAuthToken", "oauth_tokens"},
+		{"URLMapping", "url_mappings"},
+		{"IPv4Range", "ipv4_ranges"},
+		{"QRImage", "qr_images"},
+		{"api_key", "api_keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got := naming.TableName(tt.input)
+			if got != tt.want {
+				t.Errorf("TableName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowerFirstWord(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"UsersColumns", "usersColumns"},
+		{"scanUser", "scanUser"},
+		{"setUserPK", "setUserPK"},
+		{"APIKeysColumns", "apiKeysColumns"},
+		{"OAuthTokensColumns", "oauthTokensColumns"},
+		{"URLMappingColumnValuePairs", "urlMappingColumnValuePairs"},
+		{"IPv4RangesColumns", "ipv4RangesColumns"},
+		{"QRImageColumnValuePairs", "qrImageColumnValuePairs"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got := naming.LowerFirstWord(tt.input)
+			if got != tt.want {
+				t.Errorf("LowerFirstWord(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluralsTableName(t *testing.T) {
+	t.Parallel()
+
+	plurals := naming.Plurals{
+		"datum":     "data",
+		"media":     "media",
+		"criterion": "criteria",
+		"user_info": "user_infos",
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Datum", "data"},
+		{"Media", "media"},
+		{"UserMedia", "user_media"},            // last-word override
+		{"SearchCriterion", "search_criteria"}, // last-word override
+		{"UserInfo", "user_infos"},             // whole-name override
+		{"User", "users"},                      // falls back to inflection
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got := plurals.TableName(tt.input)
+			if got != tt.want {
+				t.Errorf("TableName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePluralsYAML(t *testing.T) {
+	t.Parallel()
+
+	src := `---
+# irregular plurals
+datum: data
+media: "media"   # kept as is
+'criterion': 'criteria'
+user_info:	user_infos
+`
+	got, err := naming.ParsePluralsYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("ParsePluralsYAML: %v", err)
+	}
+	want := naming.Plurals{
+		"datum":     "data",
+		"media":     "media",
+		"criterion": "criteria",
+		"user_info": "user_infos",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePluralsYAML = %v, want %v", got, want)
+	}
+}
+
+func TestParsePluralsYAMLErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"nested", "datum:\n  plural: data\n"},
+		{"list", "- datum\n"},
+		{"flow mapping", "{datum: data}\n"},
+		{"missing value", "datum:\n"},
+		{"missing colon", "datum data\n"},
+		{"unterminated quote", "datum: \"data\n"},
+		{"anchor", "datum: &d data\n"},
+	}
+	for _, tt := range tests {
+		if _, err := naming.ParsePluralsYAML([]byte(tt.src)); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
+	}
+}
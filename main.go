@@ -9,10 +9,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/jinzhu/inflection"
-
 	"github.com/mickamy/ormgen/internal/gen"
 	"github.com/mickamy/ormgen/internal/naming"
 )
@@ -22,6 +21,19 @@ var version = "dev"
 func main() {
 	source := flag.String("source", "", "source file path (required)")
 	destination := flag.String("destination", "", "output directory (default: same as source)")
+	pluralsPath := flag.String("plurals", "", "JSON or YAML (.yaml/.yml) file of singular→plural table name overrides")
+	includeTests := flag.Bool("include-tests", false, "include _test.go files as peers for relation lookups")
+	tags := flag.String("tags", "", "comma-separated build tags; when set, peers with unsatisfied //go:build constraints are skipped")
+	tagKey := flag.String("tag", "db", "struct tag key for column options (gorm reads GORM's column:...;primaryKey syntax)")
+	relTagKey := flag.String("rel-tag", "rel", "struct tag key for relation options")
+	diff := flag.Bool("diff", false, "also generate a <Type>Diff helper comparing two instances column by column")
+	sortColumns := flag.Bool("sort-columns", false, "also generate a typed <Type>SortColumn with Asc/Desc scopes for safe user-chosen ordering")
+	columnScopes := flag.Bool("column-scopes", false, "also generate a typed <Factory>By<Field> equality scope per column, e.g. UsersByEmail")
+	repo := flag.Bool("repo", false, "also generate a <Type>Repository interface with Create/FindByID/FindAll/Update/Delete and an implementation wrapping the query factory")
+	mock := flag.Bool("mock", false, "with -repo, also generate a Mock<Type>Repository with settable Func fields and recorded calls for tests")
+	helperPrefix := flag.String("helper-prefix", "", "prefix for unexported generated helpers, e.g. model → modelScanUser, to keep names unique when several packages generate into one")
+	emitSchema := flag.String("emit-schema", "", "also write the parsed structs, fields and relations as JSON to the given file")
+	genDDL := flag.String("gen-ddl", "", "emit CREATE TABLE DDL for the given dialect (mysql or postgres) instead of Go code")
 	showVersion := flag.Bool("version", false, "print version and exit")
 	flag.Parse()
 
@@ -34,7 +46,12 @@ func main() {
 		log.Fatal("-source flag is required")
 	}
 
-	infos, err := gen.Parse(*source)
+	if *mock && !*repo {
+		log.Fatal("-mock requires -repo")
+	}
+
+	parseOpt := gen.ParseOption{Tag: *tagKey, RelTag: *relTagKey}
+	infos, err := gen.ParseWithOption(*source, parseOpt)
 	if err != nil {
 		log.Fatalf("parse: %v", err)
 	}
@@ -43,20 +60,68 @@ func main() {
 		log.Fatalf("no structs with db tags found in %s", *source)
 	}
 
+	plurals, err := loadPlurals(*pluralsPath)
+	if err != nil {
+		log.Fatalf("load plurals: %v", err)
+	}
+
 	for _, info := range infos {
-		info.TableName = inferTableName(info.Name)
+		info.TableName = inferTableName(info.Name, plurals)
 	}
 
 	// Parse peer .go files in the same directory to provide struct metadata
 	// for join scan field lookups (e.g. belongs_to target in another file).
-	peerInfos := parsePeerFiles(filepath.Dir(*source), filepath.Base(*source))
+	peerOpt := gen.PeerOption{ParseOption: parseOpt, IncludeTests: *includeTests}
+	if *tags != "" {
+		peerOpt.BuildTags = strings.Split(*tags, ",")
+	}
+	peerInfos := gen.ParsePeers(filepath.Dir(*source), filepath.Base(*source), peerOpt)
 	for _, info := range peerInfos {
-		info.TableName = inferTableName(info.Name)
+		info.TableName = inferTableName(info.Name, plurals)
+	}
+
+	// Embedded structs declared in peer files, e.g. a shared Base.
+	gen.FlattenEmbedded(peerInfos, nil)
+	gen.FlattenEmbedded(infos, peerInfos)
+	// Custom column types and their Scan/Value methods may be declared in
+	// peer files.
+	gen.ParseColumnTypes(*source, peerOpt).Resolve(infos)
+	infos = slices.DeleteFunc(infos, func(info *gen.StructInfo) bool { return len(info.Fields) == 0 })
+	if len(infos) == 0 {
+		log.Fatalf("no structs with db tags found in %s", *source)
 	}
 
 	var opt gen.RenderOption
 	opt.PeerInfos = peerInfos
+	opt.Plurals = plurals
+	opt.Diff = *diff
+	opt.SortColumns = *sortColumns
+	opt.ColumnScopes = *columnScopes
+	opt.Repo = *repo
+	opt.Mock = *mock
+	opt.HelperPrefix = *helperPrefix
 	outDir := filepath.Dir(*source)
+	base := strings.TrimSuffix(filepath.Base(*source), ".go")
+
+	if *emitSchema != "" {
+		schema, err := gen.RenderSchema(infos)
+		if err != nil {
+			log.Fatalf("render schema: %v", err)
+		}
+		writeOutput(*emitSchema, schema)
+	}
+
+	if *genDDL != "" {
+		if *destination != "" {
+			outDir = *destination
+		}
+		ddl, err := gen.RenderDDL(infos, *genDDL, opt)
+		if err != nil {
+			log.Fatalf("render ddl: %v", err)
+		}
+		writeOutput(filepath.Join(outDir, strings.TrimSuffix(base, "_test")+"_"+*genDDL+"_gen.sql"), ddl)
+		return
+	}
 
 	if *destination != "" {
 		outDir = *destination
@@ -73,15 +138,22 @@ func main() {
 		log.Fatalf("render: %v", err)
 	}
 
-	base := strings.TrimSuffix(filepath.Base(*source), ".go")
+	// Models defined in a _test.go file are only visible to tests, so the
+	// generated code must be a test file too.
 	outFile := base + "_query_gen.go"
-	outPath := filepath.Join(outDir, outFile)
+	if trimmed, ok := strings.CutSuffix(base, "_test"); ok {
+		outFile = trimmed + "_query_gen_test.go"
+	}
+	writeOutput(filepath.Join(outDir, outFile), src)
+}
 
+// writeOutput writes generated content to outPath, creating parent directories.
+func writeOutput(outPath string, content []byte) {
 	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
 		log.Fatalf("mkdir %s: %v", filepath.Dir(outPath), err)
 	}
 
-	if err := os.WriteFile(outPath, src, 0o644); err != nil { //nolint:gosec // generated code should be world-readable
+	if err := os.WriteFile(outPath, content, 0o644); err != nil { //nolint:gosec // generated code should be world-readable
 		log.Fatalf("write %s: %v", outPath, err)
 	}
 
@@ -106,34 +178,34 @@ func resolveImportPath(dir string) (string, error) {
 	return pkg.ImportPath, nil
 }
 
-// parsePeerFiles parses all .go files in dir except excludeBase and returns
-// their StructInfos. Errors are silently ignored (peers are best-effort).
-func parsePeerFiles(dir, excludeBase string) []*gen.StructInfo {
-	entries, err := os.ReadDir(dir)
+// inferTableName converts a CamelCase type name to a snake_case plural table name.
+// e.g. "User" -> "users", "UserProfile" -> "user_profiles"
+// Overrides in plurals take precedence over the default inflection rules.
+func inferTableName(typeName string, plurals naming.Plurals) string {
+	return plurals.TableName(typeName)
+}
+
+// loadPlurals reads snake_case singular→plural overrides, e.g.
+// {"datum": "data", "media": "media"}. Files ending in .yaml or .yml are
+// parsed as a flat YAML mapping; anything else as a JSON object. An empty
+// path returns nil.
+func loadPlurals(path string) (naming.Plurals, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("read %s: %w", path, err)
 	}
-	var peers []*gen.StructInfo
-	for _, e := range entries {
-		name := e.Name()
-		if e.IsDir() || !strings.HasSuffix(name, ".go") || name == excludeBase {
-			continue
-		}
-		if strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, "_gen.go") {
-			continue
-		}
-		peerInfos, err := gen.Parse(filepath.Join(dir, name))
-		if err != nil {
-			continue
-		}
-		peers = append(peers, peerInfos...)
+	var plurals naming.Plurals
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		plurals, err = naming.ParsePluralsYAML(b)
+	default:
+		err = json.Unmarshal(b, &plurals)
 	}
-	return peers
-}
-
-// inferTableName converts a CamelCase type name to a snake_case plural table name.
-// e.g. "User" -> "users", "UserProfile" -> "user_profiles"
-func inferTableName(typeName string) string {
-	snake := naming.CamelToSnake(typeName)
-	return inflection.Plural(snake)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return plurals, nil
 }
@@ -3,6 +3,8 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"strconv"
+	"time"
 )
 
 // Querier is the common interface for DB and Tx.
@@ -11,6 +13,7 @@ type Querier interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	dialect() Dialect
+	preloadStrategy() PreloadStrategy
 }
 
 // Logger is the interface for query logging.
@@ -18,11 +21,37 @@ type Logger interface {
 	Log(ctx context.Context, query string, args ...any)
 }
 
+// QueryInfo describes a statement after it ran, for a QueryObserver.
+type QueryInfo struct {
+	SQL  string
+	Args []any
+
+	// Duration is the time until the driver returned. For a query that is
+	// the first result, not the time spent reading the rows.
+	Duration time.Duration
+
+	// RowsAffected is the count reported for a successful exec, or -1 for
+	// queries, failures and drivers that do not report it.
+	RowsAffected int64
+
+	Err error
+}
+
+// QueryObserver receives every statement run through a DB or Tx once the
+// driver has returned, e.g. to record latency and failures. Unlike Logger,
+// it is called after the statement, so it can see its outcome.
+type QueryObserver interface {
+	ObserveQuery(ctx context.Context, info QueryInfo)
+}
+
 // DB wraps *sql.DB with a Dialect and satisfies Querier.
 type DB struct {
-	raw    *sql.DB
-	d      Dialect
-	logger Logger
+	raw      *sql.DB
+	d        Dialect
+	logger   Logger
+	observer QueryObserver
+	preloads PreloadStrategy
+	stmts    *stmtCache
 }
 
 // New wraps a *sql.DB with the given Dialect.
@@ -33,37 +62,119 @@ func New(db *sql.DB, d Dialect) *DB {
 // Debug returns a new *DB that logs every query using the given Logger.
 // The original DB is not modified.
 func (db *DB) Debug(l Logger) *DB {
-	return &DB{raw: db.raw, d: db.d, logger: l}
+	db2 := *db
+	db2.logger = l
+	return &db2
+}
+
+// WithObserver returns a new *DB that reports every statement, with its
+// duration and error, to o. It can be combined with Debug.
+// The original DB is not modified.
+func (db *DB) WithObserver(o QueryObserver) *DB {
+	db2 := *db
+	db2.observer = o
+	return &db2
+}
+
+// WithPreloadStrategy returns a new *DB whose queries load Preload
+// relations with the given strategy, unless a query overrides it.
+// The original DB is not modified.
+func (db *DB) WithPreloadStrategy(s PreloadStrategy) *DB {
+	db2 := *db
+	db2.preloads = s
+	return &db2
+}
+
+// WithStatementCache returns a new *DB that prepares each distinct SQL
+// statement once and reuses it, keeping up to size statements and closing
+// the least recently used beyond that. A statement whose execution fails is
+// dropped and prepared again on next use. Transactions begun from the DB do
+// not use the cache. A size of 0 or less disables caching.
+// The original DB is not modified.
+func (db *DB) WithStatementCache(size int) *DB {
+	db2 := *db
+	db2.stmts = nil
+	if size > 0 {
+		db2.stmts = newStmtCache(size)
+	}
+	return &db2
 }
 
 func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	if db.logger != nil {
 		db.logger.Log(ctx, query, args...)
 	}
-	return db.raw.QueryContext(ctx, query, args...) //nolint:wrapcheck // thin wrapper
+	start := time.Now()
+	var rows *sql.Rows
+	var err error
+	if db.stmts != nil {
+		var cs *cachedStmt
+		if cs, err = db.stmts.acquire(ctx, db.raw, query); err == nil {
+			rows, err = cs.stmt.QueryContext(ctx, args...)
+			db.stmts.release(cs, err)
+		}
+	} else {
+		rows, err = db.raw.QueryContext(ctx, query, args...)
+	}
+	observeQuery(ctx, db.observer, query, args, start, nil, err)
+	return rows, err //nolint:wrapcheck // thin wrapper
 }
 
 func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	if db.logger != nil {
 		db.logger.Log(ctx, query, args...)
 	}
-	return db.raw.ExecContext(ctx, query, args...) //nolint:wrapcheck // thin wrapper
+	start := time.Now()
+	var res sql.Result
+	var err error
+	if db.stmts != nil {
+		var cs *cachedStmt
+		if cs, err = db.stmts.acquire(ctx, db.raw, query); err == nil {
+			res, err = cs.stmt.ExecContext(ctx, args...)
+			db.stmts.release(cs, err)
+		}
+	} else {
+		res, err = db.raw.ExecContext(ctx, query, args...)
+	}
+	observeQuery(ctx, db.observer, query, args, start, res, err)
+	return res, err //nolint:wrapcheck // thin wrapper
 }
 
 // Begin starts a transaction.
 func (db *DB) Begin(ctx context.Context) (*Tx, error) {
-	tx, err := db.raw.BeginTx(ctx, nil)
+	return db.BeginTx(ctx, nil)
+}
+
+// BeginTx starts a transaction with the given options (isolation level,
+// read-only). A nil opts uses the driver defaults.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.raw.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err //nolint:wrapcheck // thin wrapper
 	}
-	return &Tx{raw: tx, d: db.d, logger: db.logger}, nil
+	return &Tx{
+		raw: tx, d: db.d, logger: db.logger, observer: db.observer, preloads: db.preloads,
+		readOnly: opts != nil && opts.ReadOnly,
+	}, nil
 }
 
 // Transaction executes fn within a transaction.
 // If fn returns nil the transaction is committed.
 // If fn returns an error or panics the transaction is rolled back.
-func (db *DB) Transaction(ctx context.Context, fn func(tx *Tx) error) (err error) {
-	tx, err := db.Begin(ctx)
+func (db *DB) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	return db.transaction(ctx, nil, fn)
+}
+
+// ReadOnlyTransaction is like Transaction but starts a READ ONLY
+// transaction, e.g. for reporting against a replica. Write methods on
+// queries bound to the transaction fail with ErrReadOnly before reaching
+// the database.
+func (db *DB) ReadOnlyTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	return db.transaction(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (db *DB) transaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -88,25 +199,38 @@ func (db *DB) Close() error { return db.raw.Close() } //nolint:wrapcheck // thin
 
 func (db *DB) dialect() Dialect { return db.d }
 
+func (db *DB) preloadStrategy() PreloadStrategy { return db.preloads }
+
 // Tx wraps *sql.Tx with a Dialect and satisfies Querier.
 type Tx struct {
-	raw    *sql.Tx
-	d      Dialect
-	logger Logger
+	raw      *sql.Tx
+	d        Dialect
+	logger   Logger
+	observer QueryObserver
+	preloads PreloadStrategy
+	readOnly bool
+	// savepoints numbers the savepoints of nested Transaction calls.
+	savepoints int
 }
 
 func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	if tx.logger != nil {
 		tx.logger.Log(ctx, query, args...)
 	}
-	return tx.raw.QueryContext(ctx, query, args...) //nolint:wrapcheck // thin wrapper
+	start := time.Now()
+	rows, err := tx.raw.QueryContext(ctx, query, args...)
+	observeQuery(ctx, tx.observer, query, args, start, nil, err)
+	return rows, err //nolint:wrapcheck // thin wrapper
 }
 
 func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	if tx.logger != nil {
 		tx.logger.Log(ctx, query, args...)
 	}
-	return tx.raw.ExecContext(ctx, query, args...) //nolint:wrapcheck // thin wrapper
+	start := time.Now()
+	res, err := tx.raw.ExecContext(ctx, query, args...)
+	observeQuery(ctx, tx.observer, query, args, start, res, err)
+	return res, err //nolint:wrapcheck // thin wrapper
 }
 
 // Commit commits the transaction.
@@ -115,4 +239,89 @@ func (tx *Tx) Commit() error { return tx.raw.Commit() } //nolint:wrapcheck // th
 // Rollback rolls back the transaction.
 func (tx *Tx) Rollback() error { return tx.raw.Rollback() } //nolint:wrapcheck // thin wrapper
 
+// Savepoint marks a point in the transaction that RollbackTo can return
+// to without abandoning the whole transaction:
+//
+//	_ = tx.Savepoint(ctx, "import_row")
+//	if err := importRow(ctx, tx, row); err != nil {
+//		_ = tx.RollbackTo(ctx, "import_row") // keep the rows imported so far
+//	}
+//	_ = tx.ReleaseSavepoint(ctx, "import_row")
+func (tx *Tx) Savepoint(ctx context.Context, name string) error {
+	stmt := "SAVEPOINT "
+	if _, ok := tx.d.(sqlServerDialect); ok {
+		stmt = "SAVE TRANSACTION "
+	}
+	_, err := tx.ExecContext(ctx, stmt+tx.d.QuoteIdent(name))
+	return err
+}
+
+// RollbackTo undoes everything the transaction did since the named
+// savepoint. The savepoint stays, so it can be rolled back to again.
+func (tx *Tx) RollbackTo(ctx context.Context, name string) error {
+	stmt := "ROLLBACK TO SAVEPOINT "
+	if _, ok := tx.d.(sqlServerDialect); ok {
+		stmt = "ROLLBACK TRANSACTION "
+	}
+	_, err := tx.ExecContext(ctx, stmt+tx.d.QuoteIdent(name))
+	return err
+}
+
+// ReleaseSavepoint forgets the named savepoint, keeping the changes made
+// since. SQL Server has no RELEASE; its savepoints last until the
+// transaction ends, so this is a no-op there.
+func (tx *Tx) ReleaseSavepoint(ctx context.Context, name string) error {
+	if _, ok := tx.d.(sqlServerDialect); ok {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+tx.d.QuoteIdent(name))
+	return err
+}
+
+// Transaction executes fn within a savepoint of tx, so that calls nest:
+// if fn returns an error or panics, only its changes are rolled back and
+// the outer transaction carries on; otherwise the savepoint is released.
+// Either way tx is still open, and its own Commit or Rollback decides the
+// fate of everything fn did.
+func (tx *Tx) Transaction(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	tx.savepoints++
+	name := "ormgen_sp_" + strconv.Itoa(tx.savepoints)
+	if err := tx.Savepoint(ctx, name); err != nil {
+		return err
+	}
+	defer func() {
+		// Roll back even if ctx is done: PostgreSQL rejects every later
+		// statement of a transaction with a failed statement until then.
+		if p := recover(); p != nil {
+			_ = tx.RollbackTo(context.WithoutCancel(ctx), name)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.RollbackTo(context.WithoutCancel(ctx), name)
+		}
+	}()
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.ReleaseSavepoint(ctx, name)
+}
+
 func (tx *Tx) dialect() Dialect { return tx.d }
+
+func (tx *Tx) preloadStrategy() PreloadStrategy { return tx.preloads }
+
+// observeQuery reports a statement that started at start to o, if set.
+// res is nil for queries.
+func observeQuery(ctx context.Context, o QueryObserver, query string, args []any, start time.Time, res sql.Result, err error) {
+	if o == nil {
+		return
+	}
+	info := QueryInfo{SQL: query, Args: args, Duration: time.Since(start), RowsAffected: -1, Err: err}
+	if res != nil && err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			info.RowsAffected = n
+		}
+	}
+	o.ObserveQuery(ctx, info)
+}
@@ -0,0 +1,386 @@
+package orm_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+var errStubFailed = errors.New("stub: statement failed")
+
+// stubConnector opens connections whose statements succeed after delay,
+// unless the SQL contains "fail". Exec reports 3 affected rows, and a query
+// whose SQL contains "found" returns the row (id 1, name "found"), one
+// containing "endless" never runs out of rows (id n, name "user<n>"); other
+// queries return no rows. Prepared statements are counted in prepares.
+type stubConnector struct {
+	delay    time.Duration
+	prepares *atomic.Int64
+}
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn(c), nil }
+func (c stubConnector) Driver() driver.Driver                        { return nil }
+
+type stubConn struct {
+	delay    time.Duration
+	prepares *atomic.Int64
+}
+
+func (c stubConn) run(query string) error {
+	time.Sleep(c.delay)
+	if strings.Contains(query, "fail") {
+		return errStubFailed
+	}
+	return nil
+}
+
+func (c stubConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.run(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (c stubConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.run(query); err != nil {
+		return nil, err
+	}
+	if strings.Contains(query, "endless") {
+		return &stubRows{cols: []string{"id", "name"}, endless: true}, nil
+	}
+	if strings.Contains(query, "found") {
+		return &stubRows{cols: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "found"}}}, nil
+	}
+	return &stubRows{}, nil
+}
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) {
+	c.prepares.Add(1)
+	return stubStmt{conn: c, query: query}, nil
+}
+
+func (stubConn) Close() error              { return nil }
+func (stubConn) Begin() (driver.Tx, error) { return stubTx{}, nil }
+
+type stubStmt struct {
+	conn  stubConn
+	query string
+}
+
+func (stubStmt) Close() error  { return nil }
+func (stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, nil)
+}
+
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type stubTx struct{}
+
+func (stubTx) Commit() error   { return nil }
+func (stubTx) Rollback() error { return nil }
+
+type stubRows struct {
+	cols    []string
+	rows    [][]driver.Value
+	endless bool
+	served  int64
+}
+
+func (r *stubRows) Columns() []string { return r.cols }
+func (*stubRows) Close() error        { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.endless {
+		r.served++
+		dest[0], dest[1] = r.served, "user"+strconv.FormatInt(r.served, 10)
+		return nil
+	}
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type recordingObserver struct{ infos []orm.QueryInfo }
+
+func (o *recordingObserver) ObserveQuery(_ context.Context, info orm.QueryInfo) {
+	o.infos = append(o.infos, info)
+}
+
+func newStubDB(t *testing.T, delay time.Duration) *orm.DB {
+	t.Helper()
+
+	db, _ := newCountingStubDB(t, delay)
+	return db
+}
+
+// newCountingStubDB is like newStubDB but also returns the number of
+// statements prepared by the driver. The pool holds a single connection,
+// so a statement is never re-prepared on another one.
+func newCountingStubDB(t *testing.T, delay time.Duration) (*orm.DB, *atomic.Int64) {
+	t.Helper()
+
+	prepares := new(atomic.Int64)
+	raw := sql.OpenDB(stubConnector{delay: delay, prepares: prepares})
+	raw.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = raw.Close() })
+	return orm.New(raw, orm.MySQL), prepares
+}
+
+func TestObserverReceivesFailedQuery(t *testing.T) {
+	t.Parallel()
+
+	obs := &recordingObserver{}
+	db := newStubDB(t, 5*time.Millisecond).WithObserver(obs)
+
+	_, err := db.QueryContext(t.Context(), "SELECT fail FROM users WHERE id = ?", 1)
+	if !errors.Is(err, errStubFailed) {
+		t.Fatalf("QueryContext err = %v, want %v", err, errStubFailed)
+	}
+
+	if len(obs.infos) != 1 {
+		t.Fatalf("len(infos) = %d, want 1", len(obs.infos))
+	}
+	info := obs.infos[0]
+	if info.SQL != "SELECT fail FROM users WHERE id = ?" || len(info.Args) != 1 || info.Args[0] != 1 {
+		t.Errorf("info = %+v, want the statement and its args", info)
+	}
+	if !errors.Is(info.Err, errStubFailed) {
+		t.Errorf("info.Err = %v, want %v", info.Err, errStubFailed)
+	}
+	if info.Duration < 5*time.Millisecond {
+		t.Errorf("info.Duration = %v, want at least 5ms", info.Duration)
+	}
+	if info.RowsAffected != -1 {
+		t.Errorf("info.RowsAffected = %d, want -1", info.RowsAffected)
+	}
+}
+
+func TestObserverReceivesRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	obs := &recordingObserver{}
+	db := newStubDB(t, 0).WithObserver(obs)
+
+	if _, err := db.ExecContext(t.Context(), "UPDATE users SET name = ?", "alice"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if _, err := db.ExecContext(t.Context(), "UPDATE fail"); err == nil {
+		t.Fatal("ExecContext: expected error, got nil")
+	}
+
+	if len(obs.infos) != 2 {
+		t.Fatalf("len(infos) = %d, want 2", len(obs.infos))
+	}
+	if got := obs.infos[0]; got.RowsAffected != 3 || got.Err != nil {
+		t.Errorf("infos[0] = %+v, want 3 rows affected and no error", got)
+	}
+	if got := obs.infos[1]; got.RowsAffected != -1 || !errors.Is(got.Err, errStubFailed) {
+		t.Errorf("infos[1] = %+v, want -1 rows affected and the error", got)
+	}
+}
+
+func TestObserverIsInheritedByTx(t *testing.T) {
+	t.Parallel()
+
+	obs := &recordingObserver{}
+	db := newStubDB(t, 0).WithObserver(obs).WithPreloadStrategy(orm.PreloadJoin)
+
+	err := db.Transaction(t.Context(), func(tx *orm.Tx) error {
+		_, err := tx.ExecContext(t.Context(), "DELETE FROM users")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if len(obs.infos) != 1 || obs.infos[0].SQL != "DELETE FROM users" {
+		t.Errorf("infos = %+v, want the DELETE run in the transaction", obs.infos)
+	}
+}
+
+func TestTxTransactionUsesSavepoints(t *testing.T) {
+	t.Parallel()
+
+	obs := &recordingObserver{}
+	db := newStubDB(t, 0).WithObserver(obs)
+	errInner := errors.New("inner failed")
+
+	err := db.Transaction(t.Context(), func(tx *orm.Tx) error {
+		if err := tx.Transaction(t.Context(), func(tx *orm.Tx) error {
+			_, err := tx.ExecContext(t.Context(), "INSERT a")
+			return err
+		}); err != nil {
+			return err
+		}
+		err := tx.Transaction(t.Context(), func(tx *orm.Tx) error {
+			_, _ = tx.ExecContext(t.Context(), "INSERT b")
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("nested Transaction err = %v, want errInner", err)
+		}
+		func() {
+			defer func() { _ = recover() }()
+			_ = tx.Transaction(t.Context(), func(*orm.Tx) error { panic("boom") })
+		}()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+
+	want := []string{
+		"SAVEPOINT `ormgen_sp_1`", "INSERT a", "RELEASE SAVEPOINT `ormgen_sp_1`",
+		"SAVEPOINT `ormgen_sp_2`", "INSERT b", "ROLLBACK TO SAVEPOINT `ormgen_sp_2`",
+		"SAVEPOINT `ormgen_sp_3`", "ROLLBACK TO SAVEPOINT `ormgen_sp_3`",
+	}
+	if len(obs.infos) != len(want) {
+		t.Fatalf("len(infos) = %d, want %d: %+v", len(obs.infos), len(want), obs.infos)
+	}
+	for i, w := range want {
+		if obs.infos[i].SQL != w {
+			t.Errorf("infos[%d].SQL = %q, want %q", i, obs.infos[i].SQL, w)
+		}
+	}
+}
+
+type recordingLogger struct{ queries []string }
+
+func (l *recordingLogger) Log(_ context.Context, query string, _ ...any) {
+	l.queries = append(l.queries, query)
+}
+
+func TestDebugLoggerWithObserver(t *testing.T) {
+	t.Parallel()
+
+	logger := &recordingLogger{}
+	obs := &recordingObserver{}
+	db := newStubDB(t, 0).Debug(logger).WithObserver(obs)
+
+	_, _ = db.QueryContext(t.Context(), "SELECT fail")
+
+	if len(logger.queries) != 1 || logger.queries[0] != "SELECT fail" {
+		t.Errorf("logged = %v, want [SELECT fail]", logger.queries)
+	}
+	if len(obs.infos) != 1 || obs.infos[0].Err == nil {
+		t.Errorf("infos = %+v, want the failed SELECT", obs.infos)
+	}
+}
+
+func TestStatementCacheReusesStatements(t *testing.T) {
+	t.Parallel()
+
+	db, prepares := newCountingStubDB(t, 0)
+	db = db.WithStatementCache(2)
+	ctx := t.Context()
+
+	for range 3 {
+		rows, err := db.QueryContext(ctx, "SELECT a")
+		if err != nil {
+			t.Fatalf("QueryContext: %v", err)
+		}
+		_ = rows.Close()
+		if _, err := db.ExecContext(ctx, "UPDATE b"); err != nil {
+			t.Fatalf("ExecContext: %v", err)
+		}
+	}
+	if got := prepares.Load(); got != 2 {
+		t.Errorf("prepares = %d, want 2 (one per distinct statement)", got)
+	}
+
+	// A third statement evicts the least recently used one, SELECT a.
+	if _, err := db.ExecContext(ctx, "UPDATE c"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE b"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if got := prepares.Load(); got != 3 {
+		t.Errorf("prepares = %d, want 3 (UPDATE b still cached)", got)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT a")
+	if err != nil {
+		t.Fatalf("QueryContext: %v", err)
+	}
+	_ = rows.Close()
+	if got := prepares.Load(); got != 4 {
+		t.Errorf("prepares = %d, want 4 (SELECT a prepared again)", got)
+	}
+}
+
+func TestStatementCacheDropsFailedStatements(t *testing.T) {
+	t.Parallel()
+
+	db, prepares := newCountingStubDB(t, 0)
+	db = db.WithStatementCache(8)
+
+	for range 2 {
+		if _, err := db.ExecContext(t.Context(), "UPDATE fail"); !errors.Is(err, errStubFailed) {
+			t.Fatalf("ExecContext err = %v, want %v", err, errStubFailed)
+		}
+	}
+	if got := prepares.Load(); got != 2 {
+		t.Errorf("prepares = %d, want 2 (a failed statement is prepared afresh)", got)
+	}
+}
+
+func TestStatementCacheDisabled(t *testing.T) {
+	t.Parallel()
+
+	db, prepares := newCountingStubDB(t, 0)
+	db = db.WithStatementCache(8).WithStatementCache(0)
+
+	if _, err := db.ExecContext(t.Context(), "UPDATE b"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if got := prepares.Load(); got != 0 {
+		t.Errorf("prepares = %d, want 0", got)
+	}
+}
+
+func TestStatementCacheConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	raw := sql.OpenDB(stubConnector{prepares: new(atomic.Int64)})
+	t.Cleanup(func() { _ = raw.Close() })
+	db := orm.New(raw, orm.MySQL).WithStatementCache(2)
+
+	queries := []string{"SELECT a", "SELECT b", "SELECT c", "SELECT fail"}
+	var wg sync.WaitGroup
+	for i := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query := queries[i%len(queries)]
+			rows, err := db.QueryContext(t.Context(), query)
+			if query == "SELECT fail" {
+				if !errors.Is(err, errStubFailed) {
+					t.Errorf("%s: err = %v, want %v", query, err, errStubFailed)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("%s: %v", query, err)
+				return
+			}
+			_ = rows.Close()
+		}()
+	}
+	wg.Wait()
+}
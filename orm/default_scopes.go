@@ -0,0 +1,63 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/mickamy/ormgen/scope"
+)
+
+type defaultScopesKey struct{}
+
+// WithDefaultScopes returns a child context carrying scopes that every query
+// run with it applies before building SQL, e.g. to isolate tenants in one
+// place instead of at each call site:
+//
+//	ctx = orm.WithDefaultScopes(ctx, scope.Where("tenant_id = ?", tenantID))
+//	users, err := query.Users(db).All(ctx) // → ... WHERE tenant_id = ?
+//	n, err := query.Users(db).Where("a OR b").Count(ctx) // → ... WHERE (a OR b) AND tenant_id = ?
+//
+// Scopes already in ctx are kept and the new ones follow them. SELECTs,
+// Count and the aggregates, Exists, Updates and Delete apply them; so do
+// the queries Preload runs for related models, which therefore need the
+// same columns, or WithoutDefaultScopes on the relation's query. Creates,
+// the primary-key based Update and Raw do not, and a PreparedQuery refuses
+// to run with them, since its SQL was built before the context was known.
+func WithDefaultScopes(ctx context.Context, scopes ...scope.Scope) context.Context {
+	prev := defaultScopesFromContext(ctx)
+	all := make([]scope.Scope, 0, len(prev)+len(scopes))
+	all = append(append(all, prev...), scopes...)
+	return context.WithValue(ctx, defaultScopesKey{}, all)
+}
+
+func defaultScopesFromContext(ctx context.Context) []scope.Scope {
+	s, _ := ctx.Value(defaultScopesKey{}).([]scope.Scope)
+	return s
+}
+
+// WithoutDefaultScopes makes the query ignore the scopes carried by the
+// context, e.g. for an admin report across all tenants. It is separate from
+// Unscoped, which only lifts the soft delete filter.
+func (q *Query[T]) WithoutDefaultScopes() *Query[T] {
+	q2 := q.clone()
+	q2.noDefaultScopes = true
+	return q2
+}
+
+// withDefaultScopes returns q with the default scopes in ctx applied, or q
+// itself when there are none or the query opted out. The query's own WHERE
+// conditions are parenthesized first, so that an OR among them cannot
+// bypass the default scopes.
+func (q *Query[T]) withDefaultScopes(ctx context.Context) *Query[T] {
+	if q.noDefaultScopes {
+		return q
+	}
+	scopes := defaultScopesFromContext(ctx)
+	if len(scopes) == 0 {
+		return q
+	}
+	q2 := q.clone()
+	if w, ok := groupWheres(q.wheres); ok {
+		q2.wheres = []whereClause{w}
+	}
+	return q2.Scopes(scopes...)
+}
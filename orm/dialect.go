@@ -1,6 +1,9 @@
 package orm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Dialect abstracts SQL differences between database engines.
 type Dialect interface {
@@ -23,24 +26,222 @@ type Dialect interface {
 	// statements. Returns an empty string for dialects that do not
 	// support RETURNING (MySQL).
 	ReturningClause(pk string) string
+
+	// CaseInsensitive wraps a column or expression so that comparisons and
+	// ordering ignore case. Both built-in dialects use LOWER(), which works
+	// regardless of the column's collation; a custom dialect may return a
+	// COLLATE clause instead.
+	CaseInsensitive(expr string) string
+
+	// ILike returns a case-insensitive LIKE comparison of expr against a
+	// single ? placeholder. PostgreSQL uses ILIKE; MySQL compares both
+	// sides with CaseInsensitive.
+	ILike(expr string) string
+
+	// HintPlacement reports where a raw hint fragment passed to
+	// Query.Hint belongs in a SELECT statement.
+	HintPlacement(fragment string) HintPlacement
+
+	// PaginationClause returns the suffix of a SELECT that applies the
+	// given optional limit and offset, with a leading space, e.g.
+	// " LIMIT 10 OFFSET 20". hasOrderBy reports whether the SELECT already
+	// ends in ORDER BY, which SQL Server's OFFSET ... FETCH requires. It
+	// returns an empty string when both limit and offset are nil.
+	PaginationClause(limit, offset *int, hasOrderBy bool) string
+
+	// LockClause returns the row-locking suffix of a SELECT, with a leading
+	// space, e.g. " FOR UPDATE". With skipLocked, rows locked by other
+	// transactions are skipped rather than waited for. A dialect without
+	// row locking returns an empty string, and ForUpdate then fails.
+	LockClause(skipLocked bool) string
+
+	// MaxParams returns the largest number of bind parameters one
+	// statement may carry; CreateAll splits larger batches. MySQL and
+	// PostgreSQL return 65535, the limit of PostgreSQL's wire protocol and
+	// of MySQL's prepared statements; SQL Server returns 2100.
+	MaxParams() int
+
+	// ExplainClause returns the prefix that makes a SELECT report its plan
+	// instead of its rows, without a trailing space, e.g. "EXPLAIN". With
+	// analyze, the statement also runs and the plan carries actual times
+	// and row counts. A dialect without EXPLAIN returns an empty string.
+	ExplainClause(analyze bool) string
 }
 
+// HintPlacement is the position of a query hint within a SELECT statement.
+type HintPlacement int
+
+const (
+	// HintBeforeSelect prefixes the statement, e.g. pg_hint_plan's
+	// "/*+ SeqScan(users) */ SELECT ...".
+	HintBeforeSelect HintPlacement = iota
+	// HintAfterSelect follows the SELECT keyword, e.g. MySQL optimizer
+	// hints "SELECT /*+ MAX_EXECUTION_TIME(1000) */ ...".
+	HintAfterSelect
+	// HintAfterTable follows the base table name, e.g. MySQL index hints
+	// "FROM users USE INDEX (idx_name)".
+	HintAfterTable
+)
+
 // MySQL is the Dialect for MySQL / MariaDB.
 var MySQL Dialect = mysqlDialect{}
 
 // PostgreSQL is the Dialect for PostgreSQL.
 var PostgreSQL Dialect = postgresDialect{}
 
+// SQLServer is the Dialect for Microsoft SQL Server and Azure SQL.
+// It has neither RETURNING nor a driver LastInsertId, so Create and
+// CreateAll reject models with a database-assigned primary key before
+// inserting; such models must assign the key themselves. Upsert,
+// InsertIgnore and ForUpdate return an error.
+var SQLServer Dialect = sqlServerDialect{}
+
 type mysqlDialect struct{}
 
-func (mysqlDialect) Placeholder(_ int) string        { return "?" }
-func (mysqlDialect) QuoteIdent(name string) string   { return "`" + name + "`" }
-func (mysqlDialect) UseReturning() bool              { return false }
-func (mysqlDialect) ReturningClause(_ string) string { return "" }
+func (mysqlDialect) Placeholder(_ int) string           { return "?" }
+func (mysqlDialect) QuoteIdent(name string) string      { return "`" + name + "`" }
+func (mysqlDialect) UseReturning() bool                 { return false }
+func (mysqlDialect) ReturningClause(_ string) string    { return "" }
+func (mysqlDialect) CaseInsensitive(expr string) string { return "LOWER(" + expr + ")" }
+
+func (mysqlDialect) HintPlacement(fragment string) HintPlacement {
+	if strings.HasPrefix(strings.TrimSpace(fragment), "/*+") {
+		return HintAfterSelect
+	}
+	return HintAfterTable
+}
+
+// mysqlMaxRows is the LIMIT MySQL documents for "all remaining rows": it
+// has no OFFSET without LIMIT.
+const mysqlMaxRows = "18446744073709551615"
+
+func (mysqlDialect) PaginationClause(limit, offset *int, _ bool) string {
+	if limit == nil && offset != nil {
+		return fmt.Sprintf(" LIMIT %s OFFSET %d", mysqlMaxRows, *offset)
+	}
+	return limitOffset(limit, offset)
+}
+
+func (mysqlDialect) LockClause(skipLocked bool) string { return lockClause(skipLocked) }
+
+func (mysqlDialect) MaxParams() int { return maxParams }
+
+// ExplainClause returns EXPLAIN, or EXPLAIN ANALYZE, which needs MySQL
+// 8.0.18 or later and prints the plan as a tree.
+func (mysqlDialect) ExplainClause(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE"
+	}
+	return "EXPLAIN"
+}
+
+func (d mysqlDialect) ILike(expr string) string {
+	return d.CaseInsensitive(expr) + " LIKE " + d.CaseInsensitive("?")
+}
 
 type postgresDialect struct{}
 
-func (postgresDialect) Placeholder(index int) string     { return fmt.Sprintf("$%d", index) }
-func (postgresDialect) QuoteIdent(name string) string    { return `"` + name + `"` }
-func (postgresDialect) UseReturning() bool               { return true }
-func (postgresDialect) ReturningClause(pk string) string { return ` RETURNING "` + pk + `"` }
+func (postgresDialect) Placeholder(index int) string       { return fmt.Sprintf("$%d", index) }
+func (postgresDialect) QuoteIdent(name string) string      { return `"` + name + `"` }
+func (postgresDialect) UseReturning() bool                 { return true }
+func (postgresDialect) ReturningClause(pk string) string   { return ` RETURNING "` + pk + `"` }
+func (postgresDialect) CaseInsensitive(expr string) string { return "LOWER(" + expr + ")" }
+
+// HintPlacement always prefixes the statement: PostgreSQL has no inline
+// hint syntax, and pg_hint_plan reads the leading comment.
+func (postgresDialect) HintPlacement(_ string) HintPlacement { return HintBeforeSelect }
+
+func (postgresDialect) PaginationClause(limit, offset *int, _ bool) string {
+	return limitOffset(limit, offset)
+}
+
+func (postgresDialect) LockClause(skipLocked bool) string { return lockClause(skipLocked) }
+
+func (postgresDialect) MaxParams() int { return maxParams }
+
+// ExplainClause asks for the text format, one plan line per row, whatever
+// the server's default.
+func (postgresDialect) ExplainClause(analyze bool) string {
+	if analyze {
+		return "EXPLAIN (ANALYZE, FORMAT TEXT)"
+	}
+	return "EXPLAIN (FORMAT TEXT)"
+}
+
+func (postgresDialect) ILike(expr string) string { return expr + " ILIKE ?" }
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Placeholder(index int) string       { return fmt.Sprintf("@p%d", index) }
+func (sqlServerDialect) QuoteIdent(name string) string      { return "[" + name + "]" }
+func (sqlServerDialect) UseReturning() bool                 { return false }
+func (sqlServerDialect) ReturningClause(_ string) string    { return "" }
+func (sqlServerDialect) CaseInsensitive(expr string) string { return "LOWER(" + expr + ")" }
+
+// HintPlacement always follows the table: SQL Server's table hints, e.g.
+// "WITH (NOLOCK)", are the ones that fit a SELECT without an OPTION clause.
+func (sqlServerDialect) HintPlacement(_ string) HintPlacement { return HintAfterTable }
+
+// PaginationClause renders "OFFSET m ROWS FETCH NEXT n ROWS ONLY", SQL
+// Server's only way to page without TOP. OFFSET is not optional there and
+// needs an ORDER BY, so an unordered query gets "ORDER BY (SELECT NULL)",
+// which keeps whatever order the server returns rows in.
+func (sqlServerDialect) PaginationClause(limit, offset *int, hasOrderBy bool) string {
+	if limit == nil && offset == nil {
+		return ""
+	}
+	var s string
+	if !hasOrderBy {
+		s = " ORDER BY (SELECT NULL)"
+	}
+	rows := 0
+	if offset != nil {
+		rows = *offset
+	}
+	s += fmt.Sprintf(" OFFSET %d ROWS", rows)
+	if limit != nil {
+		s += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", *limit)
+	}
+	return s
+}
+
+// LockClause returns an empty string: SQL Server locks rows with table
+// hints such as UPDLOCK rather than a suffix.
+func (sqlServerDialect) LockClause(_ bool) string { return "" }
+
+// MaxParams returns 2100, the parameter limit of a SQL Server request.
+func (sqlServerDialect) MaxParams() int { return 2100 }
+
+// ExplainClause returns an empty string: SQL Server reports plans through
+// session settings such as SET SHOWPLAN_TEXT ON rather than a prefix.
+func (sqlServerDialect) ExplainClause(_ bool) string { return "" }
+
+func (d sqlServerDialect) ILike(expr string) string {
+	return d.CaseInsensitive(expr) + " LIKE " + d.CaseInsensitive("?")
+}
+
+// limitOffset renders the standard "LIMIT n OFFSET m" suffix, either part of
+// which may be absent.
+func limitOffset(limit, offset *int) string {
+	var s string
+	if limit != nil {
+		s += fmt.Sprintf(" LIMIT %d", *limit)
+	}
+	if offset != nil {
+		s += fmt.Sprintf(" OFFSET %d", *offset)
+	}
+	return s
+}
+
+// lockClause renders FOR UPDATE, which MySQL 8 and PostgreSQL share along
+// with SKIP LOCKED.
+func lockClause(skipLocked bool) string {
+	if skipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
+
+// maxParams is the bind parameter limit shared by MySQL and PostgreSQL:
+// both count parameters in an unsigned 16-bit field.
+const maxParams = 65535
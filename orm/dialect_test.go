@@ -71,6 +71,24 @@ func TestPostgreSQLReturningClause(t *testing.T) {
 	}
 }
 
+func TestSQLServerPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	for index, want := range map[int]string{1: "@p1", 2: "@p2", 10: "@p10"} {
+		if got := orm.SQLServer.Placeholder(index); got != want {
+			t.Errorf("Placeholder(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestSQLServerUseReturning(t *testing.T) {
+	t.Parallel()
+
+	if orm.SQLServer.UseReturning() || orm.SQLServer.ReturningClause("id") != "" {
+		t.Error("SQLServer should not use RETURNING")
+	}
+}
+
 func TestMySQLQuoteIdent(t *testing.T) {
 	t.Parallel()
 
@@ -87,3 +105,140 @@ func TestPostgreSQLQuoteIdent(t *testing.T) {
 		t.Errorf("QuoteIdent = %q, want %q", got, want)
 	}
 }
+
+func TestSQLServerQuoteIdent(t *testing.T) {
+	t.Parallel()
+
+	if got := orm.SQLServer.QuoteIdent("order"); got != "[order]" {
+		t.Errorf("QuoteIdent = %q, want %q", got, "[order]")
+	}
+}
+
+func TestPaginationClause(t *testing.T) {
+	t.Parallel()
+
+	ten, twenty := 10, 20
+	tests := []struct {
+		name       string
+		dialect    orm.Dialect
+		limit      *int
+		offset     *int
+		hasOrderBy bool
+		want       string
+	}{
+		{"MySQL none", orm.MySQL, nil, nil, false, ""},
+		{"MySQL both", orm.MySQL, &ten, &twenty, false, " LIMIT 10 OFFSET 20"},
+		{"MySQL offset only", orm.MySQL, nil, &twenty, true, " LIMIT 18446744073709551615 OFFSET 20"},
+		{"PostgreSQL both", orm.PostgreSQL, &ten, &twenty, false, " LIMIT 10 OFFSET 20"},
+		{"PostgreSQL offset only", orm.PostgreSQL, nil, &twenty, false, " OFFSET 20"},
+		{"SQL Server none", orm.SQLServer, nil, nil, false, ""},
+		{"SQL Server ordered", orm.SQLServer, &ten, &twenty, true, " OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"SQL Server unordered", orm.SQLServer, &ten, nil, false, " ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"SQL Server offset only", orm.SQLServer, nil, &twenty, true, " OFFSET 20 ROWS"},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.PaginationClause(tt.limit, tt.offset, tt.hasOrderBy); got != tt.want {
+			t.Errorf("%s: PaginationClause = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []orm.Dialect{orm.MySQL, orm.PostgreSQL, orm.SQLServer} {
+		if got := d.CaseInsensitive("name"); got != "LOWER(name)" {
+			t.Errorf("CaseInsensitive = %q, want %q", got, "LOWER(name)")
+		}
+	}
+}
+
+func TestHintPlacement(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dialect  orm.Dialect
+		fragment string
+		want     orm.HintPlacement
+	}{
+		{"MySQL optimizer hint", orm.MySQL, " /*+ BKA(users) */", orm.HintAfterSelect},
+		{"MySQL index hint", orm.MySQL, "USE INDEX (idx)", orm.HintAfterTable},
+		{"PostgreSQL hint", orm.PostgreSQL, "/*+ SeqScan(users) */", orm.HintBeforeSelect},
+		{"SQL Server table hint", orm.SQLServer, "WITH (NOLOCK)", orm.HintAfterTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.dialect.HintPlacement(tt.fragment); got != tt.want {
+				t.Errorf("HintPlacement(%q) = %v, want %v", tt.fragment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockClause(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []orm.Dialect{orm.MySQL, orm.PostgreSQL} {
+		if got := d.LockClause(false); got != " FOR UPDATE" {
+			t.Errorf("LockClause(false) = %q, want %q", got, " FOR UPDATE")
+		}
+		if got := d.LockClause(true); got != " FOR UPDATE SKIP LOCKED" {
+			t.Errorf("LockClause(true) = %q, want %q", got, " FOR UPDATE SKIP LOCKED")
+		}
+	}
+	if got := orm.SQLServer.LockClause(false); got != "" {
+		t.Errorf("SQLServer.LockClause(false) = %q, want empty", got)
+	}
+}
+
+func TestMaxParams(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []orm.Dialect{orm.MySQL, orm.PostgreSQL} {
+		if got := d.MaxParams(); got != 65535 {
+			t.Errorf("MaxParams() = %d, want 65535", got)
+		}
+	}
+	if got := orm.SQLServer.MaxParams(); got != 2100 {
+		t.Errorf("SQLServer.MaxParams() = %d, want 2100", got)
+	}
+}
+
+func TestExplainClause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		analyze bool
+		want    string
+	}{
+		{"MySQL", orm.MySQL, false, "EXPLAIN"},
+		{"MySQL analyze", orm.MySQL, true, "EXPLAIN ANALYZE"},
+		{"PostgreSQL", orm.PostgreSQL, false, "EXPLAIN (FORMAT TEXT)"},
+		{"PostgreSQL analyze", orm.PostgreSQL, true, "EXPLAIN (ANALYZE, FORMAT TEXT)"},
+		{"SQL Server", orm.SQLServer, false, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.ExplainClause(tt.analyze); got != tt.want {
+			t.Errorf("%s: ExplainClause(%v) = %q, want %q", tt.name, tt.analyze, got, tt.want)
+		}
+	}
+}
+
+func TestILike(t *testing.T) {
+	t.Parallel()
+
+	if got, want := orm.MySQL.ILike("email"), "LOWER(email) LIKE LOWER(?)"; got != want {
+		t.Errorf("MySQL.ILike = %q, want %q", got, want)
+	}
+	if got, want := orm.PostgreSQL.ILike("email"), "email ILIKE ?"; got != want {
+		t.Errorf("PostgreSQL.ILike = %q, want %q", got, want)
+	}
+	if got, want := orm.SQLServer.ILike("email"), "LOWER(email) LIKE LOWER(?)"; got != want {
+		t.Errorf("SQLServer.ILike = %q, want %q", got, want)
+	}
+}
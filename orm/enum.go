@@ -0,0 +1,26 @@
+package orm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ParseEnum returns s as an E if it equals one of valid, and an error
+// wrapping ErrInvalidEnum otherwise. Generated Parse<Type> functions use it
+// for types whose column is tagged with enum values.
+func ParseEnum[E ~string](s string, valid ...E) (E, error) {
+	v := E(s)
+	if !slices.Contains(valid, v) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEnum, s)
+	}
+	return v, nil
+}
+
+// CheckEnum returns an error wrapping ErrInvalidEnum, naming column, if v is
+// not one of valid. Generated validators call it for every enum field.
+func CheckEnum[E ~string](column string, v E, valid ...E) error {
+	if !slices.Contains(valid, v) {
+		return fmt.Errorf("%w: %s = %q", ErrInvalidEnum, column, string(v))
+	}
+	return nil
+}
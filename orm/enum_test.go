@@ -0,0 +1,42 @@
+package orm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+type enumStatus string
+
+var enumStatusValues = []enumStatus{"active", "banned"}
+
+func TestParseEnum(t *testing.T) {
+	t.Parallel()
+
+	got, err := orm.ParseEnum("banned", enumStatusValues...)
+	if err != nil {
+		t.Fatalf("ParseEnum: %v", err)
+	}
+	if got != "banned" {
+		t.Errorf("got %q, want banned", got)
+	}
+
+	if _, err := orm.ParseEnum("deleted", enumStatusValues...); !errors.Is(err, orm.ErrInvalidEnum) {
+		t.Errorf("err = %v, want ErrInvalidEnum", err)
+	}
+}
+
+func TestCheckEnum(t *testing.T) {
+	t.Parallel()
+
+	if err := orm.CheckEnum("status", enumStatus("active"), enumStatusValues...); err != nil {
+		t.Errorf("CheckEnum(active) = %v, want nil", err)
+	}
+
+	err := orm.CheckEnum("status", enumStatus(""), enumStatusValues...)
+	if !errors.Is(err, orm.ErrInvalidEnum) || !strings.Contains(err.Error(), `status = ""`) {
+		t.Errorf("err = %v, want ErrInvalidEnum naming the column", err)
+	}
+}
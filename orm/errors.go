@@ -4,3 +4,35 @@ import "errors"
 
 // ErrNotFound is returned when a query expects exactly one row but finds none.
 var ErrNotFound = errors.New("orm: not found")
+
+// ErrReadOnly is returned by write methods (Create, Update, Delete, ...)
+// called on a Query bound to a read-only transaction.
+var ErrReadOnly = errors.New("orm: write in read-only transaction")
+
+// ErrReadOnlyModel is returned by write methods called on a Query for a
+// model generated as read-only (e.g. a view or a lookup table without a
+// primary key).
+var ErrReadOnlyModel = errors.New("orm: write to read-only model")
+
+// ErrPlaceholderMismatch is returned by terminal methods when a clause added
+// with scope.WhereChecked has a different number of ? placeholders than args.
+var ErrPlaceholderMismatch = errors.New("orm: placeholder count does not match args")
+
+// ErrUnknownSortColumn is returned by generated Parse<Type>SortColumn
+// functions when the input is not one of the model's columns.
+var ErrUnknownSortColumn = errors.New("orm: unknown sort column")
+
+// ErrStaleObject is returned by Update when the model has a version column
+// and no row has both the primary key and the version of the struct: the
+// row was changed (or deleted) since the struct was read.
+var ErrStaleObject = errors.New("orm: stale object")
+
+// ErrQueryTimeout wraps the driver error of a statement cut off by the
+// timeout set with WithQueryTimeout. The wrapped error usually also matches
+// context.DeadlineExceeded.
+var ErrQueryTimeout = errors.New("orm: query timeout exceeded")
+
+// ErrInvalidEnum is returned when a column tagged with enum values is given
+// a value outside them: by generated Parse<Type> functions, and by writes
+// such as Create and Update before any statement runs.
+var ErrInvalidEnum = errors.New("orm: invalid enum value")
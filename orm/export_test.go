@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sync"
 )
 
 var errMockNotImplemented = errors.New("mock: not implemented")
@@ -11,14 +12,31 @@ var errMockNotImplemented = errors.New("mock: not implemented")
 // TestQuerier is a mock Querier that records executed queries.
 // Exported for use in orm_test package.
 type TestQuerier struct {
-	D       Dialect
-	Queries []TestQuery
+	D        Dialect
+	Preloads PreloadStrategy
+	Queries  []TestQuery
+
+	// InsertIDs are returned in turn as LastInsertId by ExecContext, e.g.
+	// to simulate non-contiguous auto-increment values. Once exhausted,
+	// LastInsertId returns 0.
+	InsertIDs []int64
+
+	// RowsAffected is returned as RowsAffected by every ExecContext result.
+	RowsAffected int64
+
+	// Block makes every statement wait until its context is done and
+	// return the context's error, like a query stuck on a lock.
+	Block bool
+
+	mu sync.Mutex // guards Queries for queries run from several goroutines
 }
 
-// TestQuery holds a captured query string and its args.
+// TestQuery holds a captured query string, its args and the context it
+// was run with.
 type TestQuery struct {
 	SQL  string
 	Args []any
+	Ctx  context.Context
 }
 
 // NewTestQuerier creates a TestQuerier with the given Dialect.
@@ -26,18 +44,36 @@ func NewTestQuerier(d Dialect) *TestQuerier {
 	return &TestQuerier{D: d}
 }
 
-func (tq *TestQuerier) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
-	tq.Queries = append(tq.Queries, TestQuery{query, args})
+func (tq *TestQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	tq.record(TestQuery{query, args, ctx})
+	if tq.Block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	return nil, errMockNotImplemented
 }
 
-func (tq *TestQuerier) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
-	tq.Queries = append(tq.Queries, TestQuery{query, args})
-	return testResult{}, nil
+func (tq *TestQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	tq.record(TestQuery{query, args, ctx})
+	if tq.Block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	res := testResult{affected: tq.RowsAffected}
+	if len(tq.InsertIDs) > 0 {
+		res.id, tq.InsertIDs = tq.InsertIDs[0], tq.InsertIDs[1:]
+	}
+	return res, nil
 }
 
 var _ Querier = (*TestQuerier)(nil)
 
+func (tq *TestQuerier) record(q TestQuery) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	tq.Queries = append(tq.Queries, q)
+}
+
 // LastQuery returns the most recently captured query, or panics if empty.
 func (tq *TestQuerier) LastQuery() TestQuery {
 	return tq.Queries[len(tq.Queries)-1]
@@ -45,7 +81,28 @@ func (tq *TestQuerier) LastQuery() TestQuery {
 
 func (tq *TestQuerier) dialect() Dialect { return tq.D }
 
-type testResult struct{}
+func (tq *TestQuerier) preloadStrategy() PreloadStrategy { return tq.Preloads }
+
+type testResult struct{ id, affected int64 }
 
-func (testResult) LastInsertId() (int64, error) { return 0, nil }
-func (testResult) RowsAffected() (int64, error) { return 0, nil }
+func (r testResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r testResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+// NewReadOnlyTestTx returns a read-only Tx with no underlying connection,
+// for testing that write methods fail before reaching the database.
+func NewReadOnlyTestTx(d Dialect) *Tx {
+	return &Tx{d: d, readOnly: true}
+}
+
+// RewritePlaceholders exposes rewritePlaceholders for testing.
+func RewritePlaceholders(d Dialect, query string) string {
+	return rewritePlaceholders(d, query)
+}
+
+// MergeJoined exposes mergeJoined for testing, as if JoinPreload had joined
+// the to-many relation name.
+func MergeJoined[T any](q *Query[T], name string, rows []T) []T {
+	q2 := q.clone()
+	q2.joinedMany = name
+	return q2.mergeJoined(rows)
+}
@@ -0,0 +1,165 @@
+// Package filter builds scopes from struct tags, mapping request DTOs to
+// queries declaratively:
+//
+//	type UserFilter struct {
+//	    Name   string   `filter:"name,like"`
+//	    MinAge int      `filter:"age,gte"`
+//	    Roles  []string `filter:"role,in"`
+//	}
+//
+//	s, err := filter.Scopes(UserFilter{Name: "ali", MinAge: 18})
+//	// → WHERE name LIKE '%ali%' AND age >= 18
+//	Users(db).Scopes(s...).All(ctx)
+//
+// Unlike the generated code, this package relies on reflection. It lives
+// apart from orm so that only callers who opt in pay for it.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mickamy/ormgen/scope"
+)
+
+// operators maps a tag operator to its SQL comparison.
+var operators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Scopes returns a WHERE scope for every non-zero field of f tagged with
+// `filter:"column[,op]"`. f must be a struct or a pointer to one.
+//
+// Supported operators are eq (default), ne, gt, gte, lt, lte, like
+// (substring match; wildcards in the value are escaped) and in (slice
+// fields). Zero values, nil pointers and empty slices are skipped; use a
+// pointer field to filter on a zero value. Untagged fields and fields
+// tagged `filter:"-"` are ignored, and untagged embedded structs are
+// walked recursively.
+func Scopes(f any) (scope.Scopes, error) {
+	return build(f, walker{tag: "filter"})
+}
+
+// SearchScopes is like Scopes for search forms whose field names must not
+// reach SQL directly. Fields are tagged `search:"key[,op]"`, and allowlist
+// maps each key to the real column name. A tagged field whose key is
+// missing from allowlist is an error, even when the field is zero, so a
+// form can never filter on a column the caller did not approve.
+//
+//	type UserSearch struct {
+//	    Q    string `search:"q,like"`
+//	    Role string `search:"role"`
+//	}
+//
+//	s, err := filter.SearchScopes(form, map[string]string{"q": "users.name", "role": "users.role"})
+func SearchScopes(form any, allowlist map[string]string) (scope.Scopes, error) {
+	if allowlist == nil {
+		allowlist = map[string]string{}
+	}
+	return build(form, walker{tag: "search", allowlist: allowlist})
+}
+
+// walker reads one struct tag key. With a non-nil allowlist the tag names a
+// key that is translated to a column; otherwise it names the column itself.
+type walker struct {
+	tag       string
+	allowlist map[string]string
+}
+
+func build(f any, w walker) (scope.Scopes, error) {
+	v := reflect.ValueOf(f)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, errors.New("filter: nil pointer")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("filter: expected struct, got %T", f)
+	}
+
+	var ss scope.Scopes
+	if err := w.appendScopes(&ss, v); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (w walker) appendScopes(ss *scope.Scopes, v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		tag, ok := sf.Tag.Lookup(w.tag)
+		if !ok {
+			if sf.Anonymous && sf.IsExported() && fv.Kind() == reflect.Struct {
+				if err := w.appendScopes(ss, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		column, op, _ := strings.Cut(tag, ",")
+		if column == "" {
+			return fmt.Errorf("filter: field %s: missing column name", sf.Name)
+		}
+		if op == "" {
+			op = "eq"
+		}
+		if w.allowlist != nil {
+			mapped, ok := w.allowlist[column]
+			if !ok || mapped == "" {
+				return fmt.Errorf("filter: field %s: search key %q is not in the allowlist", sf.Name, column)
+			}
+			column = mapped
+		}
+
+		if fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			fv = fv.Elem()
+		}
+
+		switch op {
+		case "like":
+			if fv.Kind() != reflect.String {
+				return fmt.Errorf("filter: field %s: like requires a string, got %s", sf.Name, fv.Type())
+			}
+			*ss = ss.Append(scope.Where(column+" LIKE ?", "%"+likeEscaper.Replace(fv.String())+"%"))
+		case "in":
+			if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+				return fmt.Errorf("filter: field %s: in requires a slice, got %s", sf.Name, fv.Type())
+			}
+			if fv.Len() == 0 {
+				continue
+			}
+			values := make([]any, fv.Len())
+			for j := range values {
+				values[j] = fv.Index(j).Interface()
+			}
+			*ss = ss.Append(scope.In(column, values))
+		default:
+			sqlOp, ok := operators[op]
+			if !ok {
+				return fmt.Errorf("filter: field %s: unknown operator %q", sf.Name, op)
+			}
+			*ss = ss.Append(scope.Where(column+" "+sqlOp+" ?", fv.Interface()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mickamy/ormgen/orm/filter"
+	"github.com/mickamy/ormgen/scope"
+)
+
+// whereRecorder collects WHERE fragments from applied scopes.
+type whereRecorder struct {
+	clauses []string
+	args    []any
+}
+
+func (r *whereRecorder) ApplyWhere(clause string, args []any) {
+	r.clauses = append(r.clauses, clause)
+	r.args = append(r.args, args...)
+}
+func (r *whereRecorder) ApplyOrWhere(string, []any)                      {}
+func (r *whereRecorder) ApplyOrderBy(string)                             {}
+func (r *whereRecorder) ApplyLimit(int)                                  {}
+func (r *whereRecorder) ApplyOffset(int)                                 {}
+func (r *whereRecorder) ApplySelectColumns([]string)                     {}
+func (r *whereRecorder) ApplyJoin(string)                                {}
+func (r *whereRecorder) ApplyLeftJoin(string)                            {}
+func (r *whereRecorder) ApplyPreload(string)                             {}
+func (r *whereRecorder) ApplyOrderByCI(string, string)                   {}
+func (r *whereRecorder) ApplyEqCI(string, any)                           {}
+func (r *whereRecorder) ApplyILike(string, any)                          {}
+func (r *whereRecorder) ApplyColumnWhere(string, string, []any)          {}
+func (r *whereRecorder) ApplyQualifiedColumnWhere(string, string, []any) {}
+func (r *whereRecorder) ApplyWhereChecked(string, []any)                 {}
+func (r *whereRecorder) ApplyOrderByColumn(string, string)               {}
+func (r *whereRecorder) ApplyGroupBy([]string)                           {}
+func (r *whereRecorder) ApplyHaving(string, []any)                       {}
+func (r *whereRecorder) ApplyDistinct()                                  {}
+
+func record(ss scope.Scopes) *whereRecorder {
+	r := &whereRecorder{}
+	for _, s := range ss {
+		s.Apply(r)
+	}
+	return r
+}
+
+type Paging struct {
+	Status string `filter:"status"`
+}
+
+type userFilter struct {
+	Paging
+	Name     string `filter:"name,like"`
+	MinAge   int    `filter:"age,gte"`
+	MaxAge   int    `filter:"age,lt"`
+	Role     string `filter:"role,ne"`
+	IDs      []int  `filter:"id,in"`
+	Verified *bool  `filter:"verified"`
+	Ignored  string `filter:"-"`
+	Untagged string
+}
+
+func TestScopes(t *testing.T) {
+	t.Parallel()
+
+	no := false
+
+	tests := []struct {
+		name        string
+		filter      any
+		wantClauses []string
+		wantArgs    []any
+	}{
+		{
+			name:        "zero values are skipped",
+			filter:      userFilter{Ignored: "x", Untagged: "y"},
+			wantClauses: nil,
+			wantArgs:    nil,
+		},
+		{
+			name:        "comparison operators",
+			filter:      userFilter{MinAge: 18, MaxAge: 65, Role: "admin"},
+			wantClauses: []string{"age >= ?", "age < ?", "role <> ?"},
+			wantArgs:    []any{18, 65, "admin"},
+		},
+		{
+			name:        "like escapes wildcards",
+			filter:      &userFilter{Name: "50%_off"},
+			wantClauses: []string{"name LIKE ?"},
+			wantArgs:    []any{`%50\%\_off%`},
+		},
+		{
+			name:        "in expands slice",
+			filter:      userFilter{IDs: []int{1, 2}},
+			wantClauses: []string{"id IN (?, ?)"},
+			wantArgs:    []any{1, 2},
+		},
+		{
+			name:        "pointer filters on zero value",
+			filter:      userFilter{Verified: &no},
+			wantClauses: []string{"verified = ?"},
+			wantArgs:    []any{false},
+		},
+		{
+			name:        "embedded struct",
+			filter:      userFilter{Paging: Paging{Status: "active"}},
+			wantClauses: []string{"status = ?"},
+			wantArgs:    []any{"active"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ss, err := filter.Scopes(tt.filter)
+			if err != nil {
+				t.Fatalf("Scopes: %v", err)
+			}
+			r := record(ss)
+			if !reflect.DeepEqual(r.clauses, tt.wantClauses) {
+				t.Errorf("clauses = %q, want %q", r.clauses, tt.wantClauses)
+			}
+			if !reflect.DeepEqual(r.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", r.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestScopesErrors(t *testing.T) {
+	t.Parallel()
+
+	var nilFilter *userFilter
+
+	tests := []struct {
+		name   string
+		filter any
+	}{
+		{name: "not a struct", filter: 42},
+		{name: "nil pointer", filter: nilFilter},
+		{name: "unknown operator", filter: struct {
+			Age int `filter:"age,between"`
+		}{Age: 1}},
+		{name: "like on non-string", filter: struct {
+			Age int `filter:"age,like"`
+		}{Age: 1}},
+		{name: "in on non-slice", filter: struct {
+			Age int `filter:"age,in"`
+		}{Age: 1}},
+		{name: "missing column", filter: struct {
+			Age int `filter:",gte"`
+		}{Age: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := filter.Scopes(tt.filter); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+type userSearch struct {
+	Q     string   `search:"q,like"`
+	Role  string   `search:"role"`
+	Since int      `search:"since,gte"`
+	Tags  []string `search:"tag,in"`
+}
+
+var userSearchColumns = map[string]string{
+	"q":     "users.name",
+	"role":  "users.role",
+	"since": "users.created_year",
+	"tag":   "tags.name",
+}
+
+func TestSearchScopes(t *testing.T) {
+	t.Parallel()
+
+	ss, err := filter.SearchScopes(&userSearch{Q: "ali", Since: 2020, Tags: []string{"go"}}, userSearchColumns)
+	if err != nil {
+		t.Fatalf("SearchScopes: %v", err)
+	}
+	r := record(ss)
+	wantClauses := []string{"users.name LIKE ?", "users.created_year >= ?", "tags.name IN (?)"}
+	if !reflect.DeepEqual(r.clauses, wantClauses) {
+		t.Errorf("clauses = %q, want %q", r.clauses, wantClauses)
+	}
+	wantArgs := []any{"%ali%", 2020, "go"}
+	if !reflect.DeepEqual(r.args, wantArgs) {
+		t.Errorf("args = %v, want %v", r.args, wantArgs)
+	}
+}
+
+func TestSearchScopesRejectsKeysOutsideAllowlist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		form      any
+		allowlist map[string]string
+	}{
+		{name: "missing key", form: userSearch{Role: "admin"}, allowlist: map[string]string{"q": "users.name"}},
+		{name: "missing key on zero field", form: userSearch{}, allowlist: map[string]string{"q": "users.name"}},
+		{name: "nil allowlist", form: userSearch{Q: "ali"}, allowlist: nil},
+		{name: "empty column", form: userSearch{Q: "ali"}, allowlist: map[string]string{
+			"q": "", "role": "users.role", "since": "users.created_year", "tag": "tags.name",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := filter.SearchScopes(tt.form, tt.allowlist); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSearchScopesIgnoresFilterTags(t *testing.T) {
+	t.Parallel()
+
+	ss, err := filter.SearchScopes(userFilter{Name: "ali"}, userSearchColumns)
+	if err != nil {
+		t.Fatalf("SearchScopes: %v", err)
+	}
+	if len(ss) != 0 {
+		t.Errorf("len(ss) = %d, want 0", len(ss))
+	}
+}
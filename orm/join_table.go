@@ -16,7 +16,7 @@ type JoinPair[S, T comparable] struct {
 // where sourceCol IN (sourceIDs). It returns a slice of JoinPair.
 func QueryJoinTable[S, T comparable](
 	ctx context.Context, db Querier, table, sourceCol, targetCol string, sourceIDs []S,
-) ([]JoinPair[S, T], error) {
+) (_ []JoinPair[S, T], err error) {
 	if len(sourceIDs) == 0 {
 		return nil, nil
 	}
@@ -39,6 +39,9 @@ func QueryJoinTable[S, T comparable](
 
 	query = rewritePlaceholders(d, query)
 
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err //nolint:wrapcheck // pass through
@@ -69,25 +72,6 @@ func UniqueTargets[S, T comparable](pairs []JoinPair[S, T]) []T {
 	return result
 }
 
-// rewritePlaceholders converts ? to dialect-specific placeholders ($1, $2, …).
-func rewritePlaceholders(d Dialect, query string) string {
-	if _, ok := d.(mysqlDialect); ok {
-		return query
-	}
-	var b strings.Builder
-	b.Grow(len(query))
-	idx := 1
-	for i := range len(query) {
-		if query[i] == '?' {
-			b.WriteString(d.Placeholder(idx))
-			idx++
-		} else {
-			b.WriteByte(query[i])
-		}
-	}
-	return b.String()
-}
-
 // GroupBySource groups JoinPair values by source key into a map[S][]T.
 func GroupBySource[S, T comparable](pairs []JoinPair[S, T]) map[S][]T {
 	m := make(map[S][]T)
@@ -0,0 +1,40 @@
+package orm
+
+import "sync"
+
+// keyGenerators maps keyGenKey[K]{} to the func() K registered for K.
+var keyGenerators sync.Map
+
+type keyGenKey[K comparable] struct{}
+
+// SetKeyGenerator registers gen as the source of new primary keys of type
+// K. Generated code calls GenerateKey for UUID and "generated" primary
+// keys, so registering e.g. uuid.New once at startup makes Create fill in
+// empty uuid.UUID keys:
+//
+//	orm.SetKeyGenerator(uuid.New)
+//	orm.SetKeyGenerator(uuid.NewString) // for string keys tagged "generated"
+//
+// A nil gen removes the generator for K.
+func SetKeyGenerator[K comparable](gen func() K) {
+	if gen == nil {
+		keyGenerators.Delete(keyGenKey[K]{})
+		return
+	}
+	keyGenerators.Store(keyGenKey[K]{}, gen)
+}
+
+// GenerateKey sets *k to a new key from the generator registered for K
+// with SetKeyGenerator. It leaves *k alone if it is already set or no
+// generator is registered, in which case the key is inserted as is.
+func GenerateKey[K comparable](k *K) {
+	var zero K
+	if *k != zero {
+		return
+	}
+	gen, ok := keyGenerators.Load(keyGenKey[K]{})
+	if !ok {
+		return
+	}
+	*k = gen.(func() K)() //nolint:forcetypeassert // only func() K is stored under keyGenKey[K]
+}
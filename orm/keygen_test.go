@@ -0,0 +1,107 @@
+package orm_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+type testTokenID string
+
+type testToken struct {
+	ID    testTokenID
+	Label string
+}
+
+func testTokenColValPairs(tk *testToken, _ bool) ([]string, []any) {
+	return []string{"id", "label"}, []any{tk.ID, tk.Label}
+}
+
+func newTestTokenQuery(tq *orm.TestQuerier) *orm.Query[testToken] {
+	q := orm.NewQuery[testToken](tq, "tokens", []string{"id", "label"}, "id",
+		func(*sql.Rows) (testToken, error) { return testToken{}, nil }, testTokenColValPairs, nil)
+	q.RegisterPKGenerator(func(tk *testToken) { orm.GenerateKey(&tk.ID) })
+	return q
+}
+
+func TestCreateGeneratesEmptyPK(t *testing.T) {
+	t.Parallel()
+
+	orm.SetKeyGenerator(func() testTokenID { return "generated" })
+
+	tests := []struct {
+		name   string
+		id     testTokenID
+		wantID testTokenID
+	}{
+		{"empty", "", "generated"},
+		{"set", "given", "given"},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(orm.MySQL)
+		tk := testToken{ID: tt.id, Label: "api"}
+		if err := newTestTokenQuery(tq).Create(t.Context(), &tk); err != nil {
+			t.Fatalf("%s: Create: %v", tt.name, err)
+		}
+
+		got := tq.LastQuery()
+		if want := "INSERT INTO `tokens` (`id`, `label`) VALUES (?, ?)"; got.SQL != want {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got.SQL, want)
+		}
+		if tk.ID != tt.wantID || len(got.Args) != 2 || got.Args[0] != tt.wantID {
+			t.Errorf("%s: ID = %q, Args = %v, want ID %q inserted", tt.name, tk.ID, got.Args, tt.wantID)
+		}
+	}
+}
+
+func TestCreateAllGeneratesEmptyPKs(t *testing.T) {
+	t.Parallel()
+
+	orm.SetKeyGenerator(func() testTokenID { return "generated" })
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	items := []*testToken{{Label: "a"}, {ID: "given", Label: "b"}}
+	if err := newTestTokenQuery(tq).CreateAll(t.Context(), items); err != nil {
+		t.Fatalf("CreateAll: %v", err)
+	}
+
+	if items[0].ID != "generated" || items[1].ID != "given" {
+		t.Errorf("IDs = %q, %q, want generated, given", items[0].ID, items[1].ID)
+	}
+	got := tq.LastQuery()
+	if len(got.Args) != 4 || got.Args[0] != testTokenID("generated") || got.Args[2] != testTokenID("given") {
+		t.Errorf("Args = %v, want [generated a given b]", got.Args)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	t.Parallel()
+
+	type key string
+
+	var k key
+	orm.GenerateKey(&k)
+	if k != "" {
+		t.Errorf("without a generator: k = %q, want empty", k)
+	}
+
+	orm.SetKeyGenerator(func() key { return "new" })
+	orm.GenerateKey(&k)
+	if k != "new" {
+		t.Errorf("k = %q, want new", k)
+	}
+
+	k = "kept"
+	orm.GenerateKey(&k)
+	if k != "kept" {
+		t.Errorf("k = %q, want kept", k)
+	}
+
+	orm.SetKeyGenerator[key](nil)
+	k = ""
+	orm.GenerateKey(&k)
+	if k != "" {
+		t.Errorf("after removing the generator: k = %q, want empty", k)
+	}
+}
@@ -3,8 +3,11 @@
 package orm_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -23,8 +26,13 @@ type User struct {
 var usersColumns = []string{"id", "name", "email"}
 
 func scanUser(rows *sql.Rows) (User, error) {
-	cols, _ := rows.Columns()
 	var v User
+	err := scanUserInto(rows, &v)
+	return v, err
+}
+
+func scanUserInto(rows *sql.Rows, v *User) error {
+	cols, _ := rows.Columns()
 	dest := make([]any, len(cols))
 	for i, col := range cols {
 		switch col {
@@ -38,8 +46,7 @@ func scanUser(rows *sql.Rows) (User, error) {
 			dest[i] = new(any)
 		}
 	}
-	err := rows.Scan(dest...)
-	return v, err
+	return rows.Scan(dest...)
 }
 
 func userColumnValuePairs(v *User, includesPK bool) ([]string, []any) {
@@ -56,7 +63,9 @@ func setUserPK(v *User, id int64) {
 }
 
 func Users(db orm.Querier) *orm.Query[User] {
-	return orm.NewQuery[User](db, "users", usersColumns, "id", scanUser, userColumnValuePairs, setUserPK)
+	q := orm.NewQuery[User](db, "users", usersColumns, "id", scanUser, userColumnValuePairs, setUserPK)
+	q.RegisterScanInto(scanUserInto)
+	return q
 }
 
 type dialectSetup struct {
@@ -202,6 +211,324 @@ func TestAll(t *testing.T) {
 	}
 }
 
+func TestAllPtr(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for i := range 2 {
+				u := &User{Name: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			users, err := Users(db).OrderBy("id").AllPtr(ctx)
+			if err != nil {
+				t.Fatalf("AllPtr: %v", err)
+			}
+			if len(users) != 2 {
+				t.Fatalf("len(AllPtr) = %d, want 2", len(users))
+			}
+
+			// Read-modify-write through the pointers.
+			for _, u := range users {
+				u.Name += "!"
+				if err := Users(db).Update(ctx, u); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			}
+
+			got, err := Users(db).OrderBy("id").First(ctx)
+			if err != nil {
+				t.Fatalf("First: %v", err)
+			}
+			if got.Name != "user0!" {
+				t.Errorf("Name = %q, want %q", got.Name, "user0!")
+			}
+
+			none, err := Users(db).Where("name = ?", "nobody").AllPtr(ctx)
+			if err != nil {
+				t.Fatalf("AllPtr (no rows): %v", err)
+			}
+			if len(none) != 0 {
+				t.Errorf("len(AllPtr) = %d, want 0", len(none))
+			}
+		})
+	}
+}
+
+func TestPreparedQuery(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, name := range []string{"alice", "bob", "bob"} {
+				if err := Users(db).Create(ctx, &User{Name: name, Email: name + "@example.com"}); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			byName := Users(db).Where("name = ?").OrderBy("id").Prepare()
+			for name, want := range map[string]int{"alice": 1, "bob": 2, "carol": 0} {
+				users, err := byName.All(ctx, name)
+				if err != nil {
+					t.Fatalf("All(%q): %v", name, err)
+				}
+				if len(users) != want {
+					t.Errorf("len(All(%q)) = %d, want %d", name, len(users), want)
+				}
+			}
+
+			if _, err := byName.All(ctx); err == nil {
+				t.Error("All without args: expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			u := &User{Name: "alice", Email: "alice@example.com"}
+			if err := Users(db).Create(ctx, u); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			got, err := orm.FindByID(ctx, Users(db), u.ID)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if got.Name != "alice" {
+				t.Errorf("Name = %q, want %q", got.Name, "alice")
+			}
+
+			if _, err := orm.FindByID(ctx, Users(db), u.ID+1); !errors.Is(err, orm.ErrNotFound) {
+				t.Errorf("FindByID missing: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestRaw(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, name := range []string{"alice", "bob", "carol"} {
+				if err := Users(db).Create(ctx, &User{Name: name, Email: name + "@example.com"}); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			got, err := Users(db).Where("name = ?", "alice").Raw(
+				"WITH ranked AS (SELECT id, name, email, ROW_NUMBER() OVER (ORDER BY name DESC) AS rn FROM users) "+
+					"SELECT id, name, email FROM ranked WHERE rn <= ? ORDER BY rn", 2,
+			).All(ctx)
+			if err != nil {
+				t.Fatalf("All: %v", err)
+			}
+			if len(got) != 2 || got[0].Name != "carol" || got[1].Name != "bob" {
+				t.Errorf("got %+v, want carol and bob", got)
+			}
+		})
+	}
+}
+
+func TestFirstOrCreateAndInit(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			first := &User{Name: "alice", Email: "alice@example.com"}
+			if err := Users(db).Where("email = ?", first.Email).FirstOrCreate(ctx, first); err != nil {
+				t.Fatalf("FirstOrCreate (create): %v", err)
+			}
+			if first.ID == 0 {
+				t.Fatal("ID not set by Create")
+			}
+
+			again := &User{Name: "other", Email: "alice@example.com"}
+			if err := Users(db).Where("email = ?", again.Email).FirstOrCreate(ctx, again); err != nil {
+				t.Fatalf("FirstOrCreate (find): %v", err)
+			}
+			if again.ID != first.ID || again.Name != "alice" {
+				t.Errorf("got %+v, want the stored %+v", again, first)
+			}
+
+			initd := &User{Name: "bob", Email: "bob@example.com"}
+			if err := Users(db).Where("email = ?", initd.Email).FirstOrInit(ctx, initd); err != nil {
+				t.Fatalf("FirstOrInit: %v", err)
+			}
+			if initd.ID != 0 || initd.Name != "bob" {
+				t.Errorf("FirstOrInit changed %+v", initd)
+			}
+			if n, err := Users(db).Count(ctx); err != nil || n != 1 {
+				t.Errorf("Count = %d, %v, want 1", n, err)
+			}
+		})
+	}
+}
+
+func TestAllByID(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			var ids []int
+			for _, name := range []string{"alice", "bob"} {
+				u := &User{Name: name, Email: name + "@example.com"}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				ids = append(ids, u.ID)
+			}
+
+			byID, err := orm.AllByID[int](ctx, Users(db))
+			if err != nil {
+				t.Fatalf("AllByID: %v", err)
+			}
+			if len(byID) != 2 || byID[ids[0]].Name != "alice" || byID[ids[1]].Name != "bob" {
+				t.Errorf("AllByID = %+v, want alice and bob keyed by ID", byID)
+			}
+
+			if _, err := orm.AllByID[string](ctx, Users(db)); err == nil {
+				t.Error("AllByID with the wrong key type: expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLikeScopes(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, name := range []string{"50% off", "500 off", "Alice"} {
+				u := &User{Name: name, Email: strings.ReplaceAll(name, " ", "") + "@example.com"}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			escaped, err := Users(db).Scopes(scope.Like("name", scope.EscapeLike("50%")+"%")).All(ctx)
+			if err != nil {
+				t.Fatalf("Like: %v", err)
+			}
+			if len(escaped) != 1 || escaped[0].Name != "50% off" {
+				t.Errorf("Like with escaped %% = %+v, want only %q", escaped, "50% off")
+			}
+
+			folded, err := Users(db).Scopes(scope.ILike("name", "ALI%")).All(ctx)
+			if err != nil {
+				t.Fatalf("ILike: %v", err)
+			}
+			if len(folded) != 1 || folded[0].Name != "Alice" {
+				t.Errorf("ILike = %+v, want only Alice", folded)
+			}
+		})
+	}
+}
+
+func TestPluck(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			emails, err := orm.Pluck[string](ctx, Users(db), "email")
+			if err != nil {
+				t.Fatalf("Pluck on empty table: %v", err)
+			}
+			if emails != nil {
+				t.Errorf("Pluck on empty table = %v, want nil", emails)
+			}
+
+			for _, name := range []string{"alice", "bob", "carol"} {
+				u := &User{Name: name, Email: name + "@example.com"}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			emails, err = orm.Pluck[string](ctx, Users(db).Where("name <> ?", "alice").OrderBy("name DESC").Limit(2), "email")
+			if err != nil {
+				t.Fatalf("Pluck: %v", err)
+			}
+			if len(emails) != 2 || emails[0] != "carol@example.com" || emails[1] != "bob@example.com" {
+				t.Errorf("Pluck = %v, want [carol@example.com bob@example.com]", emails)
+			}
+
+			ids, err := orm.Pluck[int64](ctx, Users(db), "users.id")
+			if err != nil {
+				t.Fatalf("Pluck ids: %v", err)
+			}
+			if len(ids) != 3 {
+				t.Errorf("len(ids) = %d, want 3", len(ids))
+			}
+		})
+	}
+}
+
+func TestOffsetWithoutLimit(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for i := range 3 {
+				u := &User{Name: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			users, err := Users(db).OrderBy("id").Offset(1).All(ctx)
+			if err != nil {
+				t.Fatalf("Offset without Limit: %v", err)
+			}
+			if len(users) != 2 || users[0].Name != "user1" {
+				t.Errorf("users = %+v, want user1 and user2", users)
+			}
+
+			none, err := Users(db).Limit(0).All(ctx)
+			if err != nil {
+				t.Fatalf("Limit(0): %v", err)
+			}
+			if len(none) != 0 {
+				t.Errorf("len(Limit(0)) = %d, want 0", len(none))
+			}
+		})
+	}
+}
+
 func TestScopes(t *testing.T) {
 	for _, ds := range dialects {
 		t.Run(ds.name, func(t *testing.T) {
@@ -331,7 +658,7 @@ func TestTransactionHelper(t *testing.T) {
 	}
 }
 
-func TestCount(t *testing.T) {
+func TestSavepoints(t *testing.T) {
 	for _, ds := range dialects {
 		t.Run(ds.name, func(t *testing.T) {
 			t.Parallel()
@@ -339,36 +666,223 @@ func TestCount(t *testing.T) {
 			db := setupDB(t, ds)
 			ctx := t.Context()
 
-			for _, name := range []string{"Alice", "Bob", "Alice2"} {
-				u := &User{Name: name, Email: name + "@example.com"}
-				if err := Users(db).Create(ctx, u); err != nil {
-					t.Fatalf("Create: %v", err)
+			ormDB, ok := db.(*orm.DB)
+			if !ok {
+				t.Fatal("expected *orm.DB")
+			}
+			exists := func(name string) bool {
+				t.Helper()
+				found, err := Users(db).Where("name = ?", name).Exists(ctx)
+				if err != nil {
+					t.Fatalf("Exists(%s): %v", name, err)
 				}
+				return found
+			}
+			create := func(tx *orm.Tx, name string) error {
+				return Users(tx).Create(ctx, &User{Name: name, Email: name + "@example.com"})
 			}
+			errInner := errors.New("inner failed")
 
-			// Count all
-			count, err := Users(db).Count(ctx)
+			// Commit: a released savepoint is committed with the outer transaction.
+			err := ormDB.Transaction(ctx, func(tx *orm.Tx) error {
+				if err := create(tx, "SpOuter"); err != nil {
+					return err
+				}
+				return tx.Transaction(ctx, func(tx *orm.Tx) error {
+					return create(tx, "SpInner")
+				})
+			})
 			if err != nil {
-				t.Fatalf("Count: %v", err)
+				t.Fatalf("Transaction commit: %v", err)
 			}
-			if count != 3 {
-				t.Errorf("Count = %d, want 3", count)
+			if !exists("SpOuter") || !exists("SpInner") {
+				t.Error("commit: expected both rows")
 			}
 
-			// Count with Where
-			count, err = Users(db).Where("name LIKE ?", "Alice%").Count(ctx)
+			// Rollback to savepoint: only the failed nested call is undone.
+			err = ormDB.Transaction(ctx, func(tx *orm.Tx) error {
+				if err := create(tx, "SpKept"); err != nil {
+					return err
+				}
+				err := tx.Transaction(ctx, func(tx *orm.Tx) error {
+					if err := create(tx, "SpUndone"); err != nil {
+						return err
+					}
+					return errInner
+				})
+				if !errors.Is(err, errInner) {
+					t.Errorf("nested Transaction err = %v, want errInner", err)
+				}
+
+				if err := tx.Savepoint(ctx, "manual"); err != nil {
+					return err
+				}
+				if err := create(tx, "SpManual"); err != nil {
+					return err
+				}
+				return tx.RollbackTo(ctx, "manual")
+			})
 			if err != nil {
-				t.Fatalf("Count where: %v", err)
+				t.Fatalf("Transaction rollback to savepoint: %v", err)
 			}
-			if count != 2 {
-				t.Errorf("Count where = %d, want 2", count)
+			if !exists("SpKept") || exists("SpUndone") || exists("SpManual") {
+				t.Error("rollback to savepoint: want only SpKept")
 			}
-		})
-	}
-}
 
-func TestExists(t *testing.T) {
-	for _, ds := range dialects {
+			// Full rollback: the outer failure undoes a released savepoint too.
+			err = ormDB.Transaction(ctx, func(tx *orm.Tx) error {
+				if err := tx.Transaction(ctx, func(tx *orm.Tx) error {
+					return create(tx, "SpReleased")
+				}); err != nil {
+					return err
+				}
+				return errInner
+			})
+			if !errors.Is(err, errInner) {
+				t.Fatalf("Transaction full rollback err = %v, want errInner", err)
+			}
+			if exists("SpReleased") {
+				t.Error("full rollback: SpReleased should be rolled back")
+			}
+		})
+	}
+}
+
+func TestReadOnlyTransaction(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			ormDB, ok := db.(*orm.DB)
+			if !ok {
+				t.Fatal("expected *orm.DB")
+			}
+			if err := Users(db).Create(ctx, &User{Name: "Reader", Email: "reader@example.com"}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			err := ormDB.ReadOnlyTransaction(ctx, func(tx *orm.Tx) error {
+				n, err := Users(tx).Count(ctx)
+				if err != nil {
+					return err
+				}
+				if n != 1 {
+					t.Errorf("Count = %d, want 1", n)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ReadOnlyTransaction read: %v", err)
+			}
+
+			err = ormDB.ReadOnlyTransaction(ctx, func(tx *orm.Tx) error {
+				return Users(tx).Create(ctx, &User{Name: "Writer", Email: "writer@example.com"})
+			})
+			if !errors.Is(err, orm.ErrReadOnly) {
+				t.Fatalf("expected ErrReadOnly, got %v", err)
+			}
+
+			// The database itself also rejects raw writes.
+			err = ormDB.ReadOnlyTransaction(ctx, func(tx *orm.Tx) error {
+				_, err := tx.ExecContext(ctx, "DELETE FROM users")
+				return err
+			})
+			if err == nil {
+				t.Error("expected raw write in read-only transaction to fail")
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, name := range []string{"Alice", "Bob", "Alice2"} {
+				u := &User{Name: name, Email: name + "@example.com"}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			// Count all
+			count, err := Users(db).Count(ctx)
+			if err != nil {
+				t.Fatalf("Count: %v", err)
+			}
+			if count != 3 {
+				t.Errorf("Count = %d, want 3", count)
+			}
+
+			// Count with Where
+			count, err = Users(db).Where("name LIKE ?", "Alice%").Count(ctx)
+			if err != nil {
+				t.Fatalf("Count where: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Count where = %d, want 2", count)
+			}
+		})
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			sum, err := Users(db).Sum(ctx, "id")
+			if err != nil {
+				t.Fatalf("Sum on empty table: %v", err)
+			}
+			if sum.Valid {
+				t.Errorf("Sum on empty table = %v, want NULL", sum)
+			}
+
+			var ids []int
+			for _, name := range []string{"alice", "bob"} {
+				u := &User{Name: name, Email: name + "@example.com"}
+				if err := Users(db).Create(ctx, u); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				ids = append(ids, u.ID)
+			}
+
+			checks := []struct {
+				name string
+				fn   func(context.Context, string) (sql.NullFloat64, error)
+				want float64
+			}{
+				{"Sum", Users(db).Sum, float64(ids[0] + ids[1])},
+				{"Avg", Users(db).Avg, float64(ids[0]+ids[1]) / 2},
+				{"Min", Users(db).Min, float64(ids[0])},
+				{"Max", Users(db).Max, float64(ids[1])},
+			}
+			for _, c := range checks {
+				got, err := c.fn(ctx, "id")
+				if err != nil {
+					t.Fatalf("%s: %v", c.name, err)
+				}
+				if !got.Valid || got.Float64 != c.want {
+					t.Errorf("%s = %v, want %v", c.name, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	for _, ds := range dialects {
 		t.Run(ds.name, func(t *testing.T) {
 			t.Parallel()
 
@@ -440,6 +954,80 @@ func TestCreateAll(t *testing.T) {
 	}
 }
 
+func TestCreateAllChunked(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			// Two columns per row, so 4 parameters make chunks of 2 rows.
+			users := make([]*User, 5)
+			for i := range users {
+				name := fmt.Sprintf("user%d", i)
+				users[i] = &User{Name: name, Email: name + "@example.com"}
+			}
+			if err := Users(db).MaxBatchParams(4).CreateAll(ctx, users); err != nil {
+				t.Fatalf("CreateAll: %v", err)
+			}
+
+			for _, u := range users {
+				got, err := Users(db).Where("id = ?", u.ID).First(ctx)
+				if err != nil {
+					t.Fatalf("First(%d): %v", u.ID, err)
+				}
+				if got.Name != u.Name {
+					t.Errorf("row %d: Name = %q, want %q", u.ID, got.Name, u.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertIgnore(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			u := &User{Name: "alice", Email: "alice@example.com"}
+			if err := Users(db).Create(ctx, u); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			// Users omits the database-assigned ID from INSERT, so conflict on it
+			// through a query without setPK, which inserts the ID as given.
+			dup := &User{ID: u.ID, Name: "bob", Email: "bob@example.com"}
+			keyed := orm.NewQuery[User](db, "users", usersColumns, "id", scanUser, userColumnValuePairs, nil)
+			if err := keyed.InsertIgnore(ctx, dup); err != nil {
+				t.Fatalf("InsertIgnore (duplicate): %v", err)
+			}
+
+			got, err := Users(db).Where("id = ?", u.ID).First(ctx)
+			if err != nil {
+				t.Fatalf("First: %v", err)
+			}
+			if got.Name != "alice" {
+				t.Errorf("Name = %q, want the existing row kept", got.Name)
+			}
+
+			fresh := &User{Name: "carol", Email: "carol@example.com"}
+			if err := Users(db).InsertIgnore(ctx, fresh); err != nil {
+				t.Fatalf("InsertIgnore (new): %v", err)
+			}
+			if fresh.ID == 0 {
+				t.Error("ID not set for an inserted row")
+			}
+			if n, err := Users(db).Count(ctx); err != nil || n != 2 {
+				t.Errorf("Count = %d, %v, want 2", n, err)
+			}
+		})
+	}
+}
+
 func TestUpsert(t *testing.T) {
 	for _, ds := range dialects {
 		t.Run(ds.name, func(t *testing.T) {
@@ -484,3 +1072,261 @@ func TestUpsert(t *testing.T) {
 		})
 	}
 }
+
+func TestExistingIDs(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			users := []*User{
+				{Name: "Alice", Email: "alice@example.com"},
+				{Name: "Bob", Email: "bob@example.com"},
+			}
+			if err := Users(db).CreateAll(ctx, users); err != nil {
+				t.Fatalf("CreateAll: %v", err)
+			}
+
+			missing := users[1].ID + 1000
+			ids, err := orm.ExistingIDs(ctx, Users(db), []int{users[0].ID, missing, users[1].ID})
+			if err != nil {
+				t.Fatalf("ExistingIDs: %v", err)
+			}
+			if len(ids) != 2 {
+				t.Fatalf("ExistingIDs = %v, want 2 ids", ids)
+			}
+			for _, id := range ids {
+				if id == missing {
+					t.Errorf("ExistingIDs returned missing id %d", id)
+				}
+			}
+
+			// WHERE clauses narrow the check.
+			ids, err = orm.ExistingIDs(ctx, Users(db).Where("name = ?", "Bob"), []int{users[0].ID, users[1].ID})
+			if err != nil {
+				t.Fatalf("ExistingIDs with Where: %v", err)
+			}
+			if len(ids) != 1 || ids[0] != users[1].ID {
+				t.Errorf("ExistingIDs with Where = %v, want [%d]", ids, users[1].ID)
+			}
+		})
+	}
+}
+
+func TestUpsertOnConflictUpdateWhere(t *testing.T) {
+	for _, ds := range dialects {
+		if ds.dialect != orm.PostgreSQL {
+			continue
+		}
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			u := &User{Name: "Alice", Email: "alice@example.com"}
+			if err := Users(db).Create(ctx, u); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			// Only overwrite when the stored email differs from the guard value.
+			guarded := Users(db).OnConflictUpdateWhere(`"users"."email" <> ?`, "alice@example.com")
+			if err := guarded.Upsert(ctx, &User{ID: u.ID, Name: "Skipped", Email: "new@example.com"}); err != nil {
+				t.Fatalf("Upsert (skipped): %v", err)
+			}
+			got, err := Users(db).Where("id = ?", u.ID).First(ctx)
+			if err != nil {
+				t.Fatalf("First: %v", err)
+			}
+			if got.Name != "Alice" {
+				t.Errorf("Name = %q, want %q (update should be skipped)", got.Name, "Alice")
+			}
+
+			guarded = Users(db).OnConflictUpdateWhere(`"users"."email" = ?`, "alice@example.com")
+			if err := guarded.Upsert(ctx, &User{ID: u.ID, Name: "Updated", Email: "alice@example.com"}); err != nil {
+				t.Fatalf("Upsert (applied): %v", err)
+			}
+			got, err = Users(db).Where("id = ?", u.ID).First(ctx)
+			if err != nil {
+				t.Fatalf("First: %v", err)
+			}
+			if got.Name != "Updated" {
+				t.Errorf("Name = %q, want %q", got.Name, "Updated")
+			}
+		})
+	}
+}
+
+func TestCreateResult(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			u := &User{Name: "Alice", Email: "alice@example.com"}
+			result, err := Users(db).CreateResult(ctx, u)
+			if err != nil {
+				t.Fatalf("CreateResult: %v", err)
+			}
+			if u.ID == 0 {
+				t.Error("expected ID to be set after CreateResult")
+			}
+
+			if ds.dialect.UseReturning() {
+				if result != nil {
+					t.Errorf("result = %v, want nil on the RETURNING path", result)
+				}
+				return
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				t.Fatalf("RowsAffected: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("RowsAffected = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestCreateReturning(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			u := &User{Name: "Alice", Email: "alice@example.com"}
+			if err := Users(db).CreateReturning(ctx, u, "name"); err != nil {
+				t.Fatalf("CreateReturning: %v", err)
+			}
+			if u.ID == 0 {
+				t.Error("expected ID to be set after CreateReturning")
+			}
+			if u.Name != "Alice" || u.Email != "alice@example.com" {
+				t.Errorf("got %+v, want name read back and email untouched", u)
+			}
+		})
+	}
+}
+
+func TestExplainPlans(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			q := Users(db).Where("name = ?", "Alice")
+			for name, explain := range map[string]func(context.Context) ([]string, error){
+				"Explain": q.Explain, "ExplainAnalyze": q.ExplainAnalyze,
+			} {
+				plan, err := explain(ctx)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if len(plan) == 0 {
+					t.Errorf("%s: empty plan", name)
+				}
+			}
+		})
+	}
+}
+
+// TestExtraColumnsDoNotBreakReads guards forward compatibility: a column
+// present in the table but not in the struct must never break reads, whether
+// it is selected explicitly ("*") or left out by SelectAll.
+func TestExtraColumnsDoNotBreakReads(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			sqlDB, err := sql.Open(ds.driver, ds.dsn)
+			if err != nil {
+				t.Fatalf("open %s: %v", ds.name, err)
+			}
+			t.Cleanup(func() { _ = sqlDB.Close() })
+
+			if _, err := sqlDB.Exec("DROP TABLE IF EXISTS drift_users"); err != nil {
+				t.Fatalf("drop: %v", err)
+			}
+			ddl := strings.Replace(ds.createTable, "IF NOT EXISTS users", "drift_users", 1)
+			ddl = strings.Replace(ddl, "email VARCHAR(255) NOT NULL", "email VARCHAR(255) NOT NULL,\n\t\t\tnickname VARCHAR(255) NOT NULL DEFAULT 'x'", 1)
+			if _, err := sqlDB.Exec(ddl); err != nil {
+				t.Fatalf("create drift_users: %v", err)
+			}
+
+			db := orm.New(sqlDB, ds.dialect)
+			driftUsers := orm.NewQuery[User](db, "drift_users", usersColumns, "id", scanUser, userColumnValuePairs, setUserPK)
+			ctx := t.Context()
+
+			if err := driftUsers.Create(ctx, &User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			for name, q := range map[string]*orm.Query[User]{
+				"Select(*)": driftUsers.Select("*"),
+				"SelectAll": driftUsers.Select("*").SelectAll(),
+			} {
+				users, err := q.All(ctx)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if len(users) != 1 || users[0].Name != "Alice" {
+					t.Errorf("%s = %+v, want Alice", name, users)
+				}
+			}
+		})
+	}
+}
+
+func TestScanRow(t *testing.T) {
+	for _, ds := range dialects {
+		t.Run(ds.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := setupDB(t, ds)
+			ctx := t.Context()
+
+			for _, name := range []string{"Alice", "Alice", "Bob"} {
+				if err := Users(db).Create(ctx, &User{Name: name, Email: name + "@example.com"}); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			type nameCount struct {
+				Name  string
+				Count int64
+			}
+
+			rows, err := db.QueryContext(ctx, "SELECT name, COUNT(*) FROM users GROUP BY name ORDER BY name")
+			if err != nil {
+				t.Fatalf("QueryContext: %v", err)
+			}
+			defer func() { _ = rows.Close() }()
+
+			var got []nameCount
+			for rows.Next() {
+				r, err := orm.ScanRow(rows, func(r *nameCount) []any { return []any{&r.Name, &r.Count} })
+				if err != nil {
+					t.Fatalf("ScanRow: %v", err)
+				}
+				got = append(got, r)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("rows: %v", err)
+			}
+
+			want := []nameCount{{"Alice", 2}, {"Bob", 1}}
+			if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
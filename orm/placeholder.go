@@ -0,0 +1,94 @@
+package orm
+
+import "strings"
+
+// rewritePlaceholders converts ? to dialect-specific placeholders ($1, $2, …).
+// A ? inside a single-quoted string literal (including doubled-quote
+// escapes) or a dollar-quoted body ($$...$$ or $tag$...$tag$) is literal
+// text and is left untouched.
+// For MySQL this is a no-op.
+func rewritePlaceholders(d Dialect, query string) string {
+	if _, ok := d.(mysqlDialect); ok {
+		return query
+	}
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query))
+	idx := 1
+	walkPlaceholders(query, func(text string) { b.WriteString(text) }, func() {
+		b.WriteString(d.Placeholder(idx))
+		idx++
+	})
+	return b.String()
+}
+
+// countPlaceholders returns the number of ? placeholders in query, skipping
+// the same literals as rewritePlaceholders.
+func countPlaceholders(query string) int {
+	n := 0
+	walkPlaceholders(query, func(string) {}, func() { n++ })
+	return n
+}
+
+// walkPlaceholders splits query into literal text and ? placeholders, in
+// order. Quoted spans are passed to text whole.
+func walkPlaceholders(query string, text func(string), placeholder func()) {
+	start := 0
+	for i := 0; i < len(query); {
+		n := 1
+		switch query[i] {
+		case '?':
+			text(query[start:i])
+			placeholder()
+			i++
+			start = i
+			continue
+		case '\'':
+			// A doubled '' inside a literal ends one span and starts the
+			// next, so escaped quotes need no special handling.
+			n = quotedLen(query[i:], "'")
+		case '$':
+			if tag := dollarTag(query[i:]); tag != "" && (i == 0 || !isIdentByte(query[i-1])) {
+				n = quotedLen(query[i:], tag)
+			}
+		}
+		i += n
+	}
+	text(query[start:])
+}
+
+// quotedLen returns the length of the span of s that opens with delim and
+// runs through its closing delim. An unterminated span runs to the end of s.
+func quotedLen(s, delim string) int {
+	end := strings.Index(s[len(delim):], delim)
+	if end < 0 {
+		return len(s)
+	}
+	return len(delim) + end + len(delim)
+}
+
+// dollarTag returns the dollar-quote delimiter ($$ or $tag$) at the start of
+// s, or "" if s does not start with one. Positional parameters such as $1
+// are not tags because a tag cannot start with a digit.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[:j+1]
+		case c >= '0' && c <= '9':
+			if j == 1 {
+				return ""
+			}
+		case !isIdentByte(c):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
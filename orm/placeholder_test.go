@@ -0,0 +1,50 @@
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+func TestRewritePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no placeholders", "SELECT 1", "SELECT 1"},
+		{"sequential", "a = ? AND b = ?", "a = $1 AND b = $2"},
+		{"literal", "note = '?' AND id = ?", "note = '?' AND id = $1"},
+		{"escaped quote in literal", "note = 'it''s ?' AND id = ?", "note = 'it''s ?' AND id = $1"},
+		{"empty literal", "note = '' AND id = ?", "note = '' AND id = $1"},
+		{"adjacent literals", "a = '?' || '?' AND b = ?", "a = '?' || '?' AND b = $1"},
+		{"unterminated literal", "a = ? AND b = 'oops ?", "a = $1 AND b = 'oops ?"},
+		{"dollar quoted", "body = $$what?$$ AND id = ?", "body = $$what?$$ AND id = $1"},
+		{"tagged dollar quote", "body = $fn$ a ? $$ b ? $fn$ AND id = ?", "body = $fn$ a ? $$ b ? $fn$ AND id = $1"},
+		{"quote inside dollar body", "body = $$it's ?$$ AND id = ?", "body = $$it's ?$$ AND id = $1"},
+		{"unterminated dollar quote", "id = ? AND body = $$ ?", "id = $1 AND body = $$ ?"},
+		{"positional parameter is not a tag", "a = $1 AND b = ?", "a = $1 AND b = $1"},
+		{"dollar inside identifier", "a$b = ? AND c$ = ?", "a$b = $1 AND c$ = $2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := orm.RewritePlaceholders(orm.PostgreSQL, tt.query); got != tt.want {
+				t.Errorf("RewritePlaceholders(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewritePlaceholdersMySQLNoop(t *testing.T) {
+	t.Parallel()
+
+	query := "note = '?' AND id = ?"
+	if got := orm.RewritePlaceholders(orm.MySQL, query); got != query {
+		t.Errorf("RewritePlaceholders(%q) = %q, want unchanged", query, got)
+	}
+}
@@ -0,0 +1,129 @@
+package orm
+
+import "slices"
+
+// PreloadStrategy selects how Preload loads relations.
+type PreloadStrategy int
+
+const (
+	// PreloadSeparate loads every preloaded relation with one extra query
+	// per relation after the main query. It is the default.
+	PreloadSeparate PreloadStrategy = iota
+
+	// PreloadJoin loads to-one relations (belongs_to, has_one) that support
+	// join scans with a LEFT JOIN in the main query, saving a round trip per
+	// relation. To-many relations, relations whose target is the queried
+	// table or is already joined, and queries with a Select override still
+	// use a separate query.
+	//
+	// A has_one relation with more than one matching row repeats the parent
+	// row once per match, just as an explicit LeftJoin would.
+	//
+	// JoinPreload joins a single relation, including has_many, whatever the
+	// strategy.
+	PreloadJoin
+)
+
+// JoinPreload is like Preload but loads the relation with a LEFT JOIN in
+// the main query whatever the preload strategy, saving a round trip. For a
+// has_many relation the joined rows are grouped into the parent's slice by
+// primary key, and a parent without children gets an empty slice.
+//
+// Every parent row is repeated once per child, so this suits small result
+// sets. A has_many relation falls back to a separate query when the query
+// has a Limit or Offset, which would cut off children, or already joins
+// another to-many relation, which would repeat them.
+func (q *Query[T]) JoinPreload(name string) *Query[T] {
+	q2 := q.Preload(name)
+	q2.joinPreloadNames = append(q2.joinPreloadNames, name)
+	return q2
+}
+
+// PreloadStrategy overrides the preload strategy of the Querier for this
+// query.
+func (q *Query[T]) PreloadStrategy(s PreloadStrategy) *Query[T] {
+	q2 := q.clone()
+	q2.preloadWith = &s
+	return q2
+}
+
+func (q *Query[T]) resolvePreloadStrategy() PreloadStrategy {
+	if q.preloadWith != nil {
+		return *q.preloadWith
+	}
+	return q.db.preloadStrategy()
+}
+
+// joinPreloads returns q with preloads that the PreloadJoin strategy or
+// JoinPreload can satisfy turned into LEFT JOINs and removed from the
+// preload list. When nothing can be joined, q is returned as is.
+func (q *Query[T]) joinPreloads() *Query[T] {
+	if len(q.preloads) == 0 || q.selects != nil {
+		return q
+	}
+	strategy := q.resolvePreloadStrategy()
+	if strategy != PreloadJoin && len(q.joinPreloadNames) == 0 {
+		return q
+	}
+
+	q2 := q.clone()
+	q2.preloads = q2.preloads[:0]
+	for _, name := range q.preloads {
+		if slices.Contains(q2.activeJoinNames, name) && q2.mergers[name] == nil {
+			continue // already joined, so already join-scanned
+		}
+		forced := slices.Contains(q.joinPreloadNames, name)
+		if (forced || strategy == PreloadJoin) && q2.canJoinPreload(name, forced) {
+			q2.applyJoin("LEFT JOIN", name)
+			if q2.mergers[name] != nil {
+				q2.joinedMany = name
+			}
+			continue
+		}
+		q2.preloads = append(q2.preloads, name)
+	}
+	return q2
+}
+
+// canJoinPreload reports whether the named relation can be loaded by a
+// LEFT JOIN on this query without ambiguous table references. To-many
+// relations are only joined when forced by JoinPreload and the repeated
+// parent rows can be merged back.
+func (q *Query[T]) canJoinPreload(name string, forced bool) bool {
+	cfg, ok := q.joinDefs[name]
+	if !ok || len(cfg.SelectColumns) == 0 || cfg.TargetTable == q.table {
+		return false
+	}
+	toMany := q.mergers[name] != nil
+	if toMany && (!forced || q.pk == "" || q.limit != nil || q.offset != nil) {
+		return false
+	}
+	for _, active := range q.activeJoinNames {
+		if q.joinDefs[active].TargetTable == cfg.TargetTable {
+			return false
+		}
+		if toMany && q.mergers[active] != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeJoined folds the rows that repeat a parent, one per child of the
+// to-many relation joined by JoinPreload, into one row per primary key,
+// keeping the order in which parents first appear.
+func (q *Query[T]) mergeJoined(rows []T) []T {
+	merge := q.mergers[q.joinedMany]
+	seen := make(map[any]int, len(rows))
+	merged := rows[:0]
+	for i := range rows {
+		key := q.pkValue(&rows[i])
+		if j, ok := seen[key]; ok {
+			merge(&merged[j], &rows[i])
+			continue
+		}
+		seen[key] = len(merged)
+		merged = append(merged, rows[i])
+	}
+	return merged
+}
@@ -0,0 +1,106 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PreparedQuery is a SELECT built once and executed many times with
+// different arguments. It is created by Query.Prepare and is safe for
+// concurrent use.
+type PreparedQuery[T any] struct {
+	q     *Query[T]
+	query string
+	args  []any // bindSlot entries are filled by the arguments to All
+	binds int
+}
+
+// bindSlot marks an argument that is supplied when a PreparedQuery runs.
+type bindSlot struct{}
+
+// Prepare builds the SELECT for q once. Every ? in a Where or Having
+// clause, or in the Raw query, that was given no args becomes a slot bound
+// on each execution, in statement order: Where clauses before Having ones.
+// Clauses given args keep them. The SQL text never changes between runs,
+// so drivers that cache statements by text reuse the server-side statement.
+//
+//	byStatus := query.Users(db).Where("status = ? AND created_at > ?").Prepare()
+//	active, err := byStatus.All(ctx, "active", since)
+func (q *Query[T]) Prepare() *PreparedQuery[T] {
+	if q.raw != nil {
+		q2 := q.clone()
+		raw := *q.raw
+		binds := openSlots(&raw)
+		q2.raw = &raw
+		query, args := q2.rewrite(raw.clause, raw.args)
+		return &PreparedQuery[T]{q: q2, query: query, args: args, binds: binds}
+	}
+
+	q2 := q.joinPreloads().clone()
+	binds := 0
+	for i := range q2.wheres {
+		binds += openSlots(&q2.wheres[i])
+	}
+	for i := range q2.havings {
+		binds += openSlots(&q2.havings[i])
+	}
+
+	query, args := q2.buildSelect()
+	query, args = q2.rewrite(query, args)
+	return &PreparedQuery[T]{q: q2, query: query, args: args, binds: binds}
+}
+
+// openSlots gives a clause without args a bindSlot for each of its
+// placeholders and returns their number.
+func openSlots(c *whereClause) int {
+	if len(c.args) > 0 {
+		return 0
+	}
+	n := countPlaceholders(c.clause)
+	slots := make([]any, n)
+	for j := range slots {
+		slots[j] = bindSlot{}
+	}
+	c.args = slots
+	return n
+}
+
+// All executes the prepared SELECT with args bound to its open placeholders
+// and returns all matching rows. It returns an error without querying when
+// len(args) does not match the number of open placeholders, or when ctx
+// carries default scopes (see WithDefaultScopes) the query did not opt out
+// of. A Raw query ignores default scopes, as Query.All does.
+func (p *PreparedQuery[T]) All(ctx context.Context, args ...any) ([]T, error) {
+	if p.q.raw == nil && !p.q.noDefaultScopes && len(defaultScopesFromContext(ctx)) > 0 {
+		return nil, errors.New("orm: prepared query cannot apply the default scopes in the context; " +
+			"apply them before Prepare and call WithoutDefaultScopes")
+	}
+	bound, err := p.bind(args)
+	if err != nil {
+		return nil, err
+	}
+	return p.q.fetch(ctx, p.query, bound)
+}
+
+// SQL returns the prepared statement text.
+func (p *PreparedQuery[T]) SQL() string {
+	return p.query
+}
+
+func (p *PreparedQuery[T]) bind(args []any) ([]any, error) {
+	if len(args) != p.binds {
+		return nil, fmt.Errorf("orm: prepared query expects %d args, got %d", p.binds, len(args))
+	}
+	bound := make([]any, len(p.args))
+	next := 0
+	for i, a := range p.args {
+		if _, ok := a.(bindSlot); ok {
+			bound[i] = args[next]
+			next++
+			continue
+		}
+		bound[i] = a
+	}
+	return bound, nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,6 +17,10 @@ import (
 // Generated per-type by ormgen.
 type ScanFunc[T any] func(rows *sql.Rows) (T, error)
 
+// ScanIntoFunc scans the current row into an existing *T, leaving fields
+// whose columns are not in the row untouched. Generated per-type by ormgen.
+type ScanIntoFunc[T any] func(rows *sql.Rows, t *T) error
+
 // ColumnValueFunc extracts column names and their values from a *T.
 // When includesPK is false the primary key column is excluded (for INSERT
 // with auto-increment).
@@ -24,6 +30,10 @@ type ColumnValueFunc[T any] func(t *T, includesPK bool) (columns []string, value
 // May be nil when the primary key is not auto-generated.
 type SetPKFunc[T any] func(t *T, id int64)
 
+// PKFunc returns the primary key value of a model.
+// Generated per-model by ormgen.
+type PKFunc[T any] func(t *T) any
+
 // SetCreatedAtFunc sets the createdAt timestamp on *T.
 // The implementation should only set the field if its current value is zero.
 // Generated per-type by ormgen; nil when no createdAt field exists.
@@ -33,6 +43,30 @@ type SetCreatedAtFunc[T any] func(t *T, now time.Time)
 // Generated per-type by ormgen; nil when no updatedAt field exists.
 type SetUpdatedAtFunc[T any] func(t *T, now time.Time)
 
+// ServerTimestampsFunc returns pointers to the server-managed timestamp
+// fields of *T, in the order of the columns passed to
+// RegisterServerTimestamps, for scanning values read back via RETURNING.
+// Generated per-type by ormgen; nil when no field is tagged "server".
+type ServerTimestampsFunc[T any] func(t *T) []any
+
+// BumpVersionFunc increments the version field of *T after a successful
+// Update. Generated per-type by ormgen; nil when no field is tagged "version".
+type BumpVersionFunc[T any] func(t *T)
+
+// ValidateFunc checks *T before it is written. Generated per-type by ormgen
+// when a field is tagged with enum values; nil otherwise.
+type ValidateFunc[T any] func(t *T) error
+
+// GeneratePKFunc assigns a client-generated primary key to *T before INSERT.
+// The implementation should only set the field if its current value is zero.
+// Generated per-type by ormgen for UUID and "generated" primary keys.
+type GeneratePKFunc[T any] func(t *T)
+
+// MergeFunc appends the to-many relation that a join scan loaded into src
+// to the same relation on dst, folding the rows JoinPreload gets for one
+// parent into one value. Generated per has_many relation by ormgen.
+type MergeFunc[T any] func(dst, src *T)
+
 // PreloaderFunc executes a preload query and assigns results to the parent slice.
 // Generated per-relation by ormgen.
 type PreloaderFunc[T any] func(ctx context.Context, db Querier, results []T) error
@@ -54,27 +88,76 @@ type Query[T any] struct {
 	columns     []string
 	pk          string
 	scan        ScanFunc[T]
+	scanInto    ScanIntoFunc[T]
 	colValPairs ColumnValueFunc[T]
 	setPK       SetPKFunc[T]
+	getPK       PKFunc[T]
+	pkCols      []string // composite primary key; pk is empty when set
 
 	wheres   []whereClause
+	groupBys []string
+	havings  []whereClause
 	orderBys []string
 	joins    []string
 	selects  *string
+	selCols  []string // columns passed to SelectColumns, qualified once joined
+	distinct bool
+	raw      *whereClause // set by Raw; replaces the built SELECT in All
+	hints    []string
 	limit    *int
 	offset   *int
-
-	joinDefs       map[string]JoinConfig
-	activeJoinNames []string
-	preloaders     map[string]PreloaderFunc[T]
-	preloads       []string
+	lock     lockMode
+
+	joinDefs         map[string]JoinConfig
+	activeJoinNames  []string
+	preloaders       map[string]PreloaderFunc[T]
+	preloads         []string
+	preloadWith      *PreloadStrategy
+	mergers          map[string]MergeFunc[T]
+	joinPreloadNames []string // relations JoinPreload loads with a LEFT JOIN
+	joinedMany       string   // to-many relation joined by joinPreloads, if any
+	shardKey         *ShardKey
+
+	upsertWhere    *whereClause
+	conflictCols   []string // Upsert conflict target; nil means the primary key
+	safePKs        bool
+	maxBatchParams int // 0 means the dialect's MaxParams
 
 	createdAtCols []string
 	updatedAtCols []string
 	setCreatedAt  SetCreatedAtFunc[T]
 	setUpdatedAt  SetUpdatedAtFunc[T]
+
+	serverTimestampCols []string
+	serverTimestamps    ServerTimestampsFunc[T]
+
+	immutableCols []string // written by INSERT, skipped by UPDATE
+
+	softDeleteCol string
+	unscoped      bool
+
+	noDefaultScopes bool // set by WithoutDefaultScopes
+
+	versionCol  string
+	bumpVersion BumpVersionFunc[T]
+
+	generatePK GeneratePKFunc[T]
+	validator  ValidateFunc[T]
+
+	readOnly bool
+
+	err error // first error deferred by a scope, returned by terminal methods
 }
 
+// lockMode is the row lock a SELECT takes.
+type lockMode int
+
+const (
+	lockNone lockMode = iota
+	lockForUpdate
+	lockForUpdateSkipLocked
+)
+
 type whereClause struct {
 	clause string
 	args   []any
@@ -117,6 +200,36 @@ func (q *Query[T]) RegisterPreloader(name string, fn PreloaderFunc[T]) {
 	q.preloaders[name] = fn
 }
 
+// RegisterMerge registers the merge function of a to-many relation, which
+// lets JoinPreload load it with a LEFT JOIN.
+func (q *Query[T]) RegisterMerge(name string, fn MergeFunc[T]) {
+	if q.mergers == nil {
+		q.mergers = make(map[string]MergeFunc[T])
+	}
+	q.mergers[name] = fn
+}
+
+// RegisterPK registers an accessor for the primary key value, used by
+// AllByID. Without one, the key is looked up among the column values.
+func (q *Query[T]) RegisterPK(fn PKFunc[T]) {
+	q.getPK = fn
+}
+
+// RegisterCompositePK declares a primary key spanning columns, e.g.
+// (tenant_id, id). Update and Upsert then match on all of them; primary
+// keys are never assigned from the database, and AllByID and ExistingIDs,
+// which need a single key, return an error.
+func (q *Query[T]) RegisterCompositePK(columns ...string) {
+	q.pk = ""
+	q.pkCols = columns
+}
+
+// RegisterReadOnly marks the model as read-only: write methods return
+// ErrReadOnlyModel without querying.
+func (q *Query[T]) RegisterReadOnly() {
+	q.readOnly = true
+}
+
 // RegisterTimestamps configures automatic timestamp management.
 func (q *Query[T]) RegisterTimestamps(
 	createdAtCols []string, setCreatedAt SetCreatedAtFunc[T],
@@ -128,17 +241,92 @@ func (q *Query[T]) RegisterTimestamps(
 	q.setUpdatedAt = setUpdatedAt
 }
 
+// RegisterServerTimestamps configures timestamp columns set by the database
+// (e.g. DEFAULT now()) rather than the Clock. They are left out of INSERT
+// and UPDATE statements; with a dialect that uses RETURNING, inserts read
+// them back into the fields returned by dest.
+func (q *Query[T]) RegisterServerTimestamps(columns []string, dest ServerTimestampsFunc[T]) {
+	q.serverTimestampCols = columns
+	q.serverTimestamps = dest
+}
+
+// RegisterValidator registers a check that Create, CreateAll, Upsert,
+// InsertIgnore and Update run on each struct before any statement, e.g.
+// the enum check generated for tagged fields. UpdateColumns, which may be
+// given a partly filled struct, and Updates, which writes raw values, do
+// not run it.
+func (q *Query[T]) RegisterValidator(fn ValidateFunc[T]) {
+	q.validator = fn
+}
+
+// RegisterScanInto registers a scanner that fills an existing *T, used by
+// CreateReturning to read back only the requested columns.
+func (q *Query[T]) RegisterScanInto(fn ScanIntoFunc[T]) {
+	q.scanInto = fn
+}
+
+// RegisterImmutable configures columns that are set once by INSERT:
+// Update leaves them out of its SET list, and so does Upsert's update on
+// conflict. Updates still writes them when named explicitly.
+func (q *Query[T]) RegisterImmutable(columns []string) {
+	q.immutableCols = columns
+}
+
+// RegisterVersion enables optimistic locking on column, an integer that
+// Update increments and requires to still hold the struct's value:
+// a concurrent change makes Update fail with ErrStaleObject. bump
+// increments the field on *T after a successful Update.
+func (q *Query[T]) RegisterVersion(column string, bump BumpVersionFunc[T]) {
+	q.versionCol = column
+	q.bumpVersion = bump
+}
+
+// RegisterPKGenerator makes Create and CreateAll call generate on each
+// value before inserting it, so that a primary key left at its zero value
+// is filled in by the client, e.g. with a UUID.
+func (q *Query[T]) RegisterPKGenerator(generate GeneratePKFunc[T]) {
+	q.generatePK = generate
+}
+
+// RegisterSoftDelete makes column, a nullable timestamp, mark deleted rows:
+// Delete sets it instead of removing the row, and queries skip rows where
+// it is set unless Unscoped is used.
+func (q *Query[T]) RegisterSoftDelete(column string) {
+	q.softDeleteCol = column
+}
+
 // clone returns a shallow copy with slices copied to avoid aliasing.
+// Pointer fields such as limit, offset and selects stay shared: builder
+// methods always replace them with a fresh pointer and never write through
+// one, so sharing them is safe.
 func (q *Query[T]) clone() *Query[T] {
 	q2 := *q
 	q2.wheres = append([]whereClause(nil), q.wheres...)
+	q2.groupBys = append([]string(nil), q.groupBys...)
+	q2.havings = append([]whereClause(nil), q.havings...)
 	q2.orderBys = append([]string(nil), q.orderBys...)
 	q2.joins = append([]string(nil), q.joins...)
+	q2.hints = append([]string(nil), q.hints...)
 	q2.activeJoinNames = append([]string(nil), q.activeJoinNames...)
 	q2.preloads = append([]string(nil), q.preloads...)
+	q2.joinPreloadNames = append([]string(nil), q.joinPreloadNames...)
 	return &q2
 }
 
+// Clone returns an independent copy of q. Builder methods already return a
+// new query and never modify their receiver, so a configured base query can
+// be shared between goroutines and extended by each of them without Clone.
+// Clone is for code that modifies a query in place: the Register* and
+// Apply* methods do, and the copy gets its own registrations, so
+// registering a join or preloader on it leaves q unchanged.
+func (q *Query[T]) Clone() *Query[T] {
+	q2 := q.clone()
+	q2.joinDefs = maps.Clone(q.joinDefs)
+	q2.preloaders = maps.Clone(q.preloaders)
+	q2.mergers = maps.Clone(q.mergers)
+	return q2
+}
+
 // --- Builder methods ---
 
 func (q *Query[T]) Where(clause string, args ...any) *Query[T] {
@@ -147,30 +335,219 @@ func (q *Query[T]) Where(clause string, args ...any) *Query[T] {
 	return q2
 }
 
+// OrWhere adds clause as an alternative to all WHERE conditions so far. The
+// existing conditions are grouped so that later Where calls still apply to
+// the whole result:
+//
+//	Users(db).Where("role = ?", "admin").Where("active").OrWhere("id = ?", 1).Where("deleted_at IS NULL")
+//	// → WHERE ((role = ? AND active) OR (id = ?)) AND deleted_at IS NULL
+//
+// Without earlier conditions OrWhere behaves like Where. scope.Or is the
+// scope form.
+func (q *Query[T]) OrWhere(clause string, args ...any) *Query[T] {
+	q2 := q.clone()
+	q2.ApplyOrWhere(clause, args)
+	return q2
+}
+
+// WhereGroup adds the WHERE conditions built by fn as one parenthesized
+// group. fn receives an empty query for the same table and returns it with
+// conditions added; anything other than WHERE conditions is ignored.
+//
+//	Users(db).Where("active").WhereGroup(func(g *orm.Query[User]) *orm.Query[User] {
+//		return g.Where("role = ?", "admin").OrWhere("role = ?", "owner")
+//	})
+//	// → WHERE active AND ((role = ?) OR (role = ?))
+func (q *Query[T]) WhereGroup(fn func(g *Query[T]) *Query[T]) *Query[T] {
+	g := q.clone()
+	g.wheres = nil
+	g = fn(g)
+
+	q2 := q.clone()
+	if q2.err == nil {
+		q2.err = g.err
+	}
+	if w, ok := groupWheres(g.wheres); ok {
+		q2.wheres = append(q2.wheres, w)
+	}
+	return q2
+}
+
+// GroupBy adds columns or expressions, caller-quoted as in OrderBy, to the
+// GROUP BY clause. Pair it with Select to read aggregates:
+//
+//	Posts(db).Select("user_id, COUNT(*) AS n").GroupBy("user_id").Having("COUNT(*) > ?", 5)
+//	// → SELECT user_id, COUNT(*) AS n FROM posts GROUP BY user_id HAVING COUNT(*) > ?
+func (q *Query[T]) GroupBy(columns ...string) *Query[T] {
+	q2 := q.clone()
+	q2.ApplyGroupBy(columns)
+	return q2
+}
+
+// Having adds a HAVING condition; multiple calls are ANDed, as with Where.
+func (q *Query[T]) Having(clause string, args ...any) *Query[T] {
+	q2 := q.clone()
+	q2.ApplyHaving(clause, args)
+	return q2
+}
+
 func (q *Query[T]) OrderBy(clause string) *Query[T] {
 	q2 := q.clone()
 	q2.orderBys = append(q2.orderBys, clause)
 	return q2
 }
 
+// ReorderBy replaces any ORDER BY added so far, e.g. a repository's default
+// sort, with clause. An empty clause removes ordering altogether.
+func (q *Query[T]) ReorderBy(clause string) *Query[T] {
+	q2 := q.clone()
+	q2.orderBys = nil
+	if clause != "" {
+		q2.orderBys = append(q2.orderBys, clause)
+	}
+	return q2
+}
+
+// Limit sets the LIMIT. Limit(0) is a literal LIMIT 0 and matches no rows;
+// a negative n removes any limit set earlier, e.g. by a scope.
 func (q *Query[T]) Limit(n int) *Query[T] {
 	q2 := q.clone()
-	q2.limit = &n
+	q2.ApplyLimit(n)
 	return q2
 }
 
+// Offset sets the OFFSET. Without a Limit it still applies on every dialect:
+// MySQL, which has no OFFSET without LIMIT, gets its documented maximum row
+// count as the limit. On SQL Server both become OFFSET ... FETCH, ordered
+// by (SELECT NULL) when the query has no OrderBy.
 func (q *Query[T]) Offset(n int) *Query[T] {
 	q2 := q.clone()
 	q2.offset = &n
 	return q2
 }
 
+// ForUpdate locks the selected rows until the end of the transaction:
+// the dialect's lock clause, e.g. FOR UPDATE, follows LIMIT and OFFSET.
+// Count and the other aggregates do not lock. Outside a transaction the
+// lock is released as soon as the statement finishes.
+//
+//	err := db.Transaction(ctx, func(tx *orm.Tx) error {
+//		acct, err := Accounts(tx).Where("id = ?", id).ForUpdate().First(ctx)
+//		...
+//	})
+func (q *Query[T]) ForUpdate() *Query[T] {
+	q2 := q.clone()
+	q2.lock = lockForUpdate
+	q2.checkLockable()
+	return q2
+}
+
+// ForUpdateSkipLocked is like ForUpdate but skips rows that another
+// transaction has locked instead of waiting for them, so that concurrent
+// workers can each claim different rows of a job queue:
+//
+//	jobs, err := Jobs(tx).Where("state = ?", "pending").OrderBy("id").Limit(10).ForUpdateSkipLocked().All(ctx)
+func (q *Query[T]) ForUpdateSkipLocked() *Query[T] {
+	q2 := q.clone()
+	q2.lock = lockForUpdateSkipLocked
+	q2.checkLockable()
+	return q2
+}
+
+// checkLockable fails the query when the dialect has no lock clause, so
+// that code relying on row locks does not silently run unlocked.
+func (q *Query[T]) checkLockable() {
+	if q.db.dialect().LockClause(false) == "" && q.err == nil {
+		q.err = errors.New("orm: dialect does not support ForUpdate")
+	}
+}
+
+// Raw makes All and First run query with args instead of the SELECT the
+// builder would produce, for window functions, CTEs and other SQL the
+// builder cannot express. Rows are still scanned into T by the generated
+// scanner, which matches result columns by name: name (or alias) them as
+// the model's columns. Unknown columns are discarded and fields whose
+// column is missing keep their zero value. Placeholders are written as ?
+// and rewritten for the dialect.
+//
+//	Users(db).Raw(`WITH ranked AS (
+//		SELECT users.*, ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC) AS rn FROM users
+//	) SELECT id, name FROM ranked WHERE rn <= ?`, 3).All(ctx)
+//
+// Where, OrderBy, Limit and the other builder clauses are ignored, so First
+// returns the first row the query yields. Preload still loads relations
+// with separate queries; other terminal methods such as Count and Update
+// do not use the raw query.
+func (q *Query[T]) Raw(query string, args ...any) *Query[T] {
+	q2 := q.clone()
+	q2.raw = &whereClause{query, args}
+	return q2
+}
+
+// Select overrides the SELECT column list with columns, written into the
+// statement as is: use it for expressions such as COUNT(*), and
+// SelectColumns for plain column names. Select("*") on a query with a Join
+// selects the generated, table-qualified list instead, so that columns the
+// joined tables share with the base table cannot shadow its values.
 func (q *Query[T]) Select(columns string) *Query[T] {
 	q2 := q.clone()
 	q2.selects = &columns
 	return q2
 }
 
+// SelectColumns overrides the SELECT column list with cols. Column names,
+// optionally table-qualified, are quoted for the dialect; anything else,
+// such as an expression or an alias, is kept as is:
+//
+//	Users(db).SelectColumns("id", "order")              // → SELECT `id`, `order` FROM `users`
+//	Users(db).SelectColumns("users.id", "COUNT(*) AS n") // → SELECT `users`.`id`, COUNT(*) AS n ...
+//
+// Once the query has a Join, names without a table are qualified with the
+// base table, wherever SelectColumns appears in the chain.
+//
+// scope.Select is the scope form.
+func (q *Query[T]) SelectColumns(cols ...string) *Query[T] {
+	q2 := q.clone()
+	q2.ApplySelectColumns(cols)
+	return q2
+}
+
+// Distinct makes the query SELECT DISTINCT, over the generated column list
+// or a Select override:
+//
+//	Users(db).Distinct().All(ctx)                // → SELECT DISTINCT `id`, `name` FROM `users`
+//	Users(db).Distinct().Select("role").All(ctx) // → SELECT DISTINCT role FROM `users`
+//
+// Count then counts the distinct rows. scope.Distinct is the scope form.
+func (q *Query[T]) Distinct() *Query[T] {
+	q2 := q.clone()
+	q2.ApplyDistinct()
+	return q2
+}
+
+// Hint adds a raw, caller-quoted hint fragment to SELECT statements. The
+// dialect decides where it goes (see HintPlacement):
+//
+//	q.Hint("/*+ MAX_EXECUTION_TIME(1000) */")  // MySQL: SELECT /*+ ... */ `id`, ...
+//	q.Hint("USE INDEX (idx_users_name)")        // MySQL: FROM `users` USE INDEX (...)
+//	q.Hint("/*+ SeqScan(users) */")             // PostgreSQL: /*+ ... */ SELECT ...
+func (q *Query[T]) Hint(fragment string) *Query[T] {
+	q2 := q.clone()
+	q2.hints = append(q2.hints, fragment)
+	return q2
+}
+
+// SelectAll discards any Select override so that the generated column list
+// is used again. It never emits "SELECT *": columns added to the table but
+// not to the struct are simply not read, so schema drift cannot surprise
+// the scanner.
+func (q *Query[T]) SelectAll() *Query[T] {
+	q2 := q.clone()
+	q2.selects = nil
+	q2.selCols = nil
+	return q2
+}
+
 // Join adds an INNER JOIN for the named relation.
 func (q *Query[T]) Join(name string) *Query[T] {
 	return q.addJoin("INNER JOIN", name)
@@ -181,6 +558,53 @@ func (q *Query[T]) LeftJoin(name string) *Query[T] {
 	return q.addJoin("LEFT JOIN", name)
 }
 
+// JoinWhere adds an INNER JOIN for the named relation and a WHERE clause on
+// the joined table, e.g. users with published posts:
+//
+//	Users(db).JoinWhere("Posts", "published = ?", true)
+//	// → INNER JOIN `posts` ON ... WHERE `posts`.`published` = ?
+//
+// A bare column at the start of clause, followed by a comparison (or by
+// nothing), is qualified with the joined table; anything else is used
+// verbatim. The clause is added even if name is not a registered relation,
+// so that a typo fails the query instead of silently dropping the filter.
+func (q *Query[T]) JoinWhere(name, clause string, args ...any) *Query[T] {
+	q2 := q.clone()
+	q2.applyJoin("INNER JOIN", name)
+	if cfg, ok := q2.joinDefs[name]; ok {
+		clause = qualifyLeadingColumn(clause, q2.joinTarget(name, cfg)+".", q2.qi)
+	}
+	q2.wheres = append(q2.wheres, whereClause{clause, args})
+	return q2
+}
+
+// qualifyLeadingColumn prefixes the bare identifier that starts clause with
+// prefix (and quotes it) when it is followed by a comparison operator,
+// IS/IN/LIKE/BETWEEN/NOT, or nothing. Otherwise clause is returned as is.
+func qualifyLeadingColumn(clause, prefix string, quote func(string) string) string {
+	trimmed := strings.TrimLeft(clause, " ")
+	end := 0
+	for end < len(trimmed) && isIdentByte(trimmed[end]) {
+		end++
+	}
+	if end == 0 || trimmed[0] >= '0' && trimmed[0] <= '9' {
+		return clause
+	}
+	rest := strings.TrimLeft(trimmed[end:], " ")
+	if rest != "" && !strings.ContainsRune("=<>!", rune(rest[0])) {
+		n := 0
+		for n < len(rest) && isIdentByte(rest[n]) {
+			n++
+		}
+		switch strings.ToUpper(rest[:n]) {
+		case "IS", "IN", "LIKE", "BETWEEN", "NOT":
+		default:
+			return clause
+		}
+	}
+	return prefix + quote(trimmed[:end]) + trimmed[end:]
+}
+
 func (q *Query[T]) addJoin(joinType, name string) *Query[T] {
 	q2 := q.clone()
 	q2.applyJoin(joinType, name)
@@ -192,17 +616,33 @@ func (q *Query[T]) applyJoin(joinType, name string) {
 	if !ok {
 		return
 	}
+	table := q.qi(cfg.TargetTable)
+	target := q.joinTarget(name, cfg)
+	if target != table {
+		table += " AS " + target
+	}
 	clause := fmt.Sprintf(
 		"%s %s ON %s.%s = %s.%s",
 		joinType,
-		q.qi(cfg.TargetTable),
-		q.qi(cfg.TargetTable), q.qi(cfg.TargetColumn),
+		table,
+		target, q.qi(cfg.TargetColumn),
 		q.qi(cfg.SourceTable), q.qi(cfg.SourceColumn),
 	)
 	q.joins = append(q.joins, clause)
 	q.activeJoinNames = append(q.activeJoinNames, name)
 }
 
+// joinTarget returns the quoted name the named relation's table goes by in
+// the query. A self-referencing relation, such as a category's parent,
+// joins the base table again under the relation name, so that its columns
+// are told apart from the base table's.
+func (q *Query[T]) joinTarget(name string, cfg JoinConfig) string {
+	if cfg.TargetTable == cfg.SourceTable {
+		return q.qi(name)
+	}
+	return q.qi(cfg.TargetTable)
+}
+
 // Preload registers a relation to be eagerly loaded after the main query.
 func (q *Query[T]) Preload(name string) *Query[T] {
 	q2 := q.clone()
@@ -210,6 +650,69 @@ func (q *Query[T]) Preload(name string) *Query[T] {
 	return q2
 }
 
+// OnConflictUpdateWhere restricts the DO UPDATE of Upsert to rows where
+// clause holds, e.g. last-write-wins by timestamp:
+//
+//	q.OnConflictUpdateWhere(`EXCLUDED."updated_at" > "posts"."updated_at"`)
+//
+// When the predicate is false the existing row is kept and the PK is not
+// populated. PostgreSQL only; Upsert returns an error on MySQL.
+func (q *Query[T]) OnConflictUpdateWhere(clause string, args ...any) *Query[T] {
+	q2 := q.clone()
+	q2.upsertWhere = &whereClause{clause, args}
+	return q2
+}
+
+// MaxBatchParams caps the bind parameters of each INSERT CreateAll sends,
+// which otherwise defaults to the dialect's MaxParams. Larger batches are
+// split into several statements of at most n / columns rows; a
+// non-positive n restores the default.
+func (q *Query[T]) MaxBatchParams(n int) *Query[T] {
+	q2 := q.clone()
+	q2.maxBatchParams = max(n, 0)
+	return q2
+}
+
+// OnConflict makes Upsert and UpsertReturning resolve conflicts on
+// columns, such as a unique email, instead of the primary key:
+//
+//	Users(db).OnConflict("email").Upsert(ctx, u)
+//	// PostgreSQL → INSERT ... ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"
+//
+// Neither the conflict columns nor the primary key are updated. A
+// database-assigned primary key is left out of the INSERT; PostgreSQL
+// reads it back with RETURNING, MySQL leaves it unset. MySQL's ON
+// DUPLICATE KEY UPDATE fires on any unique key, so there the columns only
+// narrow the update set.
+func (q *Query[T]) OnConflict(columns ...string) *Query[T] {
+	q2 := q.clone()
+	q2.conflictCols = append([]string(nil), columns...)
+	return q2
+}
+
+// SafePKAssignment makes CreateAll insert rows one statement at a time on
+// dialects without RETURNING (MySQL), reading each primary key from its own
+// LastInsertId. By default CreateAll sends a single INSERT and assigns
+// firstID+i, which is only correct when the batch receives contiguous
+// auto-increment values; with innodb_autoinc_lock_mode=2 (the MySQL 8
+// default) concurrent inserts can interleave and break that assumption.
+// On a *DB the rows are inserted in one transaction, so the batch stays
+// all-or-nothing; on a Tx they join the caller's transaction.
+func (q *Query[T]) SafePKAssignment() *Query[T] {
+	q2 := q.clone()
+	q2.safePKs = true
+	return q2
+}
+
+// Unscoped disables the soft-delete filter: queries include soft-deleted
+// rows, and Delete and DeleteAll remove rows for good. It has no effect
+// on a model without soft delete.
+func (q *Query[T]) Unscoped() *Query[T] {
+	q2 := q.clone()
+	q2.unscoped = true
+	return q2
+}
+
 // Scopes applies the given scope.Scope values to the query.
 func (q *Query[T]) Scopes(scopes ...scope.Scope) *Query[T] {
 	q2 := q.clone()
@@ -225,20 +728,106 @@ func (q *Query[T]) ApplyWhere(clause string, args []any) {
 	q.wheres = append(q.wheres, whereClause{clause, args})
 }
 
+func (q *Query[T]) ApplyOrWhere(clause string, args []any) {
+	left, ok := joinWheres(q.wheres)
+	if !ok {
+		q.wheres = []whereClause{{clause, args}}
+		return
+	}
+	q.wheres = []whereClause{{
+		"((" + left.clause + ") OR (" + clause + "))",
+		append(append([]any(nil), left.args...), args...),
+	}}
+}
+
 func (q *Query[T]) ApplyOrderBy(clause string) {
 	q.orderBys = append(q.orderBys, clause)
 }
 
-func (q *Query[T]) ApplyLimit(n int)  { q.limit = &n }
+func (q *Query[T]) ApplyLimit(n int) {
+	if n < 0 {
+		q.limit = nil
+		return
+	}
+	q.limit = &n
+}
+
 func (q *Query[T]) ApplyOffset(n int) { q.offset = &n }
 
+func (q *Query[T]) ApplyGroupBy(columns []string) {
+	q.groupBys = append(q.groupBys, columns...)
+}
+
+func (q *Query[T]) ApplyHaving(clause string, args []any) {
+	q.havings = append(q.havings, whereClause{clause, args})
+}
+
 func (q *Query[T]) ApplySelect(columns string) {
 	q.selects = &columns
+	q.selCols = nil
 }
 
+func (q *Query[T]) ApplySelectColumns(cols []string) {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = c
+		if isColumnRef(c) {
+			quoted[i] = q.qiRef(c)
+		}
+	}
+	q.ApplySelect(strings.Join(quoted, ", "))
+	q.selCols = slices.Clone(cols)
+}
+
+func (q *Query[T]) ApplyDistinct() { q.distinct = true }
+
 func (q *Query[T]) ApplyJoin(name string)     { q.applyJoin("INNER JOIN", name) }
-func (q *Query[T]) ApplyLeftJoin(name string)  { q.applyJoin("LEFT JOIN", name) }
-func (q *Query[T]) ApplyPreload(name string)   { q.preloads = append(q.preloads, name) }
+func (q *Query[T]) ApplyLeftJoin(name string) { q.applyJoin("LEFT JOIN", name) }
+func (q *Query[T]) ApplyPreload(name string)  { q.preloads = append(q.preloads, name) }
+
+func (q *Query[T]) ApplyOrderByCI(column, direction string) {
+	clause := q.db.dialect().CaseInsensitive(column)
+	if direction != "" {
+		clause += " " + direction
+	}
+	q.orderBys = append(q.orderBys, clause)
+}
+
+func (q *Query[T]) ApplyOrderByColumn(column, direction string) {
+	q.orderBys = append(q.orderBys, q.qiRef(q.qualify(column))+" "+direction)
+}
+
+func (q *Query[T]) ApplyColumnWhere(column, clause string, args []any) {
+	q.wheres = append(q.wheres, whereClause{fmt.Sprintf(clause, q.qiRef(column)), args})
+}
+
+func (q *Query[T]) ApplyQualifiedColumnWhere(column, clause string, args []any) {
+	q.ApplyColumnWhere(q.qualify(column), clause, args)
+}
+
+// qualify prefixes a bare column with the base table.
+func (q *Query[T]) qualify(column string) string {
+	if strings.Contains(column, ".") {
+		return column
+	}
+	return q.table + "." + column
+}
+
+func (q *Query[T]) ApplyWhereChecked(clause string, args []any) {
+	if n := countPlaceholders(clause); n != len(args) && q.err == nil {
+		q.err = fmt.Errorf("%w: %q has %d placeholders, got %d args", ErrPlaceholderMismatch, clause, n, len(args))
+	}
+	q.ApplyWhere(clause, args)
+}
+
+func (q *Query[T]) ApplyEqCI(column string, value any) {
+	ci := q.db.dialect().CaseInsensitive
+	q.wheres = append(q.wheres, whereClause{ci(column) + " = " + ci("?"), []any{value}})
+}
+
+func (q *Query[T]) ApplyILike(column string, pattern any) {
+	q.wheres = append(q.wheres, whereClause{q.db.dialect().ILike(column), []any{pattern}})
+}
 
 var _ scope.Applier = (*Query[any])(nil)
 
@@ -246,9 +835,124 @@ var _ scope.Applier = (*Query[any])(nil)
 
 // All executes a SELECT and returns all matching rows.
 func (q *Query[T]) All(ctx context.Context) ([]T, error) {
+	q, query, args := q.selectSQL(ctx)
+	return q.fetch(ctx, query, args)
+}
+
+// ToSQL returns the SELECT that All would run, with the dialect's
+// placeholders, and its args, without touching the database, e.g. to log
+// a query or snapshot it in a test:
+//
+//	query, args := query.Users(db).Where("age > ?", 18).ToSQL()
+//	// SELECT "id", "name", "age" FROM "users" WHERE age > $1, [18]
+//
+// Default scopes are not applied, since they come with the context All is
+// called with; add them with Scopes to see them. Errors recorded by builder
+// methods, such as a placeholder mismatch, surface only when the query runs.
+func (q *Query[T]) ToSQL() (string, []any) {
+	_, query, args := q.selectSQL(context.Background())
+	return query, args
+}
+
+// ToSQLInsert returns the INSERT that Create would run for t, with the
+// dialect's placeholders, and its args, without touching the database.
+// The args are t's fields as they are: Create sets timestamps and
+// generated primary keys first, which ToSQLInsert leaves to the caller.
+func (q *Query[T]) ToSQLInsert(t *T) (string, []any) {
+	columns, values := q.insertPairs(t, q.setPK == nil)
+	query, values := q.rewrite(q.buildInsert(columns), values)
+	if d := q.db.dialect(); q.useReturning(d) {
+		query += q.returningClause(d)
+	}
+	return query, values
+}
+
+// Explain returns the plan of the SELECT that All would run, one line per
+// row the database reports, for diagnosing slow queries:
+//
+//	plan, err := query.Users(db).Where("email = ?", addr).Explain(ctx)
+//	fmt.Println(strings.Join(plan, "\n"))
+//
+// Rows with several columns, such as MySQL's tabular EXPLAIN, are joined
+// with tabs, and NULL columns read "NULL". Preloads that run as separate
+// queries are not explained. Dialects without EXPLAIN (SQL Server) fail.
+func (q *Query[T]) Explain(ctx context.Context) ([]string, error) {
+	return q.explain(ctx, false)
+}
+
+// ExplainAnalyze is like Explain but uses EXPLAIN ANALYZE, which runs the
+// SELECT and reports actual times and row counts alongside the plan.
+func (q *Query[T]) ExplainAnalyze(ctx context.Context) ([]string, error) {
+	return q.explain(ctx, true)
+}
+
+func (q *Query[T]) explain(ctx context.Context, analyze bool) (_ []string, err error) {
+	prefix := q.db.dialect().ExplainClause(analyze)
+	if prefix == "" {
+		return nil, errors.New("orm: dialect does not support EXPLAIN")
+	}
+	q, query, args := q.selectSQL(ctx)
+
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if q.err != nil {
+		return nil, q.err
+	}
+	rows, err := q.db.QueryContext(q.routed(ctx), prefix+" "+query, args...)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // pass through
+	}
+	vals := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	var plan []string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err //nolint:wrapcheck // pass through
+		}
+		fields := make([]string, len(vals))
+		for i, v := range vals {
+			fields[i] = "NULL"
+			if v.Valid {
+				fields[i] = v.String
+			}
+		}
+		plan = append(plan, strings.Join(fields, "\t"))
+	}
+	return plan, rows.Err() //nolint:wrapcheck // pass through
+}
+
+// selectSQL returns the query All runs, with the default scopes in ctx
+// applied and preloads turned into joins where possible, and its SELECT.
+func (q *Query[T]) selectSQL(ctx context.Context) (*Query[T], string, []any) {
+	if q.raw != nil {
+		query, args := q.rewrite(q.raw.clause, q.raw.args)
+		return q, query, args
+	}
+	q = q.withDefaultScopes(ctx).joinPreloads()
 	query, args := q.buildSelect()
 	query, args = q.rewrite(query, args)
+	return q, query, args
+}
 
+// fetch runs a built SELECT, scans every row and applies preloads.
+func (q *Query[T]) fetch(ctx context.Context, query string, args []any) (_ []T, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if q.err != nil {
+		return nil, q.err
+	}
+	ctx = q.routed(ctx)
 	rows, err := q.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err //nolint:wrapcheck // pass through
@@ -266,6 +970,9 @@ func (q *Query[T]) All(ctx context.Context) ([]T, error) {
 	if err := rows.Err(); err != nil {
 		return nil, err //nolint:wrapcheck // pass through
 	}
+	if q.joinedMany != "" {
+		result = q.mergeJoined(result)
+	}
 
 	for _, name := range q.preloads {
 		fn, ok := q.preloaders[name]
@@ -280,6 +987,76 @@ func (q *Query[T]) All(ctx context.Context) ([]T, error) {
 	return result, nil
 }
 
+// AllPtr is like All but returns pointers to the results, so callers can
+// mutate and re-save rows without copying each struct. Preloads are applied
+// before the pointers are taken, so relations are visible through them.
+// All pointers share one backing array.
+func (q *Query[T]) AllPtr(ctx context.Context) ([]*T, error) {
+	items, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		return nil, nil
+	}
+	result := make([]*T, len(items))
+	for i := range items {
+		result[i] = &items[i]
+	}
+	return result, nil
+}
+
+// Each executes the SELECT like All but passes the rows to fn one at a
+// time as they are scanned, without collecting them, so exporting millions
+// of rows does not hold them all in memory:
+//
+//	err := query.Users(db).OrderBy("id").Each(ctx, func(u model.User) error {
+//	    return enc.Encode(u)
+//	})
+//
+// Iteration stops at the first error from fn, which Each returns. Preloads
+// that run as separate queries, or join a to-many relation, need every
+// parent row at once, so a query with them, including those added by the
+// context's default scopes, fails before running; call the generated
+// Preload<Model><Relation> functions on batches of rows instead. A to-one
+// relation loaded by JOIN is scanned with each row. The query's connection
+// stays busy until Each returns, so fn must not run statements on the
+// same Tx.
+func (q *Query[T]) Each(ctx context.Context, fn func(T) error) (err error) {
+	q, query, args := q.selectSQL(ctx)
+	pending := q.preloads
+	if q.joinedMany != "" {
+		pending = append(slices.Clone(pending), q.joinedMany)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("orm: Each cannot preload %s; preload batches of rows instead", strings.Join(pending, ", "))
+	}
+
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if q.err != nil {
+		return q.err
+	}
+	ctx = q.routed(ctx)
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		item, err := q.scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err() //nolint:wrapcheck // pass through
+}
+
 // First executes a SELECT with LIMIT 1 and returns the first row.
 // Returns ErrNotFound if no rows match.
 func (q *Query[T]) First(ctx context.Context) (T, error) {
@@ -296,15 +1073,99 @@ func (q *Query[T]) First(ctx context.Context) (T, error) {
 	return items[0], nil
 }
 
-// Count returns the number of rows matching the current query conditions.
+// FirstOrCreate looks up the first row matching the query and stores it in
+// *t. If there is none, it inserts *t with Create instead. The conditions
+// are not mapped back into the struct, so populate *t with the values they
+// look for before calling:
+//
+//	u := &model.User{Email: addr, Name: name}
+//	err := query.Users(db).Where("email = ?", addr).FirstOrCreate(ctx, u)
+//
+// The lookup and the INSERT are separate statements, so two concurrent
+// callers can both miss and both insert. Back the looked-up columns with a
+// unique constraint and retry on a duplicate key error, which then finds
+// the other caller's row, or use Upsert when the row may be overwritten.
+func (q *Query[T]) FirstOrCreate(ctx context.Context, t *T) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	found, err := q.First(ctx)
+	switch {
+	case err == nil:
+		*t = found
+		return nil
+	case errors.Is(err, ErrNotFound):
+		return q.Create(ctx, t)
+	default:
+		return err
+	}
+}
+
+// FirstOrInit is like FirstOrCreate but does not insert: if no row matches,
+// *t keeps the values the caller populated it with and nil is returned.
+func (q *Query[T]) FirstOrInit(ctx context.Context, t *T) error {
+	found, err := q.First(ctx)
+	switch {
+	case err == nil:
+		*t = found
+		return nil
+	case errors.Is(err, ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// Count returns the number of rows matching the current query conditions,
+// with Distinct the number of distinct rows, or with GroupBy the number of
+// groups left after Having. Having without GroupBy counts the rows All
+// returns.
+// LIMIT and OFFSET are ignored: they page the rows, not the total.
 func (q *Query[T]) Count(ctx context.Context) (int64, error) {
-	query, args := q.buildCount()
-	query, args = q.rewrite(query, args)
+	return q.count(ctx, "*")
+}
 
-	var count int64
-	rows, err := q.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return 0, err //nolint:wrapcheck // pass through
+// CountDistinct returns the number of distinct values of expr among the rows
+// matching the current query conditions, e.g. the number of users rather
+// than user×post rows once Posts is joined:
+//
+//	n, err := Users(db).Join("Posts").Where("posts.published").CountDistinct(ctx, "users.id")
+//
+// A bare column, optionally table-qualified, is quoted for the dialect; an
+// expression containing parentheses is passed through verbatim.
+// LIMIT and OFFSET are ignored, as in Count.
+func (q *Query[T]) CountDistinct(ctx context.Context, expr string) (int64, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, errors.New("orm: CountDistinct requires an expression")
+	}
+	if !strings.Contains(expr, "(") {
+		expr = q.qiRef(expr)
+	}
+	if len(q.groupBys) > 0 {
+		return 0, errors.New("orm: CountDistinct cannot be combined with GroupBy")
+	}
+	if len(q.havings) > 0 {
+		return 0, errors.New("orm: CountDistinct cannot be combined with Having")
+	}
+	return q.count(ctx, "DISTINCT "+expr)
+}
+
+func (q *Query[T]) count(ctx context.Context, expr string) (_ int64, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return 0, q.err
+	}
+	query, args := q.buildCount(expr)
+	query, args = q.rewrite(query, args)
+
+	var count int64
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // pass through
 	}
 	defer func() { _ = rows.Close() }()
 	if !rows.Next() {
@@ -316,77 +1177,496 @@ func (q *Query[T]) Count(ctx context.Context) (int64, error) {
 	return count, rows.Err() //nolint:wrapcheck // pass through
 }
 
-// Exists returns true if at least one row matches the current query conditions.
-func (q *Query[T]) Exists(ctx context.Context) (bool, error) {
-	count, err := q.Limit(1).Count(ctx)
+// Sum returns SUM(column) over the rows matching the current query
+// conditions. It is NULL (Valid false) when no rows match. A bare column,
+// optionally table-qualified, is quoted for the dialect; an expression
+// containing parentheses is passed through verbatim. LIMIT and OFFSET are
+// ignored, as in Count.
+func (q *Query[T]) Sum(ctx context.Context, column string) (sql.NullFloat64, error) {
+	return q.aggregate(ctx, "SUM", column)
+}
+
+// Avg is like Sum but returns AVG(column).
+func (q *Query[T]) Avg(ctx context.Context, column string) (sql.NullFloat64, error) {
+	return q.aggregate(ctx, "AVG", column)
+}
+
+// Min is like Sum but returns MIN(column).
+func (q *Query[T]) Min(ctx context.Context, column string) (sql.NullFloat64, error) {
+	return q.aggregate(ctx, "MIN", column)
+}
+
+// Max is like Sum but returns MAX(column).
+func (q *Query[T]) Max(ctx context.Context, column string) (sql.NullFloat64, error) {
+	return q.aggregate(ctx, "MAX", column)
+}
+
+func (q *Query[T]) aggregate(ctx context.Context, fn, column string) (_ sql.NullFloat64, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	var v sql.NullFloat64
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return v, q.err
+	}
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return v, fmt.Errorf("orm: %s requires a column", fn)
+	}
+	if len(q.groupBys) > 0 {
+		return v, fmt.Errorf("orm: %s cannot be combined with GroupBy", fn)
+	}
+	if !strings.Contains(column, "(") {
+		column = q.qiRef(column)
+	}
+
+	query, args := q.buildAggregate(fn + "(" + column + ")")
+	query, args = q.rewrite(query, args)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
 	if err != nil {
-		return false, err
+		return v, err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		return v, fmt.Errorf("orm: %s returned no rows", fn)
+	}
+	if err := rows.Scan(&v); err != nil {
+		return v, err //nolint:wrapcheck // pass through
 	}
-	return count > 0, nil
+	return v, rows.Err() //nolint:wrapcheck // pass through
+}
+
+// Exists returns true if at least one row matches the current query
+// conditions. It selects a constant from the first matching row, so the
+// database stops at that row instead of counting them all:
+//
+//	Users(db).Where("email = ?", addr).Exists(ctx)
+//	// → SELECT 1 FROM `users` WHERE email = ? LIMIT 1
+//
+// Joins, GROUP BY and HAVING apply; ORDER BY, LIMIT and OFFSET are ignored.
+func (q *Query[T]) Exists(ctx context.Context) (_ bool, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return false, q.err
+	}
+	query, args := q.buildExists()
+	query, args = q.rewrite(query, args)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
+	if err != nil {
+		return false, err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+	found := rows.Next()
+	return found, rows.Err() //nolint:wrapcheck // pass through
+}
+
+// AllByID runs q like All and returns the rows keyed by primary key, for
+// lookups and assembling joins in application code. K must be the Go type
+// of the primary key; a mismatch is reported as an error.
+//
+//	users, err := orm.AllByID[int](ctx, query.Users(db).Where("active"))
+func AllByID[K comparable, T any](ctx context.Context, q *Query[T]) (map[K]T, error) {
+	if len(q.pkCols) > 0 || q.pk == "" {
+		return nil, errors.New("orm: AllByID requires a single-column primary key")
+	}
+	items, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[K]T, len(items))
+	for i := range items {
+		k, ok := q.pkValue(&items[i]).(K)
+		if !ok {
+			var zero K
+			return nil, fmt.Errorf("orm: AllByID: primary key %q is %T, not %T", q.pk, q.pkValue(&items[i]), zero)
+		}
+		m[k] = items[i]
+	}
+	return m, nil
+}
+
+// FindByID returns the row whose primary key equals id, or ErrNotFound.
+// WHERE clauses, joins and preloads already on q still apply. id may be of
+// any type the driver accepts for the key column, e.g. int64 or a UUID string.
+//
+//	user, err := orm.FindByID(ctx, query.Users(db), 42)
+func FindByID[K comparable, T any](ctx context.Context, q *Query[T], id K) (T, error) {
+	if len(q.pkCols) > 0 || q.pk == "" {
+		var zero T
+		return zero, errors.New("orm: FindByID requires a single-column primary key")
+	}
+	return q.Where(q.qi(q.table)+"."+q.qi(q.pk)+" = ?", id).First(ctx)
+}
+
+// DeleteByID deletes the row whose primary key equals id, soft-deleting it
+// when q has a deletedAt column. WHERE clauses already on q still apply.
+//
+//	err := orm.DeleteByID(ctx, query.Users(db), 42)
+func DeleteByID[K comparable, T any](ctx context.Context, q *Query[T], id K) error {
+	if len(q.pkCols) > 0 || q.pk == "" {
+		return errors.New("orm: DeleteByID requires a single-column primary key")
+	}
+	return q.Where(q.qi(q.table)+"."+q.qi(q.pk)+" = ?", id).Delete(ctx)
+}
+
+// Pluck runs q selecting only column and returns its values, e.g. the names
+// of a single-column lookup table:
+//
+//	names, err := orm.Pluck[string](ctx, query.Tags(db).OrderBy("name"), "name")
+//
+// A bare column, optionally table-qualified, is quoted for the dialect; an
+// expression containing parentheses is passed through verbatim. Preloads
+// are not applied, and no matching rows give a nil slice.
+func Pluck[V, T any](ctx context.Context, q *Query[T], column string) (_ []V, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return nil, q.err
+	}
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return nil, errors.New("orm: Pluck requires a column")
+	}
+	if !strings.Contains(column, "(") {
+		column = q.qiRef(column)
+	}
+
+	q2 := q.clone()
+	q2.selects = &column
+	q2.selCols = nil
+	query, args := q2.buildSelect()
+	query, args = q2.rewrite(query, args)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+
+	var values []V
+	for rows.Next() {
+		var v V
+		if err := rows.Scan(&v); err != nil {
+			return nil, err //nolint:wrapcheck // pass through
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err() //nolint:wrapcheck // pass through
+}
+
+// pkValue returns the primary key value of t, via the registered accessor
+// or, failing that, the column values.
+func (q *Query[T]) pkValue(t *T) any {
+	if q.getPK != nil {
+		return q.getPK(t)
+	}
+	if q.colValPairs == nil {
+		return nil
+	}
+	cols, vals := q.colValPairs(t, true)
+	for i, c := range cols {
+		if c == q.pk {
+			return vals[i]
+		}
+	}
+	return nil
+}
+
+// existingIDsChunkSize bounds the number of placeholders per ExistingIDs query.
+const existingIDsChunkSize = 1000
+
+// ExistingIDs returns the subset of ids whose primary key exists in q's table.
+// WHERE clauses already on q are honoured, so callers can narrow the check.
+// Large id lists are split into chunks of existingIDsChunkSize.
+//
+//	seen, err := orm.ExistingIDs(ctx, query.Users(db), []int{1, 2, 3})
+func ExistingIDs[T any, K comparable](ctx context.Context, q *Query[T], ids []K) (_ []K, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if len(q.pkCols) > 0 || q.pk == "" {
+		return nil, errors.New("orm: ExistingIDs requires a single-column primary key")
+	}
+	var found []K
+	for start := 0; start < len(ids); start += existingIDsChunkSize {
+		chunk := ids[start:min(start+existingIDsChunkSize, len(ids))]
+		ids, err := queryExistingIDs(ctx, q, chunk)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, ids...)
+	}
+	return found, nil
+}
+
+func queryExistingIDs[T any, K comparable](ctx context.Context, q *Query[T], ids []K) ([]K, error) {
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return nil, q.err
+	}
+	pkCol := q.qi(q.table) + "." + q.qi(q.pk)
+	selects := "DISTINCT " + pkCol
+
+	q2 := q.Scopes(scope.In(pkCol, ids))
+	q2.selects = &selects
+	q2.selCols = nil
+	q2.distinct = false
+	q2.orderBys = nil
+	q2.limit = nil
+	q2.offset = nil
+
+	query, args := q2.buildSelect()
+	query, args = q2.rewrite(query, args)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+
+	var found []K
+	for rows.Next() {
+		var id K
+		if err := rows.Scan(&id); err != nil {
+			return nil, err //nolint:wrapcheck // pass through
+		}
+		found = append(found, id)
+	}
+	return found, rows.Err() //nolint:wrapcheck // pass through
 }
 
 // Create inserts a new row. If setPK is set, the primary key is populated
 // via RETURNING (PostgreSQL) or LastInsertId (MySQL).
 func (q *Query[T]) Create(ctx context.Context, t *T) error {
+	_, err := q.CreateResult(ctx, t)
+	return err
+}
+
+// CreateResult is like Create but also returns the driver's sql.Result,
+// e.g. to inspect RowsAffected. When the primary key is populated via
+// RETURNING (PostgreSQL) there is no sql.Result and nil is returned.
+func (q *Query[T]) CreateResult(ctx context.Context, t *T) (_ sql.Result, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := q.validate(t); err != nil {
+		return nil, err
+	}
+	if err := q.checkPKReadable(); err != nil {
+		return nil, err
+	}
+
 	q.applyTimestamps(ctx, t, true)
+	if q.generatePK != nil {
+		q.generatePK(t)
+	}
 
 	includesPK := q.setPK == nil
-	columns, values := q.colValPairs(t, includesPK)
+	columns, values := q.insertPairs(t, includesPK)
 
 	query := q.buildInsert(columns)
 	query, values = q.rewrite(query, values)
 
 	d := q.db.dialect()
-	if d.UseReturning() && q.setPK != nil {
-		query += d.ReturningClause(q.pk)
-		rows, err := q.db.QueryContext(ctx, query, values...)
+	if q.useReturning(d) {
+		query += q.returningClause(d)
+		rows, err := q.db.QueryContext(q.routed(ctx), query, values...)
 		if err != nil {
-			return err //nolint:wrapcheck // pass through
+			return nil, err //nolint:wrapcheck // pass through
 		}
 		defer func() { _ = rows.Close() }()
 		if !rows.Next() {
-			return errors.New("orm: INSERT RETURNING returned no rows")
+			return nil, errors.New("orm: INSERT RETURNING returned no rows")
 		}
-		var id int64
-		if err := rows.Scan(&id); err != nil {
-			return err //nolint:wrapcheck // pass through
+		if err := q.scanReturning(rows, t); err != nil {
+			return nil, err
 		}
-		q.setPK(t, id)
-		return rows.Err() //nolint:wrapcheck // pass through
+		return nil, rows.Err() //nolint:wrapcheck // pass through
 	}
 
-	result, err := q.db.ExecContext(ctx, query, values...)
+	result, err := q.db.ExecContext(q.routed(ctx), query, values...)
 	if err != nil {
-		return err //nolint:wrapcheck // pass through
+		return nil, err //nolint:wrapcheck // pass through
 	}
 
 	if q.setPK != nil {
 		id, err := result.LastInsertId()
 		if err != nil {
-			return err //nolint:wrapcheck // pass through
+			return nil, err //nolint:wrapcheck // pass through
 		}
 		q.setPK(t, id)
 	}
-	return nil
+	return result, nil
+}
+
+// CreateReturning is like Create but also reads the given columns back into
+// t once the row is stored, picking up values the database filled in, such
+// as column defaults or a computed slug:
+//
+//	err := query.Posts(db).CreateReturning(ctx, &p, "slug", "created_at")
+//
+// Dialects with RETURNING (PostgreSQL) append the columns to the INSERT;
+// MySQL and SQL Server follow up with a SELECT of them by primary key.
+func (q *Query[T]) CreateReturning(ctx context.Context, t *T, cols ...string) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return err
+	}
+	if err := q.validate(t); err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		return errors.New("orm: CreateReturning requires at least one column")
+	}
+	for _, col := range cols {
+		if !slices.Contains(q.columns, col) {
+			return fmt.Errorf("orm: CreateReturning: unknown column %q", col)
+		}
+	}
+	if q.scanInto == nil {
+		return errors.New("orm: CreateReturning requires a scanner registered with RegisterScanInto")
+	}
+
+	d := q.db.dialect()
+	if !d.UseReturning() {
+		if err := q.Create(ctx, t); err != nil {
+			return err
+		}
+		return q.reloadColumns(ctx, t, cols)
+	}
+
+	q.applyTimestamps(ctx, t, true)
+	if q.generatePK != nil {
+		q.generatePK(t)
+	}
+
+	includesPK := q.setPK == nil
+	columns, values := q.insertPairs(t, includesPK)
+
+	query := q.buildInsert(columns)
+	query, values = q.rewrite(query, values)
+	query += " RETURNING " + q.quoteColumns(q.returningColumns(cols))
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, values...)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		return errors.New("orm: INSERT RETURNING returned no rows")
+	}
+	if err := q.scanInto(rows, t); err != nil {
+		return err
+	}
+	return rows.Err() //nolint:wrapcheck // pass through
+}
+
+// returningColumns extends the columns requested from CreateReturning with
+// those Create itself reads back: a database-assigned primary key and the
+// server-managed timestamps.
+func (q *Query[T]) returningColumns(cols []string) []string {
+	var extra []string
+	if q.setPK != nil {
+		extra = append(extra, q.primaryKeys()[0])
+	}
+	if q.serverTimestamps != nil {
+		extra = append(extra, q.serverTimestampCols...)
+	}
+	result := slices.Clone(cols)
+	for _, col := range extra {
+		if !slices.Contains(result, col) {
+			result = append(result, col)
+		}
+	}
+	return result
 }
 
 // CreateAll inserts multiple rows in a single INSERT statement.
-// If setPK is set, primary keys are populated for each row.
-func (q *Query[T]) CreateAll(ctx context.Context, items []*T) error {
+// If setPK is set, primary keys are populated for each row; on MySQL see
+// SafePKAssignment. A batch whose bind parameters would exceed the
+// dialect's MaxParams (or MaxBatchParams) is split into several INSERTs,
+// run in one transaction on a *DB.
+func (q *Query[T]) CreateAll(ctx context.Context, items []*T) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return err
+	}
+	if err := q.validate(items...); err != nil {
+		return err
+	}
+
 	if len(items) == 0 {
 		return nil
 	}
+	if err := q.checkPKReadable(); err != nil {
+		return err
+	}
+
+	if q.safePKs && q.setPK != nil && !q.db.dialect().UseReturning() {
+		return q.createEach(ctx, items)
+	}
 
 	for _, item := range items {
 		q.applyTimestamps(ctx, item, true)
+		if q.generatePK != nil {
+			q.generatePK(item)
+		}
 	}
 
 	includesPK := q.setPK == nil
-	columns, _ := q.colValPairs(items[0], includesPK)
+	columns, _ := q.insertPairs(items[0], includesPK)
+
+	limit := q.maxBatchParams
+	if limit == 0 {
+		limit = q.db.dialect().MaxParams()
+	}
+	chunkSize := max(limit/max(len(columns), 1), 1)
+	if len(items) <= chunkSize {
+		return q.insertBatch(ctx, columns, includesPK, items)
+	}
+	return q.insertChunks(ctx, columns, includesPK, items, chunkSize)
+}
+
+// insertChunks runs insertBatch for each run of chunkSize items, inside a
+// transaction when q runs on a *DB so that the batch stays all-or-nothing.
+func (q *Query[T]) insertChunks(ctx context.Context, columns []string, includesPK bool, items []*T, chunkSize int) error {
+	if db, ok := q.db.(*DB); ok {
+		return db.Transaction(ctx, func(tx *Tx) error {
+			q2 := q.clone()
+			q2.db = tx
+			return q2.insertChunks(ctx, columns, includesPK, items, chunkSize)
+		})
+	}
+	for start := 0; start < len(items); start += chunkSize {
+		chunk := items[start:min(start+chunkSize, len(items))]
+		if err := q.insertBatch(ctx, columns, includesPK, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// insertBatch inserts items with one multi-row INSERT and populates their
+// primary keys.
+func (q *Query[T]) insertBatch(ctx context.Context, columns []string, includesPK bool, items []*T) error {
 	var allValues []any
 	for _, item := range items {
-		_, vals := q.colValPairs(item, includesPK)
+		_, vals := q.insertPairs(item, includesPK)
 		allValues = append(allValues, vals...)
 	}
 
@@ -394,101 +1674,463 @@ func (q *Query[T]) CreateAll(ctx context.Context, items []*T) error {
 	query, allValues = q.rewrite(query, allValues)
 
 	d := q.db.dialect()
-	if d.UseReturning() && q.setPK != nil {
-		query += d.ReturningClause(q.pk)
-		rows, err := q.db.QueryContext(ctx, query, allValues...)
+	if q.useReturning(d) {
+		query += q.returningClause(d)
+		rows, err := q.db.QueryContext(q.routed(ctx), query, allValues...)
 		if err != nil {
 			return err //nolint:wrapcheck // pass through
 		}
 		defer func() { _ = rows.Close() }()
 		for i := 0; rows.Next(); i++ {
-			var id int64
-			if err := rows.Scan(&id); err != nil {
-				return err //nolint:wrapcheck // pass through
+			if err := q.scanReturning(rows, items[i]); err != nil {
+				return err
+			}
+		}
+		return rows.Err() //nolint:wrapcheck // pass through
+	}
+
+	result, err := q.db.ExecContext(q.routed(ctx), query, allValues...)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+
+	if q.setPK != nil {
+		firstID, err := result.LastInsertId()
+		if err != nil {
+			return err //nolint:wrapcheck // pass through
+		}
+		for i, item := range items {
+			q.setPK(item, firstID+int64(i))
+		}
+	}
+	return nil
+}
+
+// CreateStream inserts the items received from ch, CreateAll-ing them in
+// batches of batchSize, until ch is closed; a final partial batch is
+// flushed too. Only one batch is held in memory, so a producer can stream
+// more rows than fit at once. When a batch fails or ctx is canceled, the
+// batches before it stay inserted and the items still buffered are not;
+// run the stream on a Tx to make it all-or-nothing. Primary keys and
+// timestamps are set as by CreateAll.
+func (q *Query[T]) CreateStream(ctx context.Context, ch <-chan *T, batchSize int) error {
+	if err := q.checkWritable(); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return errors.New("orm: CreateStream requires a positive batchSize")
+	}
+
+	batch := make([]*T, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck // pass through
+		case item, ok := <-ch:
+			if !ok {
+				return q.CreateAll(ctx, batch)
+			}
+			batch = append(batch, item)
+			if len(batch) < batchSize {
+				continue
+			}
+			if err := q.CreateAll(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+}
+
+// checkPKReadable rejects an insert whose database-assigned primary key
+// could not be read back. SQL Server has neither RETURNING nor a driver
+// LastInsertId, and failing after the INSERT would report an error for a
+// row that was stored.
+func (q *Query[T]) checkPKReadable() error {
+	if _, ok := q.db.dialect().(sqlServerDialect); ok && q.setPK != nil {
+		return errors.New("orm: SQL Server cannot read back a database-assigned primary key; assign the key before inserting")
+	}
+	return nil
+}
+
+// createEach inserts items one by one so that each primary key comes from
+// its own LastInsertId, inside a transaction when q runs on a *DB.
+func (q *Query[T]) createEach(ctx context.Context, items []*T) error {
+	if db, ok := q.db.(*DB); ok {
+		return db.Transaction(ctx, func(tx *Tx) error {
+			q2 := q.clone()
+			q2.db = tx
+			return q2.createEach(ctx, items)
+		})
+	}
+	for _, item := range items {
+		if _, err := q.CreateResult(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upsert inserts a row or updates it on primary key conflict.
+// All non-PK columns (except createdAt) are updated on conflict.
+// The primary key must be set on t before calling Upsert.
+func (q *Query[T]) Upsert(ctx context.Context, t *T) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	query, values, err := q.upsertStatement(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	d := q.db.dialect()
+	if q.useReturning(d) {
+		query += q.returningClause(d)
+		rows, err := q.db.QueryContext(q.routed(ctx), query, values...)
+		if err != nil {
+			return err //nolint:wrapcheck // pass through
+		}
+		defer func() { _ = rows.Close() }()
+		if rows.Next() {
+			if err := q.scanReturning(rows, t); err != nil {
+				return err
+			}
+		}
+		return rows.Err() //nolint:wrapcheck // pass through
+	}
+
+	_, err = q.db.ExecContext(q.routed(ctx), query, values...)
+	return err //nolint:wrapcheck // pass through
+}
+
+// UpsertReturning is like Upsert but refreshes every column of t from the
+// stored row afterwards, picking up values the database computed or kept,
+// such as a server-managed updated_at or a generated column. Dialects with
+// RETURNING read the row back in the same statement; MySQL, and a
+// PostgreSQL conflict skipped by OnConflictUpdateWhere, follow up with a
+// SELECT by primary key.
+func (q *Query[T]) UpsertReturning(ctx context.Context, t *T) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	query, values, err := q.upsertStatement(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	d := q.db.dialect()
+	if !d.UseReturning() {
+		if _, err := q.db.ExecContext(q.routed(ctx), query, values...); err != nil {
+			return err //nolint:wrapcheck // pass through
+		}
+		return q.reloadByPK(ctx, t)
+	}
+
+	query += " RETURNING " + q.quoteColumns(q.columns)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, values...)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err //nolint:wrapcheck // pass through
+		}
+		return q.reloadByPK(ctx, t)
+	}
+	v, err := q.scan(rows)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	*t = v
+	return rows.Err() //nolint:wrapcheck // pass through
+}
+
+// InsertIgnore inserts t like Create, but skips it without an error when it
+// conflicts with an existing row on the primary key or a unique index, for
+// idempotent ingestion. A database-assigned primary key is populated only
+// when the row was inserted.
+//
+//	MySQL:      INSERT ... ON DUPLICATE KEY UPDATE `id` = `id`
+//	PostgreSQL: INSERT ... ON CONFLICT DO NOTHING
+func (q *Query[T]) InsertIgnore(ctx context.Context, t *T) (err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return err
+	}
+	if err := q.checkConflictClause("InsertIgnore"); err != nil {
+		return err
+	}
+	if err := q.validate(t); err != nil {
+		return err
+	}
+
+	q.applyTimestamps(ctx, t, true)
+	if q.generatePK != nil {
+		q.generatePK(t)
+	}
+
+	includesPK := q.setPK == nil
+	columns, values := q.insertPairs(t, includesPK)
+
+	query := q.buildInsertIgnore(columns)
+	query, values = q.rewrite(query, values)
+
+	d := q.db.dialect()
+	if q.useReturning(d) {
+		query += q.returningClause(d)
+		rows, err := q.db.QueryContext(q.routed(ctx), query, values...)
+		if err != nil {
+			return err //nolint:wrapcheck // pass through
+		}
+		defer func() { _ = rows.Close() }()
+		if rows.Next() { // no row when the insert was skipped
+			if err := q.scanReturning(rows, t); err != nil {
+				return err
 			}
-			q.setPK(items[i], id)
 		}
 		return rows.Err() //nolint:wrapcheck // pass through
 	}
 
-	result, err := q.db.ExecContext(ctx, query, allValues...)
+	result, err := q.db.ExecContext(q.routed(ctx), query, values...)
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	if q.setPK == nil {
+		return nil
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err //nolint:wrapcheck // pass through
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	q.setPK(t, id)
+	return nil
+}
+
+// upsertStatement prepares t and builds the rewritten INSERT ... ON CONFLICT
+// statement shared by Upsert and UpsertReturning.
+func (q *Query[T]) upsertStatement(ctx context.Context, t *T) (string, []any, error) {
+	if err := q.checkWritable(); err != nil {
+		return "", nil, err
+	}
+	if err := q.checkConflictClause("Upsert"); err != nil {
+		return "", nil, err
+	}
+	if err := q.validate(t); err != nil {
+		return "", nil, err
+	}
+
+	q.applyTimestamps(ctx, t, true)
+
+	if _, ok := q.db.dialect().(mysqlDialect); ok && q.upsertWhere != nil {
+		return "", nil, errors.New("orm: OnConflictUpdateWhere is not supported by MySQL")
+	}
+
+	// Include the PK, unless it is database-assigned and the conflict is
+	// on other columns.
+	columns, values := q.insertPairs(t, len(q.conflictCols) == 0 || q.setPK == nil)
+
+	query := q.buildUpsert(columns)
+	if q.upsertWhere != nil {
+		values = append(values, q.upsertWhere.args...)
+	}
+	query, values = q.rewrite(query, values)
+	return query, values, nil
+}
+
+// checkConflictClause rejects the conflict-handling inserts on SQL Server,
+// which has neither ON CONFLICT nor ON DUPLICATE KEY UPDATE and would need
+// a MERGE instead.
+func (q *Query[T]) checkConflictClause(method string) error {
+	if _, ok := q.db.dialect().(sqlServerDialect); ok {
+		return fmt.Errorf("orm: %s is not supported by SQL Server", method)
+	}
+	return nil
+}
+
+// reloadByPK replaces t with the stored row that has t's primary key, or
+// t's OnConflict column values when set, ignoring the conditions
+// accumulated on q.
+func (q *Query[T]) reloadByPK(ctx context.Context, t *T) error {
+	cols, vals := q.pkPairs(t)
+	if len(q.conflictCols) > 0 {
+		cols, vals = q.columnPairs(t, q.conflictCols)
+	}
+	if cols == nil {
+		return errors.New("orm: primary key value is required to reload the row")
+	}
+	v, err := q.lookup(cols, vals).First(ctx)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// reloadColumns reads the given columns of the row identified by t's
+// primary key back into t, leaving its other fields as they are.
+func (q *Query[T]) reloadColumns(ctx context.Context, t *T, columns []string) error {
+	cols, vals := q.pkPairs(t)
+	if cols == nil {
+		return errors.New("orm: primary key value is required to reload the row")
+	}
+	q2 := q.lookup(cols, vals).SelectColumns(columns...).Limit(1)
+	query, args := q2.buildSelect()
+	query, args = q2.rewrite(query, args)
+
+	rows, err := q.db.QueryContext(q.routed(ctx), query, args...)
 	if err != nil {
 		return err //nolint:wrapcheck // pass through
 	}
-
-	if q.setPK != nil {
-		firstID, err := result.LastInsertId()
-		if err != nil {
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return err //nolint:wrapcheck // pass through
 		}
-		for i, item := range items {
-			q.setPK(item, firstID+int64(i))
-		}
+		return ErrNotFound
 	}
-	return nil
+	if err := q.scanInto(rows, t); err != nil {
+		return err
+	}
+	return rows.Err() //nolint:wrapcheck // pass through
 }
 
-// Upsert inserts a row or updates it on primary key conflict.
-// All non-PK columns (except createdAt) are updated on conflict.
-// The primary key must be set on t before calling Upsert.
-func (q *Query[T]) Upsert(ctx context.Context, t *T) error {
-	q.applyTimestamps(ctx, t, true)
+// lookup returns an unfiltered copy of q matching the row whose cols equal
+// vals, for reading a just-written row back.
+func (q *Query[T]) lookup(cols []string, vals []any) *Query[T] {
+	q2 := q.clone()
+	q2.raw = nil
+	q2.wheres = nil
+	q2.joins = nil
+	q2.activeJoinNames = nil
+	q2.selects = nil
+	q2.selCols = nil
+	q2.orderBys = nil
+	q2.offset = nil
+	q2.preloads = nil
+	for i, col := range cols {
+		q2 = q2.Where(q.qi(q.table)+"."+q.qi(col)+" = ?", vals[i])
+	}
+	return q2
+}
 
-	columns, values := q.colValPairs(t, true) // always include PK
+// Update updates the row identified by the primary key of t.
+// All non-PK columns are SET, except server-managed timestamps. With a
+// version column registered, the row must also still have t's version,
+// which is incremented; otherwise Update returns ErrStaleObject.
+func (q *Query[T]) Update(ctx context.Context, t *T) error {
+	_, err := q.UpdateResult(ctx, t)
+	return err
+}
 
-	query := q.buildUpsert(columns)
-	query, values = q.rewrite(query, values)
+// UpdateResult is like Update but also returns the driver's sql.Result.
+// RowsAffected of 0 means no row has t's primary key, e.g. a stale write
+// under optimistic locking. MySQL by default counts only rows whose values
+// actually changed; connect with clientFoundRows=true to count matched rows.
+func (q *Query[T]) UpdateResult(ctx context.Context, t *T) (_ sql.Result, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
 
-	d := q.db.dialect()
-	if d.UseReturning() && q.setPK != nil {
-		query += d.ReturningClause(q.pk)
-		rows, err := q.db.QueryContext(ctx, query, values...)
-		if err != nil {
-			return err //nolint:wrapcheck // pass through
-		}
-		defer func() { _ = rows.Close() }()
-		if rows.Next() {
-			var id int64
-			if err := rows.Scan(&id); err != nil {
-				return err //nolint:wrapcheck // pass through
-			}
-			q.setPK(t, id)
-		}
-		return rows.Err() //nolint:wrapcheck // pass through
+	if err := q.checkWritable(); err != nil {
+		return nil, err
 	}
+	if err := q.validate(t); err != nil {
+		return nil, err
+	}
+	return q.updateRow(ctx, t, nil)
+}
 
-	_, err := q.db.ExecContext(ctx, query, values...)
-	return err //nolint:wrapcheck // pass through
+// UpdateColumns is like Update but SETs only cols, e.g. the fields a PATCH
+// request sent, and leaves the row's other columns untouched. Registered
+// updatedAt columns are set as well, as is the version column, which is
+// checked and incremented as with Update:
+//
+//	u.Name = "bob"
+//	Users(db).UpdateColumns(ctx, []string{"name"}, u)
+//	// → UPDATE `users` SET `name` = ?, `updated_at` = ? WHERE `id` = ?
+//
+// Naming a column the model does not write, such as the primary key, an
+// immutable or a server-managed column, is an error.
+func (q *Query[T]) UpdateColumns(ctx context.Context, cols []string, t *T) error {
+	_, err := q.UpdateColumnsResult(ctx, cols, t)
+	return err
 }
 
-// Update updates the row identified by the primary key of t.
-// All non-PK columns are SET.
-func (q *Query[T]) Update(ctx context.Context, t *T) error {
+// UpdateColumnsResult is like UpdateColumns but also returns the driver's
+// sql.Result.
+func (q *Query[T]) UpdateColumnsResult(ctx context.Context, cols []string, t *T) (_ sql.Result, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, errors.New("orm: UpdateColumns requires at least one column")
+	}
+	writable, _ := q.insertPairs(t, true)
+	for _, col := range cols {
+		if !slices.Contains(writable, col) || q.isPKCol(col) || col == q.versionCol ||
+			slices.Contains(q.immutableCols, col) {
+			return nil, fmt.Errorf("orm: UpdateColumns cannot set column %q", col)
+		}
+	}
+	return q.updateRow(ctx, t, cols)
+}
+
+// updateRow runs the UPDATE behind Update and UpdateColumns. A non-nil
+// only limits the SET list to those columns and the updatedAt columns.
+func (q *Query[T]) updateRow(ctx context.Context, t *T, only []string) (sql.Result, error) {
 	q.applyTimestamps(ctx, t, false)
 
-	allCols, allVals := q.colValPairs(t, true)
+	allCols, allVals := q.insertPairs(t, true)
 
 	var setCols []string
 	var setVals []any
-	var pkVal any
+	var version any
 	for i, col := range allCols {
-		if col == q.pk {
-			pkVal = allVals[i]
-		} else {
+		switch {
+		case col == q.versionCol:
+			version = allVals[i]
+		case only != nil && !slices.Contains(only, col) && !slices.Contains(q.updatedAtCols, col):
+		case !q.isPKCol(col) && !slices.Contains(q.immutableCols, col):
 			setCols = append(setCols, col)
 			setVals = append(setVals, allVals[i])
 		}
 	}
-	if pkVal == nil {
-		return errors.New("orm: primary key value is required for Update")
+	_, pkVals := q.pkPairs(t)
+	if pkVals == nil {
+		return nil, errors.New("orm: primary key value is required for Update")
 	}
 
-	setVals = append(setVals, pkVal)
+	setVals = append(setVals, pkVals...)
+	if q.versionCol != "" {
+		setVals = append(setVals, version)
+	}
 	query := q.buildUpdate(setCols)
 	query, setVals = q.rewrite(query, setVals)
 
-	_, err := q.db.ExecContext(ctx, query, setVals...)
-	return err //nolint:wrapcheck // pass through
+	res, err := q.db.ExecContext(q.routed(ctx), query, setVals...)
+	if err != nil || q.versionCol == "" {
+		return res, err //nolint:wrapcheck // pass through
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return res, err //nolint:wrapcheck // pass through
+	}
+	if n == 0 {
+		return res, ErrStaleObject
+	}
+	if q.bumpVersion != nil {
+		q.bumpVersion(t)
+	}
+	return res, nil
 }
 
 // Updates updates specific columns by map for rows matching the accumulated
@@ -496,8 +2138,31 @@ func (q *Query[T]) Update(ctx context.Context, t *T) error {
 // If updatedAt columns are registered and not present in values, they are
 // automatically added with the current time.
 func (q *Query[T]) Updates(ctx context.Context, values map[string]any) error {
+	_, err := q.UpdatesResult(ctx, values)
+	return err
+}
+
+// UpdatesResult is like Updates but also returns the driver's sql.Result,
+// e.g. to check RowsAffected.
+func (q *Query[T]) UpdatesResult(ctx context.Context, values map[string]any) (_ sql.Result, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	if err := q.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
 	if len(q.wheres) == 0 {
-		return errors.New("orm: Updates without WHERE clause is not allowed")
+		return nil, errors.New("orm: Updates without WHERE clause is not allowed")
+	}
+	// Applied after the guard: a default scope alone must not turn Updates
+	// into an update of every row it matches.
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return nil, q.err
 	}
 
 	if len(q.updatedAtCols) > 0 {
@@ -523,21 +2188,92 @@ func (q *Query[T]) Updates(ctx context.Context, values map[string]any) error {
 
 	query, args := q.rewrite(b.String(), setVals)
 
-	_, err := q.db.ExecContext(ctx, query, args...)
-	return err //nolint:wrapcheck // pass through
+	return q.db.ExecContext(q.routed(ctx), query, args...) //nolint:wrapcheck // pass through
 }
 
 // Delete deletes rows matching the accumulated WHERE clauses.
 // Returns an error if no WHERE clauses are set (safety guard).
+// With soft delete registered, it sets the deleted-at column instead;
+// Unscoped().Delete removes the rows.
 func (q *Query[T]) Delete(ctx context.Context) error {
+	_, err := q.DeleteResult(ctx)
+	return err
+}
+
+// DeleteResult is like Delete but also returns the driver's sql.Result,
+// e.g. to check RowsAffected.
+func (q *Query[T]) DeleteResult(ctx context.Context) (sql.Result, error) {
+	if err := q.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
 	if len(q.wheres) == 0 {
-		return errors.New("orm: Delete without WHERE clause is not allowed")
+		return nil, errors.New("orm: Delete without WHERE clause is not allowed (use DeleteAll to delete every row)")
+	}
+	return q.delete(ctx)
+}
+
+// DeleteAll is Delete without the WHERE guard: it deletes the rows matching
+// the accumulated WHERE clauses or, when there are none, every row in the
+// table. Use it where emptying the table is intended, e.g. a batch job whose
+// conditions are computed elsewhere and may be empty, so that the intent is
+// visible at the call site.
+func (q *Query[T]) DeleteAll(ctx context.Context) error {
+	if err := q.checkWritable(); err != nil {
+		return err
+	}
+
+	if q.err != nil {
+		return q.err
+	}
+	_, err := q.delete(ctx)
+	return err
+}
+
+func (q *Query[T]) delete(ctx context.Context) (_ sql.Result, err error) {
+	ctx, finish := withQueryTimeout(ctx)
+	defer func() { err = finish(err) }()
+
+	// Applied after Delete's WHERE guard, for the same reason as in Updates.
+	q = q.withDefaultScopes(ctx)
+	if q.err != nil {
+		return nil, q.err
 	}
 	query, args := q.buildDelete()
+	if q.softDeleting() {
+		query, args = q.buildSoftDelete(now(ctx))
+	}
 	query, args = q.rewrite(query, args)
 
-	_, err := q.db.ExecContext(ctx, query, args...)
-	return err //nolint:wrapcheck // pass through
+	return q.db.ExecContext(q.routed(ctx), query, args...) //nolint:wrapcheck // pass through
+}
+
+// validate runs the registered validator, if any, on each of items.
+func (q *Query[T]) validate(items ...*T) error {
+	if q.validator == nil {
+		return nil
+	}
+	for _, t := range items {
+		if err := q.validator(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWritable returns ErrReadOnlyModel for a read-only model and
+// ErrReadOnly when q is bound to a read-only transaction.
+func (q *Query[T]) checkWritable() error {
+	if q.readOnly {
+		return ErrReadOnlyModel
+	}
+	if tx, ok := q.db.(*Tx); ok && tx.readOnly {
+		return ErrReadOnly
+	}
+	return nil
 }
 
 // --- SQL building ---
@@ -547,6 +2283,32 @@ func (q *Query[T]) qi(name string) string {
 	return q.db.dialect().QuoteIdent(name)
 }
 
+// qiRef quotes a possibly table-qualified column reference, e.g.
+// "users.created_at" → `users`.`created_at`.
+func (q *Query[T]) qiRef(ref string) string {
+	parts := strings.Split(ref, ".")
+	for i, p := range parts {
+		parts[i] = q.qi(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// isColumnRef reports whether s is a bare or table-qualified column name,
+// e.g. "id" or "users.id", as opposed to an expression.
+func isColumnRef(s string) bool {
+	for part := range strings.SplitSeq(s, ".") {
+		if part == "" || part[0] >= '0' && part[0] <= '9' {
+			return false
+		}
+		for i := range len(part) {
+			if !isIdentByte(part[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // quoteColumns joins column names with dialect-aware quoting.
 func (q *Query[T]) quoteColumns(cols []string) string {
 	quoted := make([]string, len(cols))
@@ -566,31 +2328,102 @@ func (q *Query[T]) qualifiedColumns() string {
 	return strings.Join(quoted, ", ")
 }
 
-func (q *Query[T]) buildSelect() (string, []any) {
+// qualifiedSelCols renders the SelectColumns list of a joined query,
+// qualifying bare column names with the base table, since a joined table
+// may have a column of the same name.
+func (q *Query[T]) qualifiedSelCols() string {
+	quoted := make([]string, len(q.selCols))
+	for i, c := range q.selCols {
+		switch {
+		case !isColumnRef(c):
+			quoted[i] = c
+		case strings.Contains(c, "."):
+			quoted[i] = q.qiRef(c)
+		default:
+			quoted[i] = q.qi(q.table) + "." + q.qi(c)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// selectList returns the column list of the SELECT statement:
+//
+//   - a user-supplied Select(...) is used verbatim, with no qualification,
+//     except that Select("*") over a JOIN means the generated list below;
+//   - SelectColumns names without a table are qualified with the base
+//     table once a JOIN is active;
+//   - otherwise, when at least one JOIN is active, the base columns are
+//     qualified with the table name and joined SelectColumns are appended
+//     as "<name>__<col>" aliases, so that columns shared with the joined
+//     tables (e.g. "id") are never ambiguous. A to-many relation's columns
+//     are only selected when JoinPreload joined it;
+//   - otherwise the base columns are quoted but left unqualified.
+//
+// Registering a join does not qualify anything; only Join/LeftJoin with a
+// registered name does.
+func (q *Query[T]) selectList() string {
+	joined := len(q.joins) > 0
+	if q.selects != nil && (!joined || strings.TrimSpace(*q.selects) != "*") {
+		if joined && q.selCols != nil {
+			return q.qualifiedSelCols()
+		}
+		return *q.selects
+	}
+	if !joined {
+		return q.quoteColumns(q.columns)
+	}
+
 	var b strings.Builder
-	b.WriteString("SELECT ")
+	b.WriteString(q.qualifiedColumns())
+	for _, name := range q.activeJoinNames {
+		if q.mergers[name] != nil && name != q.joinedMany {
+			continue // an explicit to-many join filters; it does not scan
+		}
+		cfg := q.joinDefs[name]
+		for _, col := range cfg.SelectColumns {
+			b.WriteString(", ")
+			b.WriteString(q.joinTarget(name, cfg))
+			b.WriteByte('.')
+			b.WriteString(q.qi(col))
+			b.WriteString(" AS ")
+			b.WriteString(q.qi(name + "__" + col))
+		}
+	}
+	return b.String()
+}
 
-	if q.selects != nil {
-		b.WriteString(*q.selects)
-	} else if len(q.joins) > 0 {
-		b.WriteString(q.qualifiedColumns())
-		for _, name := range q.activeJoinNames {
-			cfg := q.joinDefs[name]
-			for _, col := range cfg.SelectColumns {
-				b.WriteString(", ")
-				b.WriteString(q.qi(cfg.TargetTable))
-				b.WriteByte('.')
-				b.WriteString(q.qi(col))
-				b.WriteString(" AS ")
-				b.WriteString(q.qi(name + "__" + col))
-			}
+func (q *Query[T]) buildSelect() (string, []any) {
+	var hints map[HintPlacement][]string
+	if len(q.hints) > 0 {
+		hints = make(map[HintPlacement][]string)
+		d := q.db.dialect()
+		for _, h := range q.hints {
+			p := d.HintPlacement(h)
+			hints[p] = append(hints[p], h)
 		}
-	} else {
-		b.WriteString(q.quoteColumns(q.columns))
 	}
 
+	var b strings.Builder
+	for _, h := range hints[HintBeforeSelect] {
+		b.WriteString(h)
+		b.WriteByte(' ')
+	}
+	b.WriteString("SELECT ")
+	for _, h := range hints[HintAfterSelect] {
+		b.WriteString(h)
+		b.WriteByte(' ')
+	}
+	if q.distinct {
+		b.WriteString("DISTINCT ")
+	}
+	b.WriteString(q.selectList())
+
 	b.WriteString(" FROM ")
 	b.WriteString(q.qi(q.table))
+	for _, h := range hints[HintAfterTable] {
+		b.WriteByte(' ')
+		b.WriteString(h)
+	}
 
 	for _, j := range q.joins {
 		b.WriteByte(' ')
@@ -598,25 +2431,76 @@ func (q *Query[T]) buildSelect() (string, []any) {
 	}
 
 	args := q.appendWhere(&b)
+	args = append(args, q.appendGroupBy(&b)...)
 
 	if len(q.orderBys) > 0 {
 		b.WriteString(" ORDER BY ")
 		b.WriteString(strings.Join(q.orderBys, ", "))
 	}
 
-	if q.limit != nil {
-		fmt.Fprintf(&b, " LIMIT %d", *q.limit)
+	b.WriteString(q.db.dialect().PaginationClause(q.limit, q.offset, len(q.orderBys) > 0))
+	if q.lock != lockNone {
+		b.WriteString(q.db.dialect().LockClause(q.lock == lockForUpdateSkipLocked))
+	}
+
+	return b.String(), args
+}
+
+// buildCount builds the COUNT query. A grouped query is counted from a
+// subquery so that the result is the number of groups, not of rows in the
+// first group. HAVING without GROUP BY filters the rows All selects, so
+// such a query is counted from that SELECT.
+func (q *Query[T]) buildCount(expr string) (string, []any) {
+	if len(q.groupBys) == 0 && len(q.havings) > 0 {
+		sel := q.selectList()
+		if q.distinct {
+			sel = "DISTINCT " + sel
+		}
+		inner, args := q.buildAggregate(sel)
+		var b strings.Builder
+		b.WriteString("SELECT COUNT(*) FROM (")
+		b.WriteString(inner)
+		args = append(args, q.appendGroupBy(&b)...)
+		b.WriteString(") AS filtered")
+		return b.String(), args
+	}
+	if q.distinct && expr == "*" && len(q.groupBys) == 0 {
+		inner, args := q.buildAggregate("DISTINCT " + q.selectList())
+		return "SELECT COUNT(*) FROM (" + inner + ") AS distinct_rows", args
 	}
-	if q.offset != nil {
-		fmt.Fprintf(&b, " OFFSET %d", *q.offset)
+	if len(q.groupBys) == 0 {
+		return q.buildAggregate("COUNT(" + expr + ")")
 	}
 
+	inner, args := q.buildAggregate(strings.Join(q.groupBys, ", "))
+	var b strings.Builder
+	b.WriteString("SELECT COUNT(*) FROM (")
+	b.WriteString(inner)
+	args = append(args, q.appendGroupBy(&b)...)
+	b.WriteString(") AS grouped")
+
+	return b.String(), args
+}
+
+// buildExists builds the SELECT behind Exists: a constant from at most one
+// matching row, or group with GROUP BY.
+func (q *Query[T]) buildExists() (string, []any) {
+	query, args := q.buildAggregate("1")
+	var b strings.Builder
+	b.WriteString(query)
+	args = append(args, q.appendGroupBy(&b)...)
+	one := 1
+	b.WriteString(q.db.dialect().PaginationClause(&one, nil, false))
 	return b.String(), args
 }
 
-func (q *Query[T]) buildCount() (string, []any) {
+// buildAggregate builds "SELECT <expr> FROM ..." with the query's joins and
+// WHERE clause but no GROUP BY, ORDER BY, or LIMIT, e.g. for COUNT(*).
+func (q *Query[T]) buildAggregate(expr string) (string, []any) {
 	var b strings.Builder
-	b.WriteString("SELECT COUNT(*) FROM ")
+	b.WriteString("SELECT ")
+	b.WriteString(expr)
+	b.WriteString(" FROM ")
 	b.WriteString(q.qi(q.table))
 
 	for _, j := range q.joins {
@@ -626,13 +2510,6 @@ func (q *Query[T]) buildCount() (string, []any) {
 
 	args := q.appendWhere(&b)
 
-	if q.limit != nil {
-		fmt.Fprintf(&b, " LIMIT %d", *q.limit)
-	}
-	if q.offset != nil {
-		fmt.Fprintf(&b, " OFFSET %d", *q.offset)
-	}
-
 	return b.String(), args
 }
 
@@ -684,39 +2561,88 @@ func (q *Query[T]) buildUpsert(columns []string) string {
 
 	var updateCols []string
 	for _, col := range columns {
-		if col != q.pk && !q.isCreatedAtCol(col) {
+		if !q.isPKCol(col) && !q.isCreatedAtCol(col) && !slices.Contains(q.conflictCols, col) &&
+			!slices.Contains(q.immutableCols, col) {
 			updateCols = append(updateCols, col)
 		}
 	}
 
+	target := q.primaryKeys()
+	if len(q.conflictCols) > 0 {
+		target = q.conflictCols
+	}
+
 	d := q.db.dialect()
 	if _, ok := d.(mysqlDialect); ok {
 		sets := make([]string, len(updateCols))
 		for i, col := range updateCols {
 			sets[i] = fmt.Sprintf("%s = VALUES(%s)", q.qi(col), q.qi(col))
+			if col == q.versionCol {
+				sets[i] = fmt.Sprintf("%s = %s + 1", q.qi(col), q.qi(col))
+			}
+		}
+		if len(sets) == 0 {
+			// Nothing to update: assign a key column to itself, a no-op.
+			sets = []string{fmt.Sprintf("%s = %s", q.qi(target[0]), q.qi(target[0]))}
 		}
 		fmt.Fprintf(&b, " ON DUPLICATE KEY UPDATE %s", strings.Join(sets, ", "))
 	} else {
 		sets := make([]string, len(updateCols))
 		for i, col := range updateCols {
 			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", q.qi(col), q.qi(col))
+			if col == q.versionCol {
+				sets[i] = fmt.Sprintf("%s = %s.%s + 1", q.qi(col), q.qi(q.table), q.qi(col))
+			}
+		}
+		if len(sets) == 0 {
+			// Nothing to update, but DO NOTHING would return no row for
+			// RETURNING; assign a key column to itself instead.
+			sets = []string{fmt.Sprintf("%s = %s.%s", q.qi(target[0]), q.qi(q.table), q.qi(target[0]))}
+		}
+		fmt.Fprintf(&b, " ON CONFLICT (%s) DO UPDATE SET %s", q.quoteColumns(target), strings.Join(sets, ", "))
+		if q.upsertWhere != nil {
+			b.WriteString(" WHERE ")
+			b.WriteString(q.upsertWhere.clause)
 		}
-		fmt.Fprintf(&b, " ON CONFLICT (%s) DO UPDATE SET %s", q.qi(q.pk), strings.Join(sets, ", "))
 	}
 
 	return b.String()
 }
 
+// buildInsertIgnore builds an INSERT that does nothing on conflict. MySQL
+// has no DO NOTHING, so the conflicting row's first key column is assigned
+// to itself instead, which leaves the row unchanged.
+func (q *Query[T]) buildInsertIgnore(columns []string) string {
+	query := q.buildInsert(columns)
+	if _, ok := q.db.dialect().(mysqlDialect); ok {
+		pk := q.qi(q.primaryKeys()[0])
+		return query + " ON DUPLICATE KEY UPDATE " + pk + " = " + pk
+	}
+	return query + " ON CONFLICT DO NOTHING"
+}
+
+// buildUpdate builds the UPDATE for one row, matched by primary key and,
+// with a version column, by version, which is incremented.
 func (q *Query[T]) buildUpdate(setCols []string) string {
-	sets := make([]string, len(setCols))
-	for i, col := range setCols {
-		sets[i] = q.qi(col) + " = ?"
+	sets := make([]string, 0, len(setCols)+1)
+	conds := make([]string, 0, 2)
+	if q.versionCol != "" {
+		sets = append(sets, q.qi(q.versionCol)+" = "+q.qi(q.versionCol)+" + 1")
+	}
+	for _, col := range setCols {
+		sets = append(sets, q.qi(col)+" = ?")
+	}
+	for _, col := range q.primaryKeys() {
+		conds = append(conds, q.qi(col)+" = ?")
+	}
+	if q.versionCol != "" {
+		conds = append(conds, q.qi(q.versionCol)+" = ?")
 	}
 	return fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s = ?",
+		"UPDATE %s SET %s WHERE %s",
 		q.qi(q.table),
 		strings.Join(sets, ", "),
-		q.qi(q.pk),
+		strings.Join(conds, " AND "),
 	)
 }
 
@@ -740,14 +2666,90 @@ func (q *Query[T]) buildDelete() (string, []any) {
 	return b.String(), args
 }
 
+// buildSoftDelete builds the UPDATE that Delete runs under soft delete. The
+// soft-delete filter in the WHERE clause leaves already deleted rows, and
+// their deletion time, untouched.
+func (q *Query[T]) buildSoftDelete(deletedAt time.Time) (string, []any) {
+	var b strings.Builder
+	b.WriteString(q.buildUpdateMap([]string{q.softDeleteCol}))
+	args := append([]any{deletedAt}, q.appendWhere(&b)...)
+	return b.String(), args
+}
+
+// softDeleting reports whether q filters and soft-deletes rows.
+func (q *Query[T]) softDeleting() bool {
+	return q.softDeleteCol != "" && !q.unscoped
+}
+
+// isParenthesized reports whether clause is wrapped in one pair of
+// parentheses as a whole, e.g. "(a OR b)" but not "(a) OR (b)".
+func isParenthesized(clause string) bool {
+	if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(clause); {
+		switch clause[i] {
+		case '\'':
+			i += quotedLen(clause[i:], "'")
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(clause)-1
+			}
+		}
+		i++
+	}
+	return false
+}
+
+// joinWheres ANDs wheres into a single clause, with args in placeholder
+// order. It reports false when wheres is empty.
+func joinWheres(wheres []whereClause) (whereClause, bool) {
+	switch len(wheres) {
+	case 0:
+		return whereClause{}, false
+	case 1:
+		return wheres[0], true
+	}
+	clauses := make([]string, len(wheres))
+	var args []any
+	for i, w := range wheres {
+		clauses[i] = w.clause
+		args = append(args, w.args...)
+	}
+	return whereClause{strings.Join(clauses, " AND "), args}, true
+}
+
+// groupWheres is joinWheres with the result parenthesized, so that a
+// condition ANDed after it cannot bind to one side of an OR within it.
+func groupWheres(wheres []whereClause) (whereClause, bool) {
+	w, ok := joinWheres(wheres)
+	if ok && !isParenthesized(w.clause) {
+		w.clause = "(" + w.clause + ")"
+	}
+	return w, ok
+}
+
 func (q *Query[T]) appendWhere(b *strings.Builder) []any {
-	if len(q.wheres) == 0 {
+	wheres := q.wheres
+	if q.softDeleting() {
+		filter := whereClause{clause: q.qi(q.table) + "." + q.qi(q.softDeleteCol) + " IS NULL"}
+		wheres = []whereClause{filter}
+		if w, ok := groupWheres(q.wheres); ok {
+			wheres = []whereClause{w, filter}
+		}
+	}
+	if len(wheres) == 0 {
 		return nil
 	}
 
 	var args []any
 	b.WriteString(" WHERE ")
-	for i, w := range q.wheres {
+	for i, w := range wheres {
 		if i > 0 {
 			b.WriteString(" AND ")
 		}
@@ -757,26 +2759,26 @@ func (q *Query[T]) appendWhere(b *strings.Builder) []any {
 	return args
 }
 
+// appendGroupBy writes the GROUP BY and HAVING clauses and returns the
+// HAVING args, which follow the WHERE args in placeholder order.
+func (q *Query[T]) appendGroupBy(b *strings.Builder) []any {
+	if len(q.groupBys) > 0 {
+		b.WriteString(" GROUP BY ")
+		b.WriteString(strings.Join(q.groupBys, ", "))
+	}
+	h, ok := joinWheres(q.havings)
+	if !ok {
+		return nil
+	}
+	b.WriteString(" HAVING ")
+	b.WriteString(h.clause)
+	return h.args
+}
+
 // rewrite converts ? placeholders to dialect-specific placeholders.
 // For MySQL this is a no-op. For PostgreSQL, ? becomes $1, $2, etc.
 func (q *Query[T]) rewrite(query string, args []any) (string, []any) {
-	d := q.db.dialect()
-	if _, ok := d.(mysqlDialect); ok {
-		return query, args
-	}
-
-	var b strings.Builder
-	b.Grow(len(query))
-	idx := 1
-	for i := range len(query) {
-		if query[i] == '?' {
-			b.WriteString(d.Placeholder(idx))
-			idx++
-		} else {
-			b.WriteByte(query[i])
-		}
-	}
-	return b.String(), args
+	return rewritePlaceholders(q.db.dialect(), query), args
 }
 
 // applyTimestamps sets createdAt and/or updatedAt on t using the Clock
@@ -794,6 +2796,126 @@ func (q *Query[T]) applyTimestamps(ctx context.Context, t *T, isCreate bool) {
 	}
 }
 
+// insertPairs is colValPairs without the server-managed timestamp columns,
+// which the database fills in itself.
+func (q *Query[T]) insertPairs(t *T, includesPK bool) ([]string, []any) {
+	columns, values := q.colValPairs(t, includesPK)
+	if len(q.serverTimestampCols) == 0 {
+		return columns, values
+	}
+	keptCols := make([]string, 0, len(columns))
+	keptVals := make([]any, 0, len(values))
+	for i, col := range columns {
+		if !q.isServerTimestampCol(col) {
+			keptCols = append(keptCols, col)
+			keptVals = append(keptVals, values[i])
+		}
+	}
+	return keptCols, keptVals
+}
+
+// useReturning reports whether inserts read values back via RETURNING:
+// the generated primary key and any server-managed timestamps.
+func (q *Query[T]) useReturning(d Dialect) bool {
+	return d.UseReturning() && (q.setPK != nil || q.serverTimestamps != nil)
+}
+
+// returningClause extends the dialect's RETURNING clause for the primary
+// key with the server-managed timestamp columns.
+func (q *Query[T]) returningClause(d Dialect) string {
+	clause := d.ReturningClause(q.primaryKeys()[0])
+	if q.serverTimestamps == nil {
+		return clause
+	}
+	for _, col := range q.serverTimestampCols {
+		clause += ", " + q.qi(col)
+	}
+	return clause
+}
+
+// scanReturning scans a row produced by returningClause into t.
+func (q *Query[T]) scanReturning(rows *sql.Rows, t *T) error {
+	var id int64
+	var ignored any
+	dest := []any{&ignored}
+	if q.setPK != nil {
+		dest[0] = &id
+	}
+	if q.serverTimestamps != nil {
+		dest = append(dest, q.serverTimestamps(t)...)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	if q.setPK != nil {
+		q.setPK(t, id)
+	}
+	return nil
+}
+
+// primaryKeys returns the primary key columns: the composite key if one is
+// registered, otherwise the single pk column.
+func (q *Query[T]) primaryKeys() []string {
+	if len(q.pkCols) > 0 {
+		return q.pkCols
+	}
+	return []string{q.pk}
+}
+
+func (q *Query[T]) isPKCol(col string) bool {
+	for _, c := range q.primaryKeys() {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// pkPairs returns the primary key columns of t with their values, in
+// primaryKeys order, or nils if t's column values lack one of them.
+func (q *Query[T]) pkPairs(t *T) ([]string, []any) {
+	if len(q.pkCols) == 0 {
+		v := q.pkValue(t)
+		if v == nil {
+			return nil, nil
+		}
+		return q.primaryKeys(), []any{v}
+	}
+	return q.columnPairs(t, q.pkCols)
+}
+
+// columnPairs returns names with t's values for them, or nils if t's
+// column values lack one of them.
+func (q *Query[T]) columnPairs(t *T, names []string) ([]string, []any) {
+	if q.colValPairs == nil {
+		return nil, nil
+	}
+	cols, vals := q.colValPairs(t, true)
+	out := make([]any, len(names))
+	for i, name := range names {
+		found := false
+		for j, c := range cols {
+			if c == name {
+				out[i], found = vals[j], true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+	return names, out
+}
+
+func (q *Query[T]) isServerTimestampCol(col string) bool {
+	for _, c := range q.serverTimestampCols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
 func (q *Query[T]) isCreatedAtCol(col string) bool {
 	for _, c := range q.createdAtCols {
 		if c == col {
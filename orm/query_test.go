@@ -3,7 +3,11 @@ package orm_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -54,6 +58,40 @@ func TestBuildSelectAll(t *testing.T) {
 	}
 }
 
+func TestBuildSelectRaw(t *testing.T) {
+	t.Parallel()
+
+	const raw = "WITH ranked AS (SELECT id, name, ROW_NUMBER() OVER (ORDER BY id) AS rn FROM users WHERE name <> ?) " +
+		"SELECT id, name FROM ranked WHERE rn <= ?"
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, raw},
+		{orm.PostgreSQL, "WITH ranked AS (SELECT id, name, ROW_NUMBER() OVER (ORDER BY id) AS rn FROM users WHERE name <> $1) " +
+			"SELECT id, name FROM ranked WHERE rn <= $2"},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq).Where("id = ?", 9).OrderBy("name").Limit(5).Raw(raw, "bob", 3)
+
+		_, _ = q.All(t.Context())
+		_, _ = q.First(t.Context())
+
+		if len(tq.Queries) != 2 {
+			t.Fatalf("queries = %d, want 2", len(tq.Queries))
+		}
+		for _, got := range tq.Queries {
+			if got.SQL != tt.want {
+				t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+			}
+			if len(got.Args) != 2 || got.Args[0] != "bob" || got.Args[1] != 3 {
+				t.Errorf("Args = %v, want [bob 3]", got.Args)
+			}
+		}
+	}
+}
+
 func TestBuildSelectWhere(t *testing.T) {
 	t.Parallel()
 
@@ -105,6 +143,29 @@ func TestBuildSelectOrderBy(t *testing.T) {
 	}
 }
 
+func TestBuildSelectReorderBy(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	base := newTestQuery(tq).Scopes(scope.OrderBy("id DESC")).OrderBy("name")
+
+	_, _ = base.ReorderBy("name ASC").All(t.Context())
+	if got, want := tq.LastQuery().SQL, "SELECT `id`, `name` FROM `users` ORDER BY name ASC"; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+
+	_, _ = base.ReorderBy("").All(t.Context())
+	if got, want := tq.LastQuery().SQL, "SELECT `id`, `name` FROM `users`"; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+
+	// The base query keeps its order.
+	_, _ = base.All(t.Context())
+	if got, want := tq.LastQuery().SQL, "SELECT `id`, `name` FROM `users` ORDER BY id DESC, name"; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
+
 func TestBuildSelectLimitOffset(t *testing.T) {
 	t.Parallel()
 
@@ -120,6 +181,160 @@ func TestBuildSelectLimitOffset(t *testing.T) {
 	}
 }
 
+func TestBuildSelectLimitOffsetEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		query   func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want    string
+	}{
+		{
+			name:    "offset without limit on MySQL",
+			dialect: orm.MySQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Offset(20) },
+			want:    "SELECT `id`, `name` FROM `users` LIMIT 18446744073709551615 OFFSET 20",
+		},
+		{
+			name:    "offset without limit on PostgreSQL",
+			dialect: orm.PostgreSQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Offset(20) },
+			want:    `SELECT "id", "name" FROM "users" OFFSET 20`,
+		},
+		{
+			name:    "limit 0 on MySQL",
+			dialect: orm.MySQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Limit(0) },
+			want:    "SELECT `id`, `name` FROM `users` LIMIT 0",
+		},
+		{
+			name:    "limit 0 on PostgreSQL",
+			dialect: orm.PostgreSQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Limit(0) },
+			want:    `SELECT "id", "name" FROM "users" LIMIT 0`,
+		},
+		{
+			name:    "negative limit removes limit",
+			dialect: orm.MySQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Limit(10).Limit(-1) },
+			want:    "SELECT `id`, `name` FROM `users`",
+		},
+		{
+			name:    "negative limit keeps offset",
+			dialect: orm.MySQL,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Limit(10).Offset(5).Limit(-1) },
+			want:    "SELECT `id`, `name` FROM `users` LIMIT 18446744073709551615 OFFSET 5",
+		},
+		{
+			name:    "limit and offset on SQL Server",
+			dialect: orm.SQLServer,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.OrderBy("name").Limit(10).Offset(20) },
+			want:    "SELECT [id], [name] FROM [users] ORDER BY name OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:    "limit without order on SQL Server",
+			dialect: orm.SQLServer,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Where("name = ?", "alice").Limit(1) },
+			want:    "SELECT [id], [name] FROM [users] WHERE name = @p1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY",
+		},
+		{
+			name:    "offset without limit on SQL Server",
+			dialect: orm.SQLServer,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Offset(20) },
+			want:    "SELECT [id], [name] FROM [users] ORDER BY (SELECT NULL) OFFSET 20 ROWS",
+		},
+		{
+			name:    "no pagination on SQL Server",
+			dialect: orm.SQLServer,
+			query:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.OrderBy("name") },
+			want:    "SELECT [id], [name] FROM [users] ORDER BY name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			_, _ = tt.query(newTestQuery(tq)).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSelectAllResetsSelect(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.Select("*").Where("id = ?", 1).SelectAll().All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users` WHERE id = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectHint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		hints   []string
+		want    string
+	}{
+		{
+			name:    "MySQL optimizer hint after SELECT",
+			dialect: orm.MySQL,
+			hints:   []string{"/*+ MAX_EXECUTION_TIME(1000) */"},
+			want:    "SELECT /*+ MAX_EXECUTION_TIME(1000) */ `id`, `name` FROM `users` WHERE id = ?",
+		},
+		{
+			name:    "MySQL index hint after table",
+			dialect: orm.MySQL,
+			hints:   []string{"USE INDEX (idx_users_name)"},
+			want:    "SELECT `id`, `name` FROM `users` USE INDEX (idx_users_name) WHERE id = ?",
+		},
+		{
+			name:    "MySQL mixed hints keep their order",
+			dialect: orm.MySQL,
+			hints:   []string{"FORCE INDEX (a)", "/*+ NO_ICP(users) */", "IGNORE INDEX (b)"},
+			want:    "SELECT /*+ NO_ICP(users) */ `id`, `name` FROM `users` FORCE INDEX (a) IGNORE INDEX (b) WHERE id = ?",
+		},
+		{
+			name:    "PostgreSQL hint prefixes statement",
+			dialect: orm.PostgreSQL,
+			hints:   []string{"/*+ SeqScan(users) */"},
+			want:    `/*+ SeqScan(users) */ SELECT "id", "name" FROM "users" WHERE id = $1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestQuery(tq).Where("id = ?", 1)
+			for _, h := range tt.hints {
+				q = q.Hint(h)
+			}
+
+			_, _ = q.All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBuildSelectCustomColumns(t *testing.T) {
 	t.Parallel()
 
@@ -135,6 +350,49 @@ func TestBuildSelectCustomColumns(t *testing.T) {
 	}
 }
 
+func TestBuildSelectColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		build   func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want    string
+	}{
+		{"MySQL", orm.MySQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.SelectColumns("id", "name") },
+			"SELECT `id`, `name` FROM `users`"},
+		{"PostgreSQL reserved word", orm.PostgreSQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.SelectColumns("id", "order") },
+			`SELECT "id", "order" FROM "users"`},
+		{"qualified and raw expressions", orm.MySQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.SelectColumns("users.name", "COUNT(*) AS n", "*").GroupBy("users.name")
+			},
+			"SELECT `users`.`name`, COUNT(*) AS n, * FROM `users` GROUP BY users.name"},
+		{"scope", orm.SQLServer,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Scopes(scope.Select("id", "LOWER(name) AS name"))
+			},
+			"SELECT [id], LOWER(name) AS name FROM [users]"},
+		{"Select stays raw", orm.MySQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.SelectColumns("id").Select("COUNT(*)") },
+			"SELECT COUNT(*) FROM `users`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			_, _ = tt.build(newTestQuery(tq)).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBuildSelectFull(t *testing.T) {
 	t.Parallel()
 
@@ -199,6 +457,59 @@ func TestQueryImmutability(t *testing.T) {
 	}
 }
 
+func TestQueryConcurrentDerive(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	base := newTestQuery(tq)
+	base.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable: "posts", TargetColumn: "user_id", SourceTable: "users", SourceColumn: "id",
+	})
+	base = base.Where("name = ?", "alice").OrderBy("id").Limit(10)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = base.Where("id > ?", i).Limit(i).Offset(i).Select("id").All(t.Context())
+
+			c := base.Clone()
+			c.RegisterJoin("Tags", orm.JoinConfig{
+				TargetTable: "tags", TargetColumn: "user_id", SourceTable: "users", SourceColumn: "id",
+			})
+			c.ApplyLimit(i)
+			_, _ = c.Join("Tags").All(t.Context())
+		}()
+	}
+	wg.Wait()
+
+	if len(tq.Queries) != 2*n {
+		t.Fatalf("got %d queries, want %d", len(tq.Queries), 2*n)
+	}
+	for _, got := range tq.Queries {
+		var prefix string
+		if len(got.Args) == 2 {
+			prefix = "SELECT id FROM `users` WHERE name = ? AND id > ? ORDER BY id LIMIT "
+		} else {
+			prefix = "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `tags` ON `tags`.`user_id` = `users`.`id` " +
+				"WHERE name = ? ORDER BY id LIMIT "
+		}
+		if !strings.HasPrefix(got.SQL, prefix) {
+			t.Errorf("SQL = %q, want prefix %q", got.SQL, prefix)
+		}
+	}
+
+	// The base query kept its own conditions and registrations: Tags is
+	// unknown to it, so Join("Tags") adds nothing.
+	_, _ = base.Join("Tags").All(t.Context())
+	want := "SELECT `id`, `name` FROM `users` WHERE name = ? ORDER BY id LIMIT 10"
+	if got := tq.LastQuery(); got.SQL != want {
+		t.Errorf("base query was mutated: SQL = %q, want %q", got.SQL, want)
+	}
+}
+
 // --- INSERT ---
 
 func TestBuildInsertMySQL(t *testing.T) {
@@ -236,493 +547,3854 @@ func TestBuildInsertPostgreSQL(t *testing.T) {
 	}
 }
 
-// --- UPDATE ---
-
-func TestBuildUpdate(t *testing.T) {
+func TestBuildCreateResult(t *testing.T) {
 	t.Parallel()
 
 	tq := orm.NewTestQuerier(orm.MySQL)
 	q := newTestQuery(tq)
 
-	u := testUser{ID: 1, Name: "bob"}
-	_ = q.Update(t.Context(), &u)
+	u := testUser{Name: "alice"}
+	result, err := q.CreateResult(t.Context(), &u)
+	if err != nil {
+		t.Fatalf("CreateResult: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil sql.Result on the LastInsertId path")
+	}
 
 	got := tq.LastQuery()
-	want := "UPDATE `users` SET `name` = ? WHERE `id` = ?"
+	want := "INSERT INTO `users` (`name`) VALUES (?)"
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
-	if len(got.Args) != 2 || got.Args[0] != "bob" || got.Args[1] != 1 {
-		t.Errorf("Args = %v", got.Args)
-	}
 }
 
-func TestBuildUpdatePostgreSQL(t *testing.T) {
+func TestBuildCreateResultWithoutSetPKPostgreSQL(t *testing.T) {
 	t.Parallel()
 
 	tq := orm.NewTestQuerier(orm.PostgreSQL)
-	q := newTestQuery(tq)
+	q := orm.NewQuery[testUser](tq, "users", testUserColumns, "id", scanTestUser, testUserColValPairs, nil)
 
-	u := testUser{ID: 1, Name: "bob"}
-	_ = q.Update(t.Context(), &u)
+	u := testUser{ID: 7, Name: "alice"}
+	result, err := q.CreateResult(t.Context(), &u)
+	if err != nil {
+		t.Fatalf("CreateResult: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil sql.Result when RETURNING is not used")
+	}
 
 	got := tq.LastQuery()
-	want := `UPDATE "users" SET "name" = $1 WHERE "id" = $2`
+	want := `INSERT INTO "users" ("id", "name") VALUES ($1, $2)`
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
 }
 
-// --- DELETE ---
-
-func TestBuildDelete(t *testing.T) {
+func TestBuildInsertIgnore(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		setPK   orm.SetPKFunc[testUser]
+		want    string
+	}{
+		{"MySQL", orm.MySQL, setTestUserPK, "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `id` = `id`"},
+		{"PostgreSQL", orm.PostgreSQL, setTestUserPK, `INSERT INTO "users" ("name") VALUES ($1) ON CONFLICT DO NOTHING RETURNING "id"`},
+		{"PostgreSQL without setPK", orm.PostgreSQL, nil, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT DO NOTHING`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := orm.NewQuery[testUser](tq, "users", testUserColumns, "id", scanTestUser, testUserColValPairs, tt.setPK)
 
-	_ = q.Where("id = ?", 1).Delete(t.Context())
+		_ = q.InsertIgnore(t.Context(), &testUser{ID: 1, Name: "alice"})
 
-	got := tq.LastQuery()
-	want := "DELETE FROM `users` WHERE id = ?"
-	if got.SQL != want {
-		t.Errorf("SQL = %q, want %q", got.SQL, want)
+		if got := tq.LastQuery().SQL; got != tt.want {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got, tt.want)
+		}
 	}
 }
 
-func TestDeleteWithoutWhereReturnsError(t *testing.T) {
+func TestInsertIgnoreSetsPKOnlyWhenInserted(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		wantID       int
+	}{
+		{"inserted", 1, 5},
+		{"skipped", 0, 0},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(orm.MySQL)
+		tq.RowsAffected = tt.rowsAffected
+		tq.InsertIDs = []int64{5}
+
+		u := testUser{Name: "alice"}
+		if err := newTestQuery(tq).InsertIgnore(t.Context(), &u); err != nil {
+			t.Fatalf("%s: InsertIgnore: %v", tt.name, err)
+		}
+		if u.ID != tt.wantID {
+			t.Errorf("%s: ID = %d, want %d", tt.name, u.ID, tt.wantID)
+		}
+	}
+}
+
+func TestCreateDatabaseAssignedPKOnSQLServer(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.SQLServer)
 	q := newTestQuery(tq)
+	if err := q.Create(t.Context(), &testUser{Name: "alice"}); err == nil {
+		t.Error("Create with a database-assigned key on SQL Server: want an error")
+	}
+	if err := q.CreateAll(t.Context(), []*testUser{{Name: "alice"}, {Name: "bob"}}); err == nil {
+		t.Error("CreateAll with a database-assigned key on SQL Server: want an error")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("ran %d queries, want none", len(tq.Queries))
+	}
 
-	err := q.Delete(t.Context())
-	if err == nil {
-		t.Fatal("expected error for Delete without WHERE, got nil")
+	// A client-assigned key is inserted as is.
+	q = orm.NewQuery[testUser](tq, "users", testUserColumns, "id", scanTestUser, testUserColValPairs, nil)
+	if err := q.Create(t.Context(), &testUser{ID: 1, Name: "alice"}); err != nil {
+		t.Errorf("Create: %v", err)
+	}
+	if got, want := tq.LastQuery().SQL, "INSERT INTO [users] ([id], [name]) VALUES (@p1, @p2)"; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
 	}
 }
 
-// --- Rewrite (PostgreSQL placeholders) ---
+// --- UPDATE ---
 
-func TestRewritePostgreSQLSelect(t *testing.T) {
+func TestBuildUpdate(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	tq := orm.NewTestQuerier(orm.MySQL)
 	q := newTestQuery(tq)
 
-	_, _ = q.Where("name = ?", "alice").Where("id > ?", 10).All(t.Context())
+	u := testUser{ID: 1, Name: "bob"}
+	_ = q.Update(t.Context(), &u)
 
 	got := tq.LastQuery()
-	want := `SELECT "id", "name" FROM "users" WHERE name = $1 AND id > $2`
+	want := "UPDATE `users` SET `name` = ? WHERE `id` = ?"
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
+	if len(got.Args) != 2 || got.Args[0] != "bob" || got.Args[1] != 1 {
+		t.Errorf("Args = %v", got.Args)
+	}
 }
 
-// --- First ---
-
-func TestFirstAddsLimit(t *testing.T) {
+func TestBuildUpdatePostgreSQL(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
 	q := newTestQuery(tq)
 
-	_, _ = q.First(t.Context())
+	u := testUser{ID: 1, Name: "bob"}
+	_ = q.Update(t.Context(), &u)
 
 	got := tq.LastQuery()
-	want := "SELECT `id`, `name` FROM `users` LIMIT 1"
+	want := `UPDATE "users" SET "name" = $1 WHERE "id" = $2`
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
 }
 
-// --- Timestamp tests ---
+// --- composite primary key ---
 
-type testArticle struct {
-	ID        int
-	Title     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+type testMembership struct {
+	TenantID int
+	UserID   int
+	Role     string
 }
 
-var testArticleColumns = []string{"id", "title", "created_at", "updated_at"}
-
-func scanTestArticle(_ *sql.Rows) (testArticle, error) {
-	return testArticle{}, nil
+func scanTestMembership(_ *sql.Rows) (testMembership, error) {
+	return testMembership{}, nil
 }
 
-func testArticleColValPairs(a *testArticle, includesPK bool) ([]string, []any) {
+func testMembershipColValPairs(m *testMembership, includesPK bool) ([]string, []any) {
 	if includesPK {
-		return []string{"id", "title", "created_at", "updated_at"},
-			[]any{a.ID, a.Title, a.CreatedAt, a.UpdatedAt}
-	}
-	return []string{"title", "created_at", "updated_at"},
-		[]any{a.Title, a.CreatedAt, a.UpdatedAt}
-}
-
-func setTestArticlePK(a *testArticle, id int64) {
-	a.ID = int(id)
-}
-
-func setTestArticleCreatedAt(a *testArticle, now time.Time) {
-	if a.CreatedAt.IsZero() {
-		a.CreatedAt = now
+		return []string{"tenant_id", "user_id", "role"}, []any{m.TenantID, m.UserID, m.Role}
 	}
+	return []string{"role"}, []any{m.Role}
 }
 
-func setTestArticleUpdatedAt(a *testArticle, now time.Time) {
-	a.UpdatedAt = now
-}
-
-func newTestArticleQuery(tq *orm.TestQuerier) *orm.Query[testArticle] {
-	q := orm.NewQuery[testArticle](tq, "articles", testArticleColumns, "id", scanTestArticle, testArticleColValPairs, setTestArticlePK)
-	q.RegisterTimestamps([]string{"created_at"}, setTestArticleCreatedAt, []string{"updated_at"}, setTestArticleUpdatedAt)
+func newTestMembershipQuery(tq *orm.TestQuerier) *orm.Query[testMembership] {
+	q := orm.NewQuery[testMembership](tq, "memberships", []string{"tenant_id", "user_id", "role"}, "",
+		scanTestMembership, testMembershipColValPairs, nil)
+	q.RegisterCompositePK("tenant_id", "user_id")
 	return q
 }
 
-type fixedClock struct {
-	t time.Time
-}
-
-func (c fixedClock) Now() time.Time { return c.t }
-
-func TestUpsertExcludesCreatedAtFromUpdate(t *testing.T) {
+func TestBuildUpdateCompositePK(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestArticleQuery(tq)
-
-	a := testArticle{ID: 1, Title: "hello"}
-	_ = q.Upsert(t.Context(), &a)
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "UPDATE `memberships` SET `role` = ? WHERE `tenant_id` = ? AND `user_id` = ?"},
+		{orm.PostgreSQL, `UPDATE "memberships" SET "role" = $1 WHERE "tenant_id" = $2 AND "user_id" = $3`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		m := testMembership{TenantID: 1, UserID: 2, Role: "admin"}
+		if err := newTestMembershipQuery(tq).Update(t.Context(), &m); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
 
-	got := tq.LastQuery()
-	// UPDATE clause should NOT contain created_at
-	if strings.Contains(got.SQL, "ON DUPLICATE KEY UPDATE") {
-		updatePart := got.SQL[strings.Index(got.SQL, "ON DUPLICATE KEY UPDATE"):]
-		if strings.Contains(updatePart, "created_at") {
-			t.Errorf("UPDATE clause should not contain created_at: %s", got.SQL)
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
 		}
-		if !strings.Contains(updatePart, "updated_at") {
-			t.Errorf("UPDATE clause should contain updated_at: %s", got.SQL)
+		if len(got.Args) != 3 || got.Args[0] != "admin" || got.Args[1] != 1 || got.Args[2] != 2 {
+			t.Errorf("Args = %v", got.Args)
 		}
-	} else {
-		t.Errorf("expected ON DUPLICATE KEY UPDATE in SQL: %s", got.SQL)
 	}
 }
 
-func TestUpsertExcludesCreatedAtPostgreSQL(t *testing.T) {
+func TestBuildUpsertCompositePK(t *testing.T) {
 	t.Parallel()
 
 	tq := orm.NewTestQuerier(orm.PostgreSQL)
-	q := newTestArticleQuery(tq)
-
-	a := testArticle{ID: 1, Title: "hello"}
-	_ = q.Upsert(t.Context(), &a)
+	m := testMembership{TenantID: 1, UserID: 2, Role: "admin"}
+	if err := newTestMembershipQuery(tq).Upsert(t.Context(), &m); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
 
 	got := tq.LastQuery()
-	// DO UPDATE SET should NOT contain created_at
-	if strings.Contains(got.SQL, "DO UPDATE SET") {
-		updatePart := got.SQL[strings.Index(got.SQL, "DO UPDATE SET"):]
-		if strings.Contains(updatePart, "created_at") {
-			t.Errorf("UPDATE SET should not contain created_at: %s", got.SQL)
-		}
-		if !strings.Contains(updatePart, "updated_at") {
-			t.Errorf("UPDATE SET should contain updated_at: %s", got.SQL)
-		}
-	} else {
-		t.Errorf("expected DO UPDATE SET in SQL: %s", got.SQL)
+	want := `INSERT INTO "memberships" ("tenant_id", "user_id", "role") VALUES ($1, $2, $3)` +
+		` ON CONFLICT ("tenant_id", "user_id") DO UPDATE SET "role" = EXCLUDED."role"`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
 }
 
-func TestCreateAutoSetsTimestamps(t *testing.T) {
+func TestAllByIDRejectsCompositePK(t *testing.T) {
 	t.Parallel()
 
-	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
-	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
-
 	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestArticleQuery(tq)
-
-	a := testArticle{Title: "hello"}
-	_ = q.Create(ctx, &a)
-
-	if a.CreatedAt != fixed {
-		t.Errorf("CreatedAt = %v, want %v", a.CreatedAt, fixed)
+	if _, err := orm.AllByID[int](t.Context(), newTestMembershipQuery(tq)); err == nil {
+		t.Error("AllByID: expected error for composite primary key, got nil")
 	}
-	if a.UpdatedAt != fixed {
-		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	if _, err := orm.ExistingIDs(t.Context(), newTestMembershipQuery(tq), []int{1}); err == nil {
+		t.Error("ExistingIDs: expected error for composite primary key, got nil")
 	}
 }
 
-func TestCreatePreservesExistingCreatedAt(t *testing.T) {
+func TestAllByIDRejectsMissingPK(t *testing.T) {
 	t.Parallel()
 
-	existing := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
-	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
-	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
-
 	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestArticleQuery(tq)
-
-	a := testArticle{Title: "hello", CreatedAt: existing}
-	_ = q.Create(ctx, &a)
+	q := orm.NewQuery[testUser](tq, "users", testUserColumns, "", scanTestUser, nil, nil)
+	q.RegisterReadOnly()
 
-	if a.CreatedAt != existing {
-		t.Errorf("CreatedAt = %v, want %v (should not be overwritten)", a.CreatedAt, existing)
+	_, err := orm.AllByID[int](t.Context(), q)
+	if err == nil || !strings.Contains(err.Error(), "requires a single-column primary key") {
+		t.Errorf("err = %v, want a primary key error", err)
 	}
-	if a.UpdatedAt != fixed {
-		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
 	}
 }
 
-func TestUpdateOnlySetsUpdatedAt(t *testing.T) {
+func TestBuildFindByID(t *testing.T) {
 	t.Parallel()
 
-	existing := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
-	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
-	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
-
 	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestArticleQuery(tq)
-
-	a := testArticle{ID: 1, Title: "hello", CreatedAt: existing}
-	_ = q.Update(ctx, &a)
+	_, _ = orm.FindByID(t.Context(), newTestQuery(tq), 42)
 
-	if a.CreatedAt != existing {
-		t.Errorf("CreatedAt = %v, want %v (Update should not touch createdAt)", a.CreatedAt, existing)
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users` WHERE `users`.`id` = ? LIMIT 1"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
-	if a.UpdatedAt != fixed {
-		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	if len(got.Args) != 1 || got.Args[0] != 42 {
+		t.Errorf("Args = %v, want [42]", got.Args)
 	}
 }
 
-// --- scope.Join / scope.LeftJoin / scope.Preload via Scopes ---
-
-func TestBuildSelectWithScopeJoin(t *testing.T) {
+func TestBuildFindByIDPostgreSQLStringKey(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
-	q.RegisterJoin("Posts", orm.JoinConfig{
-		TargetTable:  "posts",
-		TargetColumn: "user_id",
-		SourceTable:  "users",
-		SourceColumn: "id",
-	})
-
-	_, _ = q.Scopes(scope.Join("Posts")).All(t.Context())
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	id := "0b6f3c1e-6d4a-4a55-9d0e-3f7a2c1b8e90"
+	_, _ = orm.FindByID(t.Context(), newTestQuery(tq).Where("name = ?", "alice"), id)
 
 	got := tq.LastQuery()
-	if !strings.Contains(got.SQL, "INNER JOIN") {
-		t.Errorf("SQL should contain INNER JOIN: %q", got.SQL)
-	}
-	want := "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
+	want := `SELECT "id", "name" FROM "users" WHERE name = $1 AND "users"."id" = $2 LIMIT 1`
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
+	if len(got.Args) != 2 || got.Args[1] != id {
+		t.Errorf("Args = %v", got.Args)
+	}
 }
 
-func TestBuildSelectWithScopeLeftJoin(t *testing.T) {
+func TestFindByIDRejectsCompositePK(t *testing.T) {
 	t.Parallel()
 
 	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
-	q.RegisterJoin("Posts", orm.JoinConfig{
-		TargetTable:  "posts",
-		TargetColumn: "user_id",
-		SourceTable:  "users",
-		SourceColumn: "id",
-	})
-
-	_, _ = q.Scopes(scope.LeftJoin("Posts")).All(t.Context())
-
-	got := tq.LastQuery()
-	if !strings.Contains(got.SQL, "LEFT JOIN") {
-		t.Errorf("SQL should contain LEFT JOIN: %q", got.SQL)
+	if _, err := orm.FindByID(t.Context(), newTestMembershipQuery(tq), 1); err == nil {
+		t.Error("expected error for composite primary key, got nil")
 	}
-	want := "SELECT `users`.`id`, `users`.`name` FROM `users` LEFT JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
-	if got.SQL != want {
-		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
 	}
 }
 
-func TestBuildSelectWithJoinSelectColumns(t *testing.T) {
-	t.Parallel()
+// --- DELETE ---
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
-	q.RegisterJoin("Author", orm.JoinConfig{
-		TargetTable:   "authors",
-		TargetColumn:  "id",
-		SourceTable:   "users",
-		SourceColumn:  "author_id",
-		SelectColumns: []string{"id", "name"},
-	})
+func TestBuildDeleteByID(t *testing.T) {
+	t.Parallel()
 
-	_, _ = q.Join("Author").All(t.Context())
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	if err := orm.DeleteByID(t.Context(), newTestQuery(tq), 42); err != nil {
+		t.Fatalf("DeleteByID: %v", err)
+	}
 
 	got := tq.LastQuery()
-	want := "SELECT `users`.`id`, `users`.`name`, `authors`.`id` AS `Author__id`, `authors`.`name` AS `Author__name` FROM `users` INNER JOIN `authors` ON `authors`.`id` = `users`.`author_id`"
+	want := `DELETE FROM "users" WHERE "users"."id" = $1`
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
+	if len(got.Args) != 1 || got.Args[0] != 42 {
+		t.Errorf("Args = %v, want [42]", got.Args)
+	}
+
+	if err := orm.DeleteByID(t.Context(), newTestMembershipQuery(tq), 1); err == nil {
+		t.Error("expected error for composite primary key, got nil")
+	}
 }
 
-func TestBuildSelectWithJoinSelectColumnsPostgreSQL(t *testing.T) {
+func TestBuildDelete(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	tq := orm.NewTestQuerier(orm.MySQL)
 	q := newTestQuery(tq)
-	q.RegisterJoin("Author", orm.JoinConfig{
-		TargetTable:   "authors",
-		TargetColumn:  "id",
-		SourceTable:   "users",
-		SourceColumn:  "author_id",
-		SelectColumns: []string{"id", "name"},
-	})
 
-	_, _ = q.Join("Author").All(t.Context())
+	_ = q.Where("id = ?", 1).Delete(t.Context())
 
 	got := tq.LastQuery()
-	want := `SELECT "users"."id", "users"."name", "authors"."id" AS "Author__id", "authors"."name" AS "Author__name" FROM "users" INNER JOIN "authors" ON "authors"."id" = "users"."author_id"`
+	want := "DELETE FROM `users` WHERE id = ?"
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
 }
 
-func TestBuildSelectWithJoinNoSelectColumns(t *testing.T) {
+func TestDeleteWithoutWhereReturnsError(t *testing.T) {
 	t.Parallel()
 
-	// When SelectColumns is nil (e.g. has_many), no extra columns are added.
 	tq := orm.NewTestQuerier(orm.MySQL)
 	q := newTestQuery(tq)
-	q.RegisterJoin("Posts", orm.JoinConfig{
-		TargetTable:  "posts",
-		TargetColumn: "user_id",
-		SourceTable:  "users",
-		SourceColumn: "id",
-	})
-
-	_, _ = q.Join("Posts").All(t.Context())
 
-	got := tq.LastQuery()
-	want := "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
-	if got.SQL != want {
-		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	err := q.Delete(t.Context())
+	if err == nil {
+		t.Fatal("expected error for Delete without WHERE, got nil")
 	}
 }
 
-func TestBuildSelectWithScopePreload(t *testing.T) {
+func TestBuildDeleteAll(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
-	q.RegisterPreloader("Posts", func(_ context.Context, _ orm.Querier, _ []testUser) error {
-		return nil
-	})
-
-	// Scopes(scope.Preload("Posts")) should not affect the generated SQL;
-	// preloads are executed after the main query.
-	_, _ = q.Scopes(scope.Preload("Posts")).All(t.Context())
+	tests := []struct {
+		name  string
+		build func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want  string
+	}{
+		{
+			name:  "without WHERE deletes every row",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q },
+			want:  "DELETE FROM `users`",
+		},
+		{
+			name:  "with WHERE",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Where("id > ?", 10) },
+			want:  "DELETE FROM `users` WHERE id > ?",
+		},
+	}
 
-	got := tq.LastQuery()
-	want := "SELECT `id`, `name` FROM `users`"
-	if got.SQL != want {
-		t.Errorf("SQL = %q, want %q (preload should not alter SQL)", got.SQL, want)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			if err := tt.build(newTestQuery(tq)).DeleteAll(t.Context()); err != nil {
+				t.Fatalf("DeleteAll: %v", err)
+			}
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
-// --- Updates ---
+// --- soft delete ---
 
-func TestUpdates(t *testing.T) {
+func newTestSoftDeleteQuery(tq *orm.TestQuerier) *orm.Query[testUser] {
+	q := newTestQuery(tq)
+	q.RegisterSoftDelete("deleted_at")
+	return q
+}
+
+func TestSoftDeleteFiltersQueries(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestQuery(tq)
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, q *orm.Query[testUser])
+		want string
+	}{
+		{
+			name: "All",
+			run:  func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.All(ctx) },
+			want: "SELECT `id`, `name` FROM `users` WHERE `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "First with WHERE",
+			run:  func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Where("name = ?", "alice").First(ctx) },
+			want: "SELECT `id`, `name` FROM `users` WHERE (name = ?) AND `users`.`deleted_at` IS NULL LIMIT 1",
+		},
+		{
+			name: "All with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ? OR id = ?", "alice", 2).All(ctx)
+			},
+			want: "SELECT `id`, `name` FROM `users` WHERE (name = ? OR id = ?) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "Count with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ? OR id = ?", "alice", 2).Count(ctx)
+			},
+			want: "SELECT COUNT(*) FROM `users` WHERE (name = ? OR id = ?) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "Updates with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_ = q.Where("name = ? OR id = ?", "alice", 2).Updates(ctx, map[string]any{"name": "bob"})
+			},
+			want: "UPDATE `users` SET `name` = ? WHERE (name = ? OR id = ?) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "OrWhere stays one group",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ?", "alice").OrWhere("id = ?", 2).All(ctx)
+			},
+			want: "SELECT `id`, `name` FROM `users` WHERE ((name = ?) OR (id = ?)) AND `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "Count",
+			run:  func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Count(ctx) },
+			want: "SELECT COUNT(*) FROM `users` WHERE `users`.`deleted_at` IS NULL",
+		},
+		{
+			name: "Unscoped",
+			run:  func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Unscoped().All(ctx) },
+			want: "SELECT `id`, `name` FROM `users`",
+		},
+	}
 
-	err := q.Where("id = ?", 123).Updates(t.Context(), map[string]any{
-		"name": "new name",
-	})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			tt.run(t.Context(), newTestSoftDeleteQuery(tq))
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
 	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	t.Parallel()
 
+	fixed := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	if err := newTestSoftDeleteQuery(tq).Where("id = ?", 1).Delete(ctx); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
 	got := tq.LastQuery()
-	want := "UPDATE `users` SET `name` = ? WHERE id = ?"
+	want := `UPDATE "users" SET "deleted_at" = $1 WHERE (id = $2) AND "users"."deleted_at" IS NULL`
 	if got.SQL != want {
 		t.Errorf("SQL = %q, want %q", got.SQL, want)
 	}
-	if len(got.Args) != 2 || got.Args[0] != "new name" || got.Args[1] != 123 {
+	if len(got.Args) != 2 || got.Args[0] != fixed || got.Args[1] != 1 {
 		t.Errorf("Args = %v", got.Args)
 	}
+
+	if err := newTestSoftDeleteQuery(tq).Where("name = ? OR id = ?", "a", 2).Delete(ctx); err != nil {
+		t.Fatalf("Delete with OR: %v", err)
+	}
+	want = `UPDATE "users" SET "deleted_at" = $1 WHERE (name = $2 OR id = $3) AND "users"."deleted_at" IS NULL`
+	if got := tq.LastQuery().SQL; got != want {
+		t.Errorf("OR SQL = %q, want %q", got, want)
+	}
+
+	if err := newTestSoftDeleteQuery(tq).Where("id = ?", 1).Unscoped().Delete(ctx); err != nil {
+		t.Fatalf("Unscoped Delete: %v", err)
+	}
+	if got, want := tq.LastQuery().SQL, `DELETE FROM "users" WHERE id = $1`; got != want {
+		t.Errorf("Unscoped SQL = %q, want %q", got, want)
+	}
 }
 
-func TestUpdatesPostgreSQL(t *testing.T) {
+// --- GROUP BY / HAVING ---
+
+func TestBuildSelectGroupByHaving(t *testing.T) {
 	t.Parallel()
 
-	tq := orm.NewTestQuerier(orm.PostgreSQL)
-	q := newTestQuery(tq)
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT name, COUNT(*) AS n FROM `users` WHERE id > ? GROUP BY name HAVING COUNT(*) > ? AND MAX(id) < ? ORDER BY n DESC LIMIT 10"},
+		{orm.PostgreSQL, `SELECT name, COUNT(*) AS n FROM "users" WHERE id > $1 GROUP BY name HAVING COUNT(*) > $2 AND MAX(id) < $3 ORDER BY n DESC LIMIT 10`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		_, _ = newTestQuery(tq).
+			Select("name, COUNT(*) AS n").
+			Having("COUNT(*) > ?", 5).
+			Where("id > ?", 1).
+			Scopes(scope.GroupBy("name"), scope.Having("MAX(id) < ?", 100)).
+			OrderBy("n DESC").
+			Limit(10).
+			All(t.Context())
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 3 || got.Args[0] != 1 || got.Args[1] != 5 || got.Args[2] != 100 {
+			t.Errorf("Args = %v, want [1 5 100]", got.Args)
+		}
+	}
+}
 
-	err := q.Where("id = ?", 123).Updates(t.Context(), map[string]any{
-		"name": "new name",
-	})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestBuildCountGroupBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT COUNT(*) FROM (SELECT name FROM `users` WHERE id > ? GROUP BY name HAVING COUNT(*) > ?) AS grouped"},
+		{orm.PostgreSQL, `SELECT COUNT(*) FROM (SELECT name FROM "users" WHERE id > $1 GROUP BY name HAVING COUNT(*) > $2) AS grouped`},
 	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		_, _ = newTestQuery(tq).Where("id > ?", 1).GroupBy("name").Having("COUNT(*) > ?", 5).Count(t.Context())
 
-	got := tq.LastQuery()
-	want := `UPDATE "users" SET "name" = $1 WHERE id = $2`
-	if got.SQL != want {
-		t.Errorf("SQL = %q, want %q", got.SQL, want)
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 2 || got.Args[0] != 1 || got.Args[1] != 5 {
+			t.Errorf("Args = %v, want [1 5]", got.Args)
+		}
+	}
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	if _, err := newTestQuery(tq).GroupBy("name").CountDistinct(t.Context(), "id"); err == nil {
+		t.Error("CountDistinct with GroupBy: expected error, got nil")
 	}
 }
 
-func TestUpdatesAutoUpdatedAt(t *testing.T) {
+func TestBuildCountHavingWithoutGroupBy(t *testing.T) {
 	t.Parallel()
 
-	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
-	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT COUNT(*) FROM (SELECT `id`, `name` FROM `users` WHERE id > ? HAVING name <> ?) AS filtered"},
+		{orm.PostgreSQL, `SELECT COUNT(*) FROM (SELECT "id", "name" FROM "users" WHERE id > $1 HAVING name <> $2) AS filtered`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq).Where("id > ?", 1).Having("name <> ?", "bob")
+		_, _ = q.All(t.Context())
+		all := tq.LastQuery().SQL
+		_, _ = q.Count(t.Context())
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if !strings.Contains(got.SQL, all) {
+			t.Errorf("Count SQL %q does not count the rows of %q", got.SQL, all)
+		}
+		if len(got.Args) != 2 || got.Args[0] != 1 || got.Args[1] != "bob" {
+			t.Errorf("Args = %v, want [1 bob]", got.Args)
+		}
+	}
 
 	tq := orm.NewTestQuerier(orm.MySQL)
-	q := newTestArticleQuery(tq)
+	if _, err := newTestQuery(tq).Having("name <> ?", "bob").CountDistinct(t.Context(), "id"); err == nil {
+		t.Error("CountDistinct with Having: expected error, got nil")
+	}
+}
 
-	err := q.Where("id = ?", 1).Updates(ctx, map[string]any{
-		"title": "updated title",
-	})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+// --- optimistic locking ---
+
+type testDocument struct {
+	ID      int
+	Title   string
+	Version int
+}
+
+func testDocumentColValPairs(d *testDocument, includesPK bool) ([]string, []any) {
+	if includesPK {
+		return []string{"id", "title", "version"}, []any{d.ID, d.Title, d.Version}
 	}
+	return []string{"title", "version"}, []any{d.Title, d.Version}
+}
 
-	got := tq.LastQuery()
-	// Should contain both title and updated_at in SET clause
-	if !strings.Contains(got.SQL, "`updated_at` = ?") {
-		t.Errorf("SQL should contain updated_at: %q", got.SQL)
+func newTestDocumentQuery(tq *orm.TestQuerier) *orm.Query[testDocument] {
+	q := orm.NewQuery[testDocument](tq, "documents", []string{"id", "title", "version"}, "id",
+		func(*sql.Rows) (testDocument, error) { return testDocument{}, nil }, testDocumentColValPairs, nil)
+	q.RegisterVersion("version", func(d *testDocument) { d.Version++ })
+	return q
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "UPDATE `documents` SET `version` = `version` + 1, `title` = ? WHERE `id` = ? AND `version` = ?"},
+		{orm.PostgreSQL, `UPDATE "documents" SET "version" = "version" + 1, "title" = $1 WHERE "id" = $2 AND "version" = $3`},
 	}
-	if !strings.Contains(got.SQL, "`title` = ?") {
-		t.Errorf("SQL should contain title: %q", got.SQL)
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		tq.RowsAffected = 1
+		d := testDocument{ID: 1, Title: "draft", Version: 4}
+		if err := newTestDocumentQuery(tq).Update(t.Context(), &d); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 3 || got.Args[0] != "draft" || got.Args[1] != 1 || got.Args[2] != 4 {
+			t.Errorf("Args = %v, want [draft 1 4]", got.Args)
+		}
+		if d.Version != 5 {
+			t.Errorf("Version = %d, want 5", d.Version)
+		}
 	}
+}
 
-	// Verify updated_at value is the fixed time
-	foundUpdatedAt := false
-	for _, arg := range got.Args {
-		if ts, ok := arg.(time.Time); ok && ts.Equal(fixed) {
-			foundUpdatedAt = true
-			break
+func TestUpdateStaleObject(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL) // RowsAffected 0: another writer bumped the version
+	d := testDocument{ID: 1, Title: "draft", Version: 4}
+	if err := newTestDocumentQuery(tq).Update(t.Context(), &d); !errors.Is(err, orm.ErrStaleObject) {
+		t.Errorf("err = %v, want ErrStaleObject", err)
+	}
+	if d.Version != 4 {
+		t.Errorf("Version = %d, want 4 (unchanged)", d.Version)
+	}
+}
+
+func TestUpsertIncrementsVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "ON DUPLICATE KEY UPDATE `title` = VALUES(`title`), `version` = `version` + 1"},
+		{orm.PostgreSQL, `DO UPDATE SET "title" = EXCLUDED."title", "version" = "documents"."version" + 1`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		_ = newTestDocumentQuery(tq).Upsert(t.Context(), &testDocument{ID: 1, Title: "draft"})
+		if got := tq.LastQuery().SQL; !strings.HasSuffix(got, tt.want) {
+			t.Errorf("SQL = %q, want suffix %q", got, tt.want)
 		}
 	}
-	if !foundUpdatedAt {
-		t.Errorf("Args should contain fixed time %v: %v", fixed, got.Args)
+}
+
+// --- write results ---
+
+func TestWriteResultsReportRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	tq.RowsAffected = 3
+	q := newTestQuery(tq)
+	ctx := t.Context()
+
+	results := map[string]func() (sql.Result, error){
+		"UpdateResult": func() (sql.Result, error) { return q.UpdateResult(ctx, &testUser{ID: 1, Name: "bob"}) },
+		"UpdatesResult": func() (sql.Result, error) {
+			return q.Where("id > ?", 1).UpdatesResult(ctx, map[string]any{"name": "bob"})
+		},
+		"DeleteResult": func() (sql.Result, error) { return q.Where("id > ?", 1).DeleteResult(ctx) },
+	}
+	for name, run := range results {
+		res, err := run()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if n, _ := res.RowsAffected(); n != 3 {
+			t.Errorf("%s: RowsAffected = %d, want 3", name, n)
+		}
 	}
 }
 
-func TestUpdatesWithoutWhereReturnsError(t *testing.T) {
+func TestWriteResultsKeepGuards(t *testing.T) {
 	t.Parallel()
 
 	tq := orm.NewTestQuerier(orm.MySQL)
 	q := newTestQuery(tq)
+	ctx := t.Context()
 
-	err := q.Updates(t.Context(), map[string]any{"name": "oops"})
-	if err == nil {
-		t.Fatal("expected error for Updates without WHERE, got nil")
+	if _, err := q.UpdatesResult(ctx, map[string]any{"name": "bob"}); err == nil {
+		t.Error("UpdatesResult without WHERE: expected error, got nil")
+	}
+	if _, err := q.DeleteResult(ctx); err == nil {
+		t.Error("DeleteResult without WHERE: expected error, got nil")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+// --- row locking ---
+
+func TestBuildSelectForUpdate(t *testing.T) {
+	t.Parallel()
+
+	type q = *orm.Query[testUser]
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		build   func(q) q
+		want    string
+	}{
+		{
+			name:    "ForUpdate after LIMIT and OFFSET",
+			dialect: orm.MySQL,
+			build:   func(b q) q { return b.Where("id = ?", 1).ForUpdate().Limit(1).Offset(2) },
+			want:    "SELECT `id`, `name` FROM `users` WHERE id = ? LIMIT 1 OFFSET 2 FOR UPDATE",
+		},
+		{
+			name:    "ForUpdateSkipLocked",
+			dialect: orm.PostgreSQL,
+			build:   func(b q) q { return b.Where("id > ?", 1).OrderBy("id").Limit(10).ForUpdateSkipLocked() },
+			want:    `SELECT "id", "name" FROM "users" WHERE id > $1 ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED`,
+		},
+		{
+			name:    "no lock by default",
+			dialect: orm.PostgreSQL,
+			build:   func(b q) q { return b.Limit(1) },
+			want:    `SELECT "id", "name" FROM "users" LIMIT 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			_, _ = tt.build(newTestQuery(tq)).All(t.Context())
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountDoesNotLock(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	_, _ = newTestQuery(tq).ForUpdate().Count(t.Context())
+	if got := tq.LastQuery().SQL; strings.Contains(got, "FOR UPDATE") {
+		t.Errorf("SQL = %q, want no lock clause", got)
+	}
+}
+
+func TestForUpdateUnsupportedDialect(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.SQLServer)
+	q := newTestQuery(tq)
+	if _, err := q.ForUpdate().All(t.Context()); err == nil {
+		t.Error("ForUpdate on SQL Server: want an error")
+	}
+	if _, err := q.ForUpdateSkipLocked().First(t.Context()); err == nil {
+		t.Error("ForUpdateSkipLocked on SQL Server: want an error")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("ran %d queries, want none", len(tq.Queries))
+	}
+}
+
+// --- aggregates ---
+
+func TestBuildAggregates(t *testing.T) {
+	t.Parallel()
+
+	type aggregate func(q *orm.Query[testUser], ctx context.Context, column string) (sql.NullFloat64, error)
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		fn      aggregate
+		column  string
+		want    string
+	}{
+		{"Sum", orm.MySQL, (*orm.Query[testUser]).Sum, "amount", "SELECT SUM(`amount`) FROM `users` WHERE id > ?"},
+		{"Avg", orm.MySQL, (*orm.Query[testUser]).Avg, "users.amount", "SELECT AVG(`users`.`amount`) FROM `users` WHERE id > ?"},
+		{"Min", orm.PostgreSQL, (*orm.Query[testUser]).Min, "amount", `SELECT MIN("amount") FROM "users" WHERE id > $1`},
+		{"Max", orm.PostgreSQL, (*orm.Query[testUser]).Max, "COALESCE(amount, 0)", `SELECT MAX(COALESCE(amount, 0)) FROM "users" WHERE id > $1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestQuery(tq).Where("id > ?", 1).OrderBy("id").Limit(10)
+			_, _ = tt.fn(q, t.Context(), tt.column)
+
+			got := tq.LastQuery()
+			if got.SQL != tt.want {
+				t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+			}
+			if len(got.Args) != 1 || got.Args[0] != 1 {
+				t.Errorf("Args = %v, want [1]", got.Args)
+			}
+		})
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	if _, err := newTestQuery(tq).Sum(t.Context(), " "); err == nil {
+		t.Error("Sum without a column: expected error, got nil")
+	}
+	if _, err := newTestQuery(tq).GroupBy("name").Max(t.Context(), "id"); err == nil {
+		t.Error("Max with GroupBy: expected error, got nil")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+// --- Rewrite (PostgreSQL placeholders) ---
+
+func TestRewritePostgreSQLSelect(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.Where("name = ?", "alice").Where("id > ?", 10).All(t.Context())
+
+	got := tq.LastQuery()
+	want := `SELECT "id", "name" FROM "users" WHERE name = $1 AND id > $2`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+// --- Prepare ---
+
+func TestPrepareBindsOpenPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	p := newTestQuery(tq).
+		Where("name = ?").
+		Where("id > ?", 10).
+		Where("note <> '?' AND id < ?").
+		Prepare()
+
+	want := `SELECT "id", "name" FROM "users" WHERE name = $1 AND id > $2 AND note <> '?' AND id < $3`
+	if got := p.SQL(); got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+
+	for _, name := range []string{"alice", "bob"} {
+		_, _ = p.All(t.Context(), name, 20)
+
+		got := tq.LastQuery()
+		if got.SQL != want {
+			t.Errorf("SQL = %q, want %q", got.SQL, want)
+		}
+		wantArgs := []any{name, 10, 20}
+		if len(got.Args) != len(wantArgs) {
+			t.Fatalf("Args = %v, want %v", got.Args, wantArgs)
+		}
+		for i := range wantArgs {
+			if got.Args[i] != wantArgs[i] {
+				t.Errorf("Args[%d] = %v, want %v", i, got.Args[i], wantArgs[i])
+			}
+		}
+	}
+}
+
+func TestPrepareBindsHavingPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	p := newTestQuery(tq).
+		Select("name, COUNT(*) AS n").
+		Where("id > ?").
+		GroupBy("name").
+		Having("COUNT(*) > ?").
+		Prepare()
+
+	want := `SELECT name, COUNT(*) AS n FROM "users" WHERE id > $1 GROUP BY name HAVING COUNT(*) > $2`
+	if got := p.SQL(); got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+	if _, err := p.All(t.Context(), 10); err == nil {
+		t.Error("All with the HAVING arg missing: expected error, got nil")
+	}
+
+	_, _ = p.All(t.Context(), 10, 3)
+	got := tq.LastQuery()
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 2 || got.Args[0] != 10 || got.Args[1] != 3 {
+		t.Errorf("Args = %v, want [10 3]", got.Args)
+	}
+}
+
+func TestPrepareRaw(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	p := newTestQuery(tq).
+		Where("ignored = ?", 1).
+		Raw("SELECT id, name FROM ranked WHERE rn <= ?").
+		Prepare()
+
+	want := "SELECT id, name FROM ranked WHERE rn <= $1"
+	if got := p.SQL(); got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+
+	_, _ = p.All(t.Context(), 3)
+	got := tq.LastQuery()
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 1 || got.Args[0] != 3 {
+		t.Errorf("Args = %v, want [3]", got.Args)
+	}
+
+	// A raw query given its args keeps them.
+	p = newTestQuery(tq).Raw("SELECT id, name FROM ranked WHERE rn <= ?", 5).Prepare()
+	_, _ = p.All(t.Context())
+	if got := tq.LastQuery(); got.SQL != want || len(got.Args) != 1 || got.Args[0] != 5 {
+		t.Errorf("query = %q %v, want %q [5]", got.SQL, got.Args, want)
+	}
+}
+
+func TestPrepareRejectsWrongArgCount(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	p := newTestQuery(tq).Where("name = ? AND id > ?").Prepare()
+
+	for _, args := range [][]any{nil, {"alice"}, {"alice", 1, 2}} {
+		if _, err := p.All(t.Context(), args...); err == nil {
+			t.Errorf("All(%v): expected error, got nil", args)
+		}
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+func TestAllByIDPropagatesQueryError(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	m, err := orm.AllByID[int](t.Context(), newTestQuery(tq).Where("name = ?", "alice"))
+	if err == nil {
+		t.Fatal("expected error from mock querier")
+	}
+	if m != nil {
+		t.Errorf("m = %v, want nil on error", m)
+	}
+	if got, want := tq.LastQuery().SQL, "SELECT `id`, `name` FROM `users` WHERE name = ?"; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
+
+// --- First ---
+
+func TestFirstAddsLimit(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.First(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users` LIMIT 1"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+// newStubUserQuery returns a users query on the stub driver that scans
+// rows for real; table "found_users" has the row (1, "found").
+func newStubUserQuery(t *testing.T, table string, obs orm.QueryObserver) *orm.Query[testUser] {
+	t.Helper()
+
+	scan := func(rows *sql.Rows) (testUser, error) {
+		var u testUser
+		err := rows.Scan(&u.ID, &u.Name)
+		return u, err
+	}
+	return orm.NewQuery[testUser](newStubDB(t, 0).WithObserver(obs), table, testUserColumns, "id", scan, testUserColValPairs, nil)
+}
+
+func TestEachStreamsRows(t *testing.T) {
+	t.Parallel()
+
+	errStop := errors.New("stop")
+	q := newStubUserQuery(t, "endless_users", nil)
+
+	// The stub never runs out of rows, so Each only returns if it yields
+	// them one at a time and stops when fn fails.
+	var got []testUser
+	err := q.Each(t.Context(), func(u testUser) error {
+		got = append(got, u)
+		if len(got) == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("err = %v, want %v", err, errStop)
+	}
+	want := []testUser{{1, "user1"}, {2, "user2"}, {3, "user3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+
+	// The pool holds one connection, which is only free for another run
+	// if Each closed its rows.
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	if err := q.Each(ctx, func(testUser) error { return errStop }); !errors.Is(err, errStop) {
+		t.Errorf("second Each: err = %v, want %v", err, errStop)
+	}
+}
+
+func TestEachRejectsPreloads(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	err := newTestQuery(tq).Preload("Posts").Each(t.Context(), func(testUser) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "cannot preload Posts") {
+		t.Errorf("err = %v, want a preload error", err)
+	}
+
+	// A preload added by the context's default scopes is rejected too.
+	ctx := orm.WithDefaultScopes(t.Context(), scope.Preload("Posts"))
+	err = newTestQuery(tq).Each(ctx, func(testUser) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "cannot preload Posts") {
+		t.Errorf("default scope preload: err = %v, want a preload error", err)
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("ran %d queries, want none", len(tq.Queries))
+	}
+}
+
+func TestFirstOrCreate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		table   string
+		want    testUser
+		wantSQL []string
+	}{
+		{"found", "found_users", testUser{ID: 1, Name: "found"}, []string{
+			"SELECT `id`, `name` FROM `found_users` WHERE name = ? LIMIT 1",
+		}},
+		{"not found", "users", testUser{ID: 7, Name: "alice"}, []string{
+			"SELECT `id`, `name` FROM `users` WHERE name = ? LIMIT 1",
+			"INSERT INTO `users` (`id`, `name`) VALUES (?, ?)",
+		}},
+	}
+	for _, tt := range tests {
+		obs := &recordingObserver{}
+		u := testUser{ID: 7, Name: "alice"}
+		if err := newStubUserQuery(t, tt.table, obs).Where("name = ?", "alice").FirstOrCreate(t.Context(), &u); err != nil {
+			t.Fatalf("%s: FirstOrCreate: %v", tt.name, err)
+		}
+		if u != tt.want {
+			t.Errorf("%s: u = %+v, want %+v", tt.name, u, tt.want)
+		}
+		var got []string
+		for _, info := range obs.infos {
+			got = append(got, info.SQL)
+		}
+		if !reflect.DeepEqual(got, tt.wantSQL) {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got, tt.wantSQL)
+		}
+	}
+}
+
+func TestFirstOrInit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		table string
+		want  testUser
+	}{
+		{"found", "found_users", testUser{ID: 1, Name: "found"}},
+		{"not found", "users", testUser{ID: 7, Name: "alice"}},
+	}
+	for _, tt := range tests {
+		obs := &recordingObserver{}
+		u := testUser{ID: 7, Name: "alice"}
+		if err := newStubUserQuery(t, tt.table, obs).Where("name = ?", "alice").FirstOrInit(t.Context(), &u); err != nil {
+			t.Fatalf("%s: FirstOrInit: %v", tt.name, err)
+		}
+		if u != tt.want {
+			t.Errorf("%s: u = %+v, want %+v", tt.name, u, tt.want)
+		}
+		if len(obs.infos) != 1 {
+			t.Errorf("%s: %d statements, want only the SELECT", tt.name, len(obs.infos))
+		}
+	}
+}
+
+func TestFirstOrCreatePassesLookupErrors(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	u := testUser{Name: "alice"}
+	if err := newTestQuery(tq).FirstOrCreate(t.Context(), &u); err == nil {
+		t.Fatal("expected error from mock querier")
+	}
+	if len(tq.Queries) != 1 {
+		t.Errorf("queries = %d, want no INSERT after a failed lookup", len(tq.Queries))
+	}
+}
+
+func TestAllPtrUsesSelect(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	users, err := q.Where("name = ?", "alice").AllPtr(t.Context())
+	if err == nil {
+		t.Fatal("expected error from mock querier")
+	}
+	if users != nil {
+		t.Errorf("users = %v, want nil on error", users)
+	}
+
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users` WHERE name = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectOrWhereAndWhereGroup(t *testing.T) {
+	t.Parallel()
+
+	type q = *orm.Query[testUser]
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		build   func(q) q
+		where   string
+		args    []any
+	}{
+		{
+			name:    "OrWhere groups earlier conditions",
+			dialect: orm.MySQL,
+			build: func(b q) q {
+				return b.Where("role = ?", "admin").Where("active").OrWhere("id = ?", 1).Where("deleted_at IS NULL")
+			},
+			where: " WHERE ((role = ? AND active) OR (id = ?)) AND deleted_at IS NULL",
+			args:  []any{"admin", 1},
+		},
+		{
+			name:    "OrWhere without earlier conditions",
+			dialect: orm.MySQL,
+			build:   func(b q) q { return b.OrWhere("id = ?", 1) },
+			where:   " WHERE id = ?",
+			args:    []any{1},
+		},
+		{
+			name:    "WhereGroup with OrWhere",
+			dialect: orm.MySQL,
+			build: func(b q) q {
+				return b.Where("active").WhereGroup(func(g q) q {
+					return g.Where("role = ?", "admin").OrWhere("role = ?", "owner")
+				})
+			},
+			where: " WHERE active AND ((role = ?) OR (role = ?))",
+			args:  []any{"admin", "owner"},
+		},
+		{
+			name:    "scope.Or",
+			dialect: orm.PostgreSQL,
+			build: func(b q) q {
+				return b.Scopes(scope.Where("name = ?", "alice"), scope.Or("email = ?", "a@example.com"))
+			},
+			where: ` WHERE ((name = $1) OR (email = $2))`,
+			args:  []any{"alice", "a@example.com"},
+		},
+		{
+			name:    "scope.Or inside WhereGroup",
+			dialect: orm.MySQL,
+			build: func(b q) q {
+				return b.Where("active = ?", true).WhereGroup(func(g q) q {
+					return g.Scopes(scope.Where("name = ?", "alice"), scope.Or("email = ?", "a@example.com"))
+				})
+			},
+			where: " WHERE active = ? AND ((name = ?) OR (email = ?))",
+			args:  []any{true, "alice", "a@example.com"},
+		},
+		{
+			name:    "WhereGroup parenthesizes a raw clause",
+			dialect: orm.MySQL,
+			build: func(b q) q {
+				return b.Where("active").WhereGroup(func(g q) q { return g.Where("(a) OR (b)") })
+			},
+			where: " WHERE active AND ((a) OR (b))",
+		},
+		{
+			name:    "empty WhereGroup",
+			dialect: orm.MySQL,
+			build:   func(b q) q { return b.Where("active").WhereGroup(func(g q) q { return g }) },
+			where:   " WHERE active",
+		},
+		{
+			name:    "nested groups keep placeholder order",
+			dialect: orm.PostgreSQL,
+			build: func(b q) q {
+				return b.Where("x = ?", 1).WhereGroup(func(g q) q {
+					return g.Where("a = ?", 2).OrWhere("b = ?", 3).WhereGroup(func(g2 q) q {
+						return g2.Where("c = ?", 4).OrWhere("d = ?", 5)
+					})
+				}).Where("z = ?", 6)
+			},
+			where: " WHERE x = $1 AND (((a = $2) OR (b = $3)) AND ((c = $4) OR (d = $5))) AND z = $6",
+			args:  []any{1, 2, 3, 4, 5, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			base := newTestQuery(tq)
+			_, _ = tt.build(base).All(t.Context())
+
+			got := tq.LastQuery()
+			if !strings.HasSuffix(got.SQL, tt.where) {
+				t.Errorf("SQL = %q, want suffix %q", got.SQL, tt.where)
+			}
+			if len(got.Args) != len(tt.args) {
+				t.Fatalf("Args = %v, want %v", got.Args, tt.args)
+			}
+			for i := range tt.args {
+				if got.Args[i] != tt.args[i] {
+					t.Errorf("Args = %v, want %v", got.Args, tt.args)
+					break
+				}
+			}
+
+			// The base query is not modified.
+			_, _ = base.All(t.Context())
+			if strings.Contains(tq.LastQuery().SQL, "WHERE") {
+				t.Errorf("base query modified: %q", tq.LastQuery().SQL)
+			}
+		})
+	}
+}
+
+func TestWhereCheckedMismatch(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	base := newTestQuery(tq)
+	q := base.Scopes(scope.WhereChecked("name = ? OR email = ?", "alice"))
+
+	if _, err := q.All(t.Context()); !errors.Is(err, orm.ErrPlaceholderMismatch) {
+		t.Errorf("All err = %v, want ErrPlaceholderMismatch", err)
+	}
+	if _, err := q.Count(t.Context()); !errors.Is(err, orm.ErrPlaceholderMismatch) {
+		t.Errorf("Count err = %v, want ErrPlaceholderMismatch", err)
+	}
+	if err := q.Delete(t.Context()); !errors.Is(err, orm.ErrPlaceholderMismatch) {
+		t.Errorf("Delete err = %v, want ErrPlaceholderMismatch", err)
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no query to be executed, got %d", len(tq.Queries))
+	}
+
+	// The builder is immutable; the base query is unaffected.
+	if err := base.Where("id = ?", 1).Delete(t.Context()); err != nil {
+		t.Errorf("Delete on base query: %v", err)
+	}
+}
+
+func TestWhereCheckedMatch(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	_ = newTestQuery(tq).Scopes(scope.WhereChecked("name = ? OR email = '?'", "alice")).Delete(t.Context())
+
+	got := tq.LastQuery()
+	want := `DELETE FROM "users" WHERE name = $1 OR email = '?'`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectCaseInsensitiveScopes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT `id`, `name` FROM `users` WHERE LOWER(name) = LOWER(?) ORDER BY LOWER(name) DESC, id"},
+		{orm.PostgreSQL, `SELECT "id", "name" FROM "users" WHERE LOWER(name) = LOWER($1) ORDER BY LOWER(name) DESC, id`},
+	}
+
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq)
+
+		_, _ = q.Scopes(scope.EqCI("name", "Alice"), scope.OrderByCI("name DESC"), scope.OrderBy("id")).All(t.Context())
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 1 || got.Args[0] != "Alice" {
+			t.Errorf("Args = %v, want [Alice]", got.Args)
+		}
+	}
+}
+
+func TestBuildSelectLikeScopes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT `id`, `name` FROM `users` WHERE name LIKE ? AND LOWER(email) LIKE LOWER(?)"},
+		{orm.PostgreSQL, `SELECT "id", "name" FROM "users" WHERE name LIKE $1 AND email ILIKE $2`},
+	}
+
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq)
+
+		_, _ = q.Scopes(scope.Like("name", "al%"), scope.ILike("email", "%@example.com")).All(t.Context())
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 2 || got.Args[0] != "al%" || got.Args[1] != "%@example.com" {
+			t.Errorf("Args = %v, want [al%% %%@example.com]", got.Args)
+		}
+	}
+}
+
+func TestBuildSelectColumnOrderScopes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT `id`, `name` FROM `users` ORDER BY `users`.`name` ASC, `posts`.`id` DESC"},
+		{orm.PostgreSQL, `SELECT "id", "name" FROM "users" ORDER BY "users"."name" ASC, "posts"."id" DESC`},
+	}
+
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq)
+
+		_, _ = q.Scopes(scope.Asc("name"), scope.Desc("posts.id")).All(t.Context())
+
+		if got := tq.LastQuery().SQL; got != tt.want {
+			t.Errorf("SQL = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBuildSelectTimeScopes(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		dialect orm.Dialect
+		want    string
+	}{
+		{orm.MySQL, "SELECT `id`, `name` FROM `users` WHERE `users`.`created_at` > ? AND `created_at` < ? AND `created_at` >= ? AND `created_at` < ?"},
+		{orm.PostgreSQL, `SELECT "id", "name" FROM "users" WHERE "users"."created_at" > $1 AND "created_at" < $2 AND "created_at" >= $3 AND "created_at" < $4`},
+	}
+
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq)
+
+		_, _ = q.Scopes(
+			scope.After("users.created_at", since),
+			scope.Before("created_at", until),
+			scope.DateEq("created_at", since),
+		).All(t.Context())
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+		}
+		if len(got.Args) != 4 {
+			t.Fatalf("Args = %v, want 4 args", got.Args)
+		}
+		for i, arg := range got.Args {
+			if _, ok := arg.(time.Time); !ok {
+				t.Errorf("Args[%d] = %T, want time.Time", i, arg)
+			}
+		}
+		if !got.Args[0].(time.Time).Equal(since) {
+			t.Errorf("Args[0] = %v, want %v", got.Args[0], since)
+		}
+	}
+}
+
+// --- Timestamp tests ---
+
+type testArticle struct {
+	ID        int
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+var testArticleColumns = []string{"id", "title", "created_at", "updated_at"}
+
+func scanTestArticle(_ *sql.Rows) (testArticle, error) {
+	return testArticle{}, nil
+}
+
+func testArticleColValPairs(a *testArticle, includesPK bool) ([]string, []any) {
+	if includesPK {
+		return []string{"id", "title", "created_at", "updated_at"},
+			[]any{a.ID, a.Title, a.CreatedAt, a.UpdatedAt}
+	}
+	return []string{"title", "created_at", "updated_at"},
+		[]any{a.Title, a.CreatedAt, a.UpdatedAt}
+}
+
+func setTestArticlePK(a *testArticle, id int64) {
+	a.ID = int(id)
+}
+
+func setTestArticleCreatedAt(a *testArticle, now time.Time) {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+}
+
+func setTestArticleUpdatedAt(a *testArticle, now time.Time) {
+	a.UpdatedAt = now
+}
+
+func newTestArticleQuery(tq *orm.TestQuerier) *orm.Query[testArticle] {
+	q := orm.NewQuery[testArticle](tq, "articles", testArticleColumns, "id", scanTestArticle, testArticleColValPairs, setTestArticlePK)
+	q.RegisterTimestamps([]string{"created_at"}, setTestArticleCreatedAt, []string{"updated_at"}, setTestArticleUpdatedAt)
+	return q
+}
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func TestUpsertExcludesCreatedAtFromUpdate(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestArticleQuery(tq)
+
+	a := testArticle{ID: 1, Title: "hello"}
+	_ = q.Upsert(t.Context(), &a)
+
+	got := tq.LastQuery()
+	// UPDATE clause should NOT contain created_at
+	if strings.Contains(got.SQL, "ON DUPLICATE KEY UPDATE") {
+		updatePart := got.SQL[strings.Index(got.SQL, "ON DUPLICATE KEY UPDATE"):]
+		if strings.Contains(updatePart, "created_at") {
+			t.Errorf("UPDATE clause should not contain created_at: %s", got.SQL)
+		}
+		if !strings.Contains(updatePart, "updated_at") {
+			t.Errorf("UPDATE clause should contain updated_at: %s", got.SQL)
+		}
+	} else {
+		t.Errorf("expected ON DUPLICATE KEY UPDATE in SQL: %s", got.SQL)
+	}
+}
+
+func TestUpsertExcludesCreatedAtPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestArticleQuery(tq)
+
+	a := testArticle{ID: 1, Title: "hello"}
+	_ = q.Upsert(t.Context(), &a)
+
+	got := tq.LastQuery()
+	// DO UPDATE SET should NOT contain created_at
+	if strings.Contains(got.SQL, "DO UPDATE SET") {
+		updatePart := got.SQL[strings.Index(got.SQL, "DO UPDATE SET"):]
+		if strings.Contains(updatePart, "created_at") {
+			t.Errorf("UPDATE SET should not contain created_at: %s", got.SQL)
+		}
+		if !strings.Contains(updatePart, "updated_at") {
+			t.Errorf("UPDATE SET should contain updated_at: %s", got.SQL)
+		}
+	} else {
+		t.Errorf("expected DO UPDATE SET in SQL: %s", got.SQL)
+	}
+}
+
+func TestBuildUpsertOnConflictUpdateWhere(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq).OnConflictUpdateWhere(`"users"."name" <> ?`, "admin")
+
+	u := testUser{ID: 1, Name: "alice"}
+	_ = q.Upsert(t.Context(), &u)
+
+	got := tq.LastQuery()
+	want := `INSERT INTO "users" ("id", "name") VALUES ($1, $2)` +
+		` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" WHERE "users"."name" <> $3 RETURNING "id"`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 3 || got.Args[0] != 1 || got.Args[1] != "alice" || got.Args[2] != "admin" {
+		t.Errorf("Args = %v, want [1 alice admin]", got.Args)
+	}
+}
+
+func TestBuildUpsertOnConflictUpdateWhereMySQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	u := testUser{ID: 1, Name: "alice"}
+	if err := q.OnConflictUpdateWhere("1 = 1").Upsert(t.Context(), &u); err == nil {
+		t.Error("expected error for MySQL")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no query to be executed, got %d", len(tq.Queries))
+	}
+
+	// The builder is immutable; the base query still upserts.
+	if err := q.Upsert(t.Context(), &u); err != nil {
+		t.Errorf("Upsert: %v", err)
+	}
+}
+
+func TestConflictInsertsUnsupportedOnSQLServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, q *orm.Query[testUser], u *testUser) error
+	}{
+		{"Upsert", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.Upsert(ctx, u) }},
+		{"UpsertReturning", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.UpsertReturning(ctx, u) }},
+		{"InsertIgnore", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.InsertIgnore(ctx, u) }},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(orm.SQLServer)
+		if err := tt.run(t.Context(), newTestQuery(tq), &testUser{ID: 1, Name: "alice"}); err == nil {
+			t.Errorf("%s on SQL Server: want an error", tt.name)
+		}
+		if len(tq.Queries) != 0 {
+			t.Errorf("%s: ran %d queries, want none", tt.name, len(tq.Queries))
+		}
+	}
+}
+
+type testContact struct {
+	ID       int
+	TenantID int
+	Email    string
+	Name     string
+}
+
+func testContactColValPairs(c *testContact, includesPK bool) ([]string, []any) {
+	if includesPK {
+		return []string{"id", "tenant_id", "email", "name"}, []any{c.ID, c.TenantID, c.Email, c.Name}
+	}
+	return []string{"tenant_id", "email", "name"}, []any{c.TenantID, c.Email, c.Name}
+}
+
+func newTestContactQuery(tq *orm.TestQuerier) *orm.Query[testContact] {
+	return orm.NewQuery[testContact](tq, "contacts", []string{"id", "tenant_id", "email", "name"}, "id",
+		func(*sql.Rows) (testContact, error) { return testContact{}, nil }, testContactColValPairs,
+		func(c *testContact, id int64) { c.ID = int(id) })
+}
+
+func TestBuildUpsertOnConflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		cols    []string
+		want    string
+	}{
+		{"PostgreSQL single column", orm.PostgreSQL, []string{"email"},
+			`INSERT INTO "contacts" ("tenant_id", "email", "name") VALUES ($1, $2, $3)` +
+				` ON CONFLICT ("email") DO UPDATE SET "tenant_id" = EXCLUDED."tenant_id", "name" = EXCLUDED."name" RETURNING "id"`},
+		{"PostgreSQL composite", orm.PostgreSQL, []string{"tenant_id", "email"},
+			`INSERT INTO "contacts" ("tenant_id", "email", "name") VALUES ($1, $2, $3)` +
+				` ON CONFLICT ("tenant_id", "email") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id"`},
+		{"MySQL", orm.MySQL, []string{"tenant_id", "email"},
+			"INSERT INTO `contacts` (`tenant_id`, `email`, `name`) VALUES (?, ?, ?)" +
+				" ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		c := testContact{TenantID: 3, Email: "a@example.com", Name: "alice"}
+		_ = newTestContactQuery(tq).OnConflict(tt.cols...).Upsert(t.Context(), &c)
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got.SQL, tt.want)
+		}
+		if len(got.Args) != 3 || got.Args[0] != 3 || got.Args[1] != "a@example.com" || got.Args[2] != "alice" {
+			t.Errorf("%s: Args = %v, want [3 a@example.com alice]", tt.name, got.Args)
+		}
+	}
+}
+
+func TestBuildUpsertOnConflictWithoutUpdateColumns(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := orm.NewQuery[testUser](tq, "users", testUserColumns, "id", scanTestUser, testUserColValPairs, nil)
+
+	_ = q.OnConflict("name").Upsert(t.Context(), &testUser{ID: 1, Name: "alice"})
+
+	// Every column is a key, so the row is kept as is.
+	want := `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("name") DO UPDATE SET "name" = "users"."name"`
+	if got := tq.LastQuery().SQL; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
+
+func TestImmutableColumnsExcludedFromUpdates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		write   func(q *orm.Query[testContact], ctx context.Context, c *testContact) error
+		want    string
+	}{
+		{
+			name:    "Create",
+			dialect: orm.MySQL,
+			write:   (*orm.Query[testContact]).Create,
+			want:    "INSERT INTO `contacts` (`tenant_id`, `email`, `name`) VALUES (?, ?, ?)",
+		},
+		{
+			name:    "Update",
+			dialect: orm.MySQL,
+			write:   (*orm.Query[testContact]).Update,
+			want:    "UPDATE `contacts` SET `email` = ?, `name` = ? WHERE `id` = ?",
+		},
+		{
+			name:    "Upsert PostgreSQL",
+			dialect: orm.PostgreSQL,
+			write:   (*orm.Query[testContact]).Upsert,
+			want: `INSERT INTO "contacts" ("id", "tenant_id", "email", "name") VALUES ($1, $2, $3, $4)` +
+				` ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "name" = EXCLUDED."name" RETURNING "id"`,
+		},
+		{
+			name:    "Upsert MySQL",
+			dialect: orm.MySQL,
+			write:   (*orm.Query[testContact]).Upsert,
+			want: "INSERT INTO `contacts` (`id`, `tenant_id`, `email`, `name`) VALUES (?, ?, ?, ?)" +
+				" ON DUPLICATE KEY UPDATE `email` = VALUES(`email`), `name` = VALUES(`name`)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestContactQuery(tq)
+			q.RegisterImmutable([]string{"tenant_id"})
+			c := testContact{ID: 1, TenantID: 3, Email: "a@example.com", Name: "alice"}
+			_ = tt.write(q, t.Context(), &c)
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func newServerTimestampArticleQuery(tq *orm.TestQuerier) *orm.Query[testArticle] {
+	q := orm.NewQuery[testArticle](tq, "articles", testArticleColumns, "id", scanTestArticle, testArticleColValPairs, setTestArticlePK)
+	q.RegisterTimestamps(nil, nil, []string{"updated_at"}, setTestArticleUpdatedAt)
+	q.RegisterServerTimestamps([]string{"created_at"}, func(a *testArticle) []any { return []any{&a.CreatedAt} })
+	return q
+}
+
+func TestServerTimestampsExcludedFromWrites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		write   func(q *orm.Query[testArticle], ctx context.Context, a *testArticle) error
+		want    string
+	}{
+		{
+			name:    "Create MySQL",
+			dialect: orm.MySQL,
+			write:   (*orm.Query[testArticle]).Create,
+			want:    "INSERT INTO `articles` (`title`, `updated_at`) VALUES (?, ?)",
+		},
+		{
+			name:    "Create PostgreSQL reads back via RETURNING",
+			dialect: orm.PostgreSQL,
+			write:   (*orm.Query[testArticle]).Create,
+			want:    `INSERT INTO "articles" ("title", "updated_at") VALUES ($1, $2) RETURNING "id", "created_at"`,
+		},
+		{
+			name:    "Upsert PostgreSQL",
+			dialect: orm.PostgreSQL,
+			write:   (*orm.Query[testArticle]).Upsert,
+			want: `INSERT INTO "articles" ("id", "title", "updated_at") VALUES ($1, $2, $3)` +
+				` ON CONFLICT ("id") DO UPDATE SET "title" = EXCLUDED."title", "updated_at" = EXCLUDED."updated_at"` +
+				` RETURNING "id", "created_at"`,
+		},
+		{
+			name:    "Update",
+			dialect: orm.MySQL,
+			write:   (*orm.Query[testArticle]).Update,
+			want:    "UPDATE `articles` SET `title` = ?, `updated_at` = ? WHERE `id` = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			a := testArticle{ID: 1, Title: "hello"}
+			_ = tt.write(newServerTimestampArticleQuery(tq), t.Context(), &a)
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+			if !a.CreatedAt.IsZero() {
+				t.Errorf("CreatedAt = %v, want zero (set by the database)", a.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestCreateAllServerTimestampsPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	items := []*testArticle{{Title: "a"}, {Title: "b"}}
+	_ = newServerTimestampArticleQuery(tq).CreateAll(t.Context(), items)
+
+	want := `INSERT INTO "articles" ("title", "updated_at") VALUES ($1, $2), ($3, $4) RETURNING "id", "created_at"`
+	if got := tq.LastQuery().SQL; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
+
+func TestBuildUpdateColumns(t *testing.T) {
+	t.Parallel()
+
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+	tq := orm.NewTestQuerier(orm.MySQL)
+
+	a := testArticle{ID: 7, Title: "patched", CreatedAt: fixed.Add(-time.Hour)}
+	if err := newTestArticleQuery(tq).UpdateColumns(ctx, []string{"title"}, &a); err != nil {
+		t.Fatalf("UpdateColumns: %v", err)
+	}
+
+	got := tq.LastQuery()
+	want := "UPDATE `articles` SET `title` = ?, `updated_at` = ? WHERE `id` = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 3 || got.Args[0] != "patched" || got.Args[1] != fixed || got.Args[2] != 7 {
+		t.Errorf("Args = %v, want [patched %v 7]", got.Args, fixed)
+	}
+	if !a.UpdatedAt.Equal(fixed) {
+		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	}
+}
+
+func TestBuildUpdateColumnsVersion(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	tq.RowsAffected = 1
+	d := testDocument{ID: 1, Title: "draft", Version: 3}
+	if err := newTestDocumentQuery(tq).UpdateColumns(t.Context(), []string{"title"}, &d); err != nil {
+		t.Fatalf("UpdateColumns: %v", err)
+	}
+
+	want := "UPDATE `documents` SET `version` = `version` + 1, `title` = ? WHERE `id` = ? AND `version` = ?"
+	if got := tq.LastQuery().SQL; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+	if d.Version != 4 {
+		t.Errorf("Version = %d, want 4", d.Version)
+	}
+}
+
+func TestUpdateColumnsRejectsColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cols []string
+	}{
+		{"none", nil},
+		{"unknown", []string{"title", "nope"}},
+		{"primary key", []string{"id"}},
+		{"immutable", []string{"created_at"}},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(orm.MySQL)
+		q := newTestArticleQuery(tq)
+		q.RegisterImmutable([]string{"created_at"})
+
+		err := q.UpdateColumns(t.Context(), tt.cols, &testArticle{ID: 1})
+		if err == nil {
+			t.Errorf("%s: err = nil, want an error", tt.name)
+		}
+		if len(tq.Queries) != 0 {
+			t.Errorf("%s: ran %d queries, want none", tt.name, len(tq.Queries))
+		}
+	}
+}
+
+func TestCreateAutoSetsTimestamps(t *testing.T) {
+	t.Parallel()
+
+	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestArticleQuery(tq)
+
+	a := testArticle{Title: "hello"}
+	_ = q.Create(ctx, &a)
+
+	if a.CreatedAt != fixed {
+		t.Errorf("CreatedAt = %v, want %v", a.CreatedAt, fixed)
+	}
+	if a.UpdatedAt != fixed {
+		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	}
+}
+
+func TestCreatePreservesExistingCreatedAt(t *testing.T) {
+	t.Parallel()
+
+	existing := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestArticleQuery(tq)
+
+	a := testArticle{Title: "hello", CreatedAt: existing}
+	_ = q.Create(ctx, &a)
+
+	if a.CreatedAt != existing {
+		t.Errorf("CreatedAt = %v, want %v (should not be overwritten)", a.CreatedAt, existing)
+	}
+	if a.UpdatedAt != fixed {
+		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	}
+}
+
+func TestUpdateOnlySetsUpdatedAt(t *testing.T) {
+	t.Parallel()
+
+	existing := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestArticleQuery(tq)
+
+	a := testArticle{ID: 1, Title: "hello", CreatedAt: existing}
+	_ = q.Update(ctx, &a)
+
+	if a.CreatedAt != existing {
+		t.Errorf("CreatedAt = %v, want %v (Update should not touch createdAt)", a.CreatedAt, existing)
+	}
+	if a.UpdatedAt != fixed {
+		t.Errorf("UpdatedAt = %v, want %v", a.UpdatedAt, fixed)
+	}
+}
+
+// --- scope.Join / scope.LeftJoin / scope.Preload via Scopes ---
+
+func TestBuildSelectWithScopeJoin(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	})
+
+	_, _ = q.Scopes(scope.Join("Posts")).All(t.Context())
+
+	got := tq.LastQuery()
+	if !strings.Contains(got.SQL, "INNER JOIN") {
+		t.Errorf("SQL should contain INNER JOIN: %q", got.SQL)
+	}
+	want := "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectWithScopeLeftJoin(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	})
+
+	_, _ = q.Scopes(scope.LeftJoin("Posts")).All(t.Context())
+
+	got := tq.LastQuery()
+	if !strings.Contains(got.SQL, "LEFT JOIN") {
+		t.Errorf("SQL should contain LEFT JOIN: %q", got.SQL)
+	}
+	want := "SELECT `users`.`id`, `users`.`name` FROM `users` LEFT JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectColumnQualification(t *testing.T) {
+	t.Parallel()
+
+	postsJoin := orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	}
+
+	tests := []struct {
+		name  string
+		build func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want  string
+	}{
+		{
+			name:  "no join stays unqualified",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q },
+			want:  "SELECT `id`, `name` FROM `users`",
+		},
+		{
+			name:  "unknown join name adds nothing",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Join("Nope") },
+			want:  "SELECT `id`, `name` FROM `users`",
+		},
+		{
+			name:  "join without select columns qualifies base columns",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Join("Posts") },
+			want:  "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`",
+		},
+		{
+			name: "explicit Select disables qualification",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Posts").Select("users.name")
+			},
+			want: "SELECT users.name FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`",
+		},
+		{
+			name: "explicit Select via scope disables qualification",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Scopes(scope.Select("id"))
+			},
+			want: "SELECT `id` FROM `users`",
+		},
+		{
+			name: "keyset qualifies its WHERE and ORDER BY",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Posts").Scopes(scope.Keyset("id", 42, false)...).Limit(20)
+			},
+			want: "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`" +
+				" WHERE `users`.`id` > ? ORDER BY `users`.`id` ASC LIMIT 20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			q := newTestQuery(tq)
+			q.RegisterJoin("Posts", postsJoin)
+
+			_, _ = tt.build(q).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSelectJoinSharedColumns(t *testing.T) {
+	t.Parallel()
+
+	// users and teams both have id and name: every column must stay
+	// unambiguous, so that the scanner reads the base table's values.
+	teamJoin := orm.JoinConfig{
+		TargetTable:   "teams",
+		TargetColumn:  "id",
+		SourceTable:   "users",
+		SourceColumn:  "team_id",
+		SelectColumns: []string{"id", "name"},
+	}
+	const join = " FROM `users` INNER JOIN `teams` ON `teams`.`id` = `users`.`team_id`"
+
+	tests := []struct {
+		name  string
+		build func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want  string
+	}{
+		{
+			name:  "star expands to qualified and aliased columns",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Join("Team").Select("*") },
+			want:  "SELECT `users`.`id`, `users`.`name`, `teams`.`id` AS `Team__id`, `teams`.`name` AS `Team__name`" + join,
+		},
+		{
+			name:  "select columns are qualified with the base table",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Join("Team").SelectColumns("id", "name") },
+			want:  "SELECT `users`.`id`, `users`.`name`" + join,
+		},
+		{
+			name:  "select columns before the join",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.SelectColumns("id").Join("Team") },
+			want:  "SELECT `users`.`id`" + join,
+		},
+		{
+			name: "qualified columns and expressions are kept",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Team").SelectColumns("id", "teams.name", "COUNT(*) AS n")
+			},
+			want: "SELECT `users`.`id`, `teams`.`name`, COUNT(*) AS n" + join,
+		},
+		{
+			name:  "star without a join is kept",
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Select("*") },
+			want:  "SELECT * FROM `users`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			q := newTestQuery(tq)
+			q.RegisterJoin("Team", teamJoin)
+
+			_, _ = tt.build(q).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSelectWithJoinSelectColumns(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Author", orm.JoinConfig{
+		TargetTable:   "authors",
+		TargetColumn:  "id",
+		SourceTable:   "users",
+		SourceColumn:  "author_id",
+		SelectColumns: []string{"id", "name"},
+	})
+
+	_, _ = q.Join("Author").All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `users`.`id`, `users`.`name`, `authors`.`id` AS `Author__id`, `authors`.`name` AS `Author__name` FROM `users` INNER JOIN `authors` ON `authors`.`id` = `users`.`author_id`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectWithJoinSelectColumnsPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Author", orm.JoinConfig{
+		TargetTable:   "authors",
+		TargetColumn:  "id",
+		SourceTable:   "users",
+		SourceColumn:  "author_id",
+		SelectColumns: []string{"id", "name"},
+	})
+
+	_, _ = q.Join("Author").All(t.Context())
+
+	got := tq.LastQuery()
+	want := `SELECT "users"."id", "users"."name", "authors"."id" AS "Author__id", "authors"."name" AS "Author__name" FROM "users" INNER JOIN "authors" ON "authors"."id" = "users"."author_id"`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectWithSelfJoin(t *testing.T) {
+	t.Parallel()
+
+	// A self-referencing relation joins the base table again under the
+	// relation name.
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Manager", orm.JoinConfig{
+		TargetTable:   "users",
+		TargetColumn:  "id",
+		SourceTable:   "users",
+		SourceColumn:  "manager_id",
+		SelectColumns: []string{"id", "name"},
+	})
+
+	_, _ = q.LeftJoin("Manager").All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `users`.`id`, `users`.`name`, `Manager`.`id` AS `Manager__id`, `Manager`.`name` AS `Manager__name` FROM `users` LEFT JOIN `users` AS `Manager` ON `Manager`.`id` = `users`.`manager_id`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+
+	_, _ = q.JoinWhere("Manager", "name = ?", "alice").All(t.Context())
+
+	got = tq.LastQuery()
+	want = "SELECT `users`.`id`, `users`.`name`, `Manager`.`id` AS `Manager__id`, `Manager`.`name` AS `Manager__name` FROM `users` INNER JOIN `users` AS `Manager` ON `Manager`.`id` = `users`.`manager_id` WHERE `Manager`.`name` = ?"
+	if got.SQL != want {
+		t.Errorf("JoinWhere SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectWithJoinNoSelectColumns(t *testing.T) {
+	t.Parallel()
+
+	// When SelectColumns is nil (e.g. has_many), no extra columns are added.
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	})
+
+	_, _ = q.Join("Posts").All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectDistinct(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	})
+
+	tests := []struct {
+		name string
+		q    *orm.Query[testUser]
+		want string
+	}{
+		{"columns", q.Distinct(), "SELECT DISTINCT `id`, `name` FROM `users`"},
+		{"scope", q.Scopes(scope.Distinct()), "SELECT DISTINCT `id`, `name` FROM `users`"},
+		{"Select", q.Distinct().Select("name"), "SELECT DISTINCT name FROM `users`"},
+		{"join columns", q.Join("Posts").Distinct().Where("posts.published"),
+			"SELECT DISTINCT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id` WHERE posts.published"},
+		{"after hint", q.Distinct().Hint("/*+ MAX_EXECUTION_TIME(1000) */"),
+			"SELECT /*+ MAX_EXECUTION_TIME(1000) */ DISTINCT `id`, `name` FROM `users`"},
+	}
+	for _, tt := range tests {
+		_, _ = tt.q.All(t.Context())
+		if got := tq.LastQuery().SQL; got != tt.want {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	_, _ = q.All(t.Context())
+	if got := tq.LastQuery().SQL; got != "SELECT `id`, `name` FROM `users`" {
+		t.Errorf("base query SQL = %q, want no DISTINCT", got)
+	}
+
+	_, _ = orm.ExistingIDs(t.Context(), q.Distinct(), []int{1})
+	if got, want := tq.LastQuery().SQL, "SELECT DISTINCT `users`.`id` FROM `users` WHERE `users`.`id` IN (?)"; got != want {
+		t.Errorf("ExistingIDs SQL = %q, want %q", got, want)
+	}
+}
+
+func TestCountDistinctRows(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	_, _ = newTestQuery(tq).Distinct().Select("name").Where("id > ?", 1).Count(t.Context())
+
+	want := `SELECT COUNT(*) FROM (SELECT DISTINCT name FROM "users" WHERE id > $1) AS distinct_rows`
+	if got := tq.LastQuery().SQL; got != want {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
+
+func TestBuildSelectJoinWhere(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		clause  string
+		args    []any
+		where   string
+	}{
+		{"bare column", orm.MySQL, "published = ?", []any{true}, "`posts`.`published` = ?"},
+		{"bare boolean column", orm.MySQL, "published", nil, "`posts`.`published`"},
+		{"IS NULL", orm.MySQL, "deleted_at IS NULL", nil, "`posts`.`deleted_at` IS NULL"},
+		{"IN without space", orm.MySQL, "status IN(?, ?)", []any{"a", "b"}, "`posts`.`status` IN(?, ?)"},
+		{"NOT LIKE", orm.MySQL, "title NOT LIKE ?", []any{"%draft%"}, "`posts`.`title` NOT LIKE ?"},
+		{"already qualified", orm.MySQL, "posts.published = ?", []any{true}, "posts.published = ?"},
+		{"function call", orm.MySQL, "LOWER(title) = ?", []any{"x"}, "LOWER(title) = ?"},
+		{"leading keyword", orm.MySQL, "NOT published", nil, "NOT published"},
+		{"PostgreSQL placeholders", orm.PostgreSQL, "published = ? AND title <> ?", []any{true, "x"}, `"posts"."published" = $2 AND title <> $3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestQuery(tq)
+			q.RegisterJoin("Posts", orm.JoinConfig{
+				TargetTable:  "posts",
+				TargetColumn: "user_id",
+				SourceTable:  "users",
+				SourceColumn: "id",
+			})
+
+			_, _ = q.Where("name = ?", "alice").JoinWhere("Posts", tt.clause, tt.args...).All(t.Context())
+
+			got := tq.LastQuery()
+			wantJoin := " INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id` WHERE name = ? AND "
+			if tt.dialect == orm.PostgreSQL {
+				wantJoin = ` INNER JOIN "posts" ON "posts"."user_id" = "users"."id" WHERE name = $1 AND `
+			}
+			if !strings.HasSuffix(got.SQL, wantJoin+tt.where) {
+				t.Errorf("SQL = %q, want suffix %q", got.SQL, wantJoin+tt.where)
+			}
+			if len(got.Args) != 1+len(tt.args) {
+				t.Errorf("Args = %v, want name followed by %v", got.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestBuildSelectJoinWhereUnknownRelationKeepsFilter(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	_, _ = newTestQuery(tq).JoinWhere("Nope", "published = ?", true).All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users` WHERE published = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildSelectWithScopePreload(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+	q.RegisterPreloader("Posts", func(_ context.Context, _ orm.Querier, _ []testUser) error {
+		return nil
+	})
+
+	// Scopes(scope.Preload("Posts")) should not affect the generated SQL;
+	// preloads are executed after the main query.
+	_, _ = q.Scopes(scope.Preload("Posts")).All(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT `id`, `name` FROM `users`"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q (preload should not alter SQL)", got.SQL, want)
+	}
+}
+
+// --- PreloadStrategy ---
+
+func newPreloadTestQuery(tq *orm.TestQuerier) *orm.Query[testUser] {
+	q := newTestQuery(tq)
+	q.RegisterJoin("Author", orm.JoinConfig{
+		TargetTable:   "authors",
+		TargetColumn:  "id",
+		SourceTable:   "users",
+		SourceColumn:  "author_id",
+		SelectColumns: []string{"id", "name"},
+	})
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	})
+	noop := func(context.Context, orm.Querier, []testUser) error { return nil }
+	q.RegisterPreloader("Author", noop)
+	q.RegisterPreloader("Posts", noop)
+	return q
+}
+
+func TestPreloadStrategy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		separate = "SELECT `id`, `name` FROM `users`"
+		joined   = "SELECT `users`.`id`, `users`.`name`, `authors`.`id` AS `Author__id`, `authors`.`name` AS `Author__name` FROM `users` LEFT JOIN `authors` ON `authors`.`id` = `users`.`author_id`"
+	)
+
+	tests := []struct {
+		name  string
+		db    orm.PreloadStrategy
+		query func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want  string
+	}{
+		{
+			name:  "separate by default",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Preload("Author") },
+			want:  separate,
+		},
+		{
+			name: "join from querier; to-many stays separate",
+			db:   orm.PreloadJoin,
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Preload("Author").Preload("Posts")
+			},
+			want: joined,
+		},
+		{
+			name: "query overrides querier to join",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Preload("Author").PreloadStrategy(orm.PreloadJoin)
+			},
+			want: joined,
+		},
+		{
+			name: "query overrides querier to separate",
+			db:   orm.PreloadJoin,
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Preload("Author").PreloadStrategy(orm.PreloadSeparate)
+			},
+			want: separate,
+		},
+		{
+			name: "select override falls back to separate",
+			db:   orm.PreloadJoin,
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Select("id").Preload("Author")
+			},
+			want: "SELECT id FROM `users`",
+		},
+		{
+			name: "explicit join is not joined twice",
+			db:   orm.PreloadJoin,
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Author").Preload("Author")
+			},
+			want: "SELECT `users`.`id`, `users`.`name`, `authors`.`id` AS `Author__id`, `authors`.`name` AS `Author__name` FROM `users` INNER JOIN `authors` ON `authors`.`id` = `users`.`author_id`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			tq.Preloads = tt.db
+
+			_, _ = tt.query(newPreloadTestQuery(tq)).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func newJoinPreloadTestQuery(tq *orm.TestQuerier) *orm.Query[testUser] {
+	q := newPreloadTestQuery(tq)
+	q.RegisterJoin("Posts", orm.JoinConfig{
+		TargetTable:   "posts",
+		TargetColumn:  "user_id",
+		SourceTable:   "users",
+		SourceColumn:  "id",
+		SelectColumns: []string{"id", "title"},
+	})
+	q.RegisterJoin("Comments", orm.JoinConfig{
+		TargetTable:   "comments",
+		TargetColumn:  "user_id",
+		SourceTable:   "users",
+		SourceColumn:  "id",
+		SelectColumns: []string{"id"},
+	})
+	merge := func(_, _ *testUser) {}
+	q.RegisterMerge("Posts", merge)
+	q.RegisterMerge("Comments", merge)
+	q.RegisterPreloader("Comments", func(context.Context, orm.Querier, []testUser) error { return nil })
+	return q
+}
+
+func TestJoinPreload(t *testing.T) {
+	t.Parallel()
+
+	const (
+		base        = "SELECT `id`, `name` FROM `users`"
+		postsJoined = "SELECT `users`.`id`, `users`.`name`, `posts`.`id` AS `Posts__id`, `posts`.`title` AS `Posts__title`" +
+			" FROM `users` LEFT JOIN `posts` ON `posts`.`user_id` = `users`.`id`"
+	)
+
+	tests := []struct {
+		name  string
+		db    orm.PreloadStrategy
+		query func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want  string
+	}{
+		{
+			name:  "has_many",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.JoinPreload("Posts") },
+			want:  postsJoined,
+		},
+		{
+			name: "to-one under the separate strategy",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.JoinPreload("Author")
+			},
+			want: "SELECT `users`.`id`, `users`.`name`, `authors`.`id` AS `Author__id`, `authors`.`name` AS `Author__name`" +
+				" FROM `users` LEFT JOIN `authors` ON `authors`.`id` = `users`.`author_id`",
+		},
+		{
+			name: "PreloadJoin strategy leaves has_many separate",
+			db:   orm.PreloadJoin,
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Preload("Posts")
+			},
+			want: base,
+		},
+		{
+			name: "limit falls back to separate",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.JoinPreload("Posts").Limit(10)
+			},
+			want: base + " LIMIT 10",
+		},
+		{
+			name: "only one to-many relation is joined",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.JoinPreload("Posts").JoinPreload("Comments")
+			},
+			want: postsJoined,
+		},
+		{
+			name: "explicit to-many join selects no columns",
+			query: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Posts").JoinPreload("Comments")
+			},
+			want: "SELECT `users`.`id`, `users`.`name` FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			tq.Preloads = tt.db
+
+			_, _ = tt.query(newJoinPreloadTestQuery(tq)).All(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeJoined(t *testing.T) {
+	t.Parallel()
+
+	type blogger struct {
+		ID    int
+		Posts []string
+	}
+	q := orm.NewQuery[blogger](orm.NewTestQuerier(orm.MySQL), "bloggers", []string{"id"}, "id", nil, nil, nil)
+	q.RegisterPK(func(b *blogger) any { return b.ID })
+	q.RegisterMerge("Posts", func(dst, src *blogger) { dst.Posts = append(dst.Posts, src.Posts...) })
+
+	rows := []blogger{
+		{ID: 2, Posts: []string{"a"}},
+		{ID: 1, Posts: []string{}},
+		{ID: 2, Posts: []string{"b"}},
+		{ID: 2, Posts: []string{"c"}},
+	}
+	got := orm.MergeJoined(q, "Posts", rows)
+
+	want := []blogger{
+		{ID: 2, Posts: []string{"a", "b", "c"}},
+		{ID: 1, Posts: []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeJoined = %+v, want %+v", got, want)
+	}
+	if got[1].Posts == nil {
+		t.Error("a parent without children should keep an empty, non-nil slice")
+	}
+}
+
+// --- ShardKey ---
+
+func TestShardKeyReachesQuerier(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq).ShardKey("tenant_id", 42).Where("id = ?", 1)
+	ctx := t.Context()
+
+	_, _ = q.All(ctx)
+	_, _ = q.Count(ctx)
+	_ = q.Update(ctx, &testUser{ID: 1, Name: "alice"})
+	_ = q.Delete(ctx)
+
+	if len(tq.Queries) != 4 {
+		t.Fatalf("len(Queries) = %d, want 4", len(tq.Queries))
+	}
+	for _, got := range tq.Queries {
+		k, ok := orm.ShardKeyFromContext(got.Ctx)
+		if !ok || k.Name != "tenant_id" || k.Value != 42 {
+			t.Errorf("%s: shard key = %+v (ok=%v), want tenant_id=42", got.SQL, k, ok)
+		}
+	}
+}
+
+func TestShardKeyFromContext(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	ctx := orm.WithShardKey(t.Context(), "tenant_id", 7)
+
+	_, _ = newTestQuery(tq).All(ctx)
+	if k, ok := orm.ShardKeyFromContext(tq.LastQuery().Ctx); !ok || k.Value != 7 {
+		t.Errorf("shard key = %+v (ok=%v), want tenant_id=7", k, ok)
+	}
+
+	// The query's own key wins over the context's.
+	_, _ = newTestQuery(tq).ShardKey("tenant_id", 8).All(ctx)
+	if k, _ := orm.ShardKeyFromContext(tq.LastQuery().Ctx); k.Value != 8 {
+		t.Errorf("shard key = %+v, want tenant_id=8", k)
+	}
+
+	// Without a key, nothing is attached.
+	_, _ = newTestQuery(tq).All(t.Context())
+	if k, ok := orm.ShardKeyFromContext(tq.LastQuery().Ctx); ok {
+		t.Errorf("unexpected shard key %+v", k)
+	}
+}
+
+// --- Validation ---
+
+func TestValidatorRejectsWrites(t *testing.T) {
+	t.Parallel()
+
+	errInvalid := errors.New("invalid name")
+	validate := func(u *testUser) error {
+		if u.Name == "" {
+			return errInvalid
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, q *orm.Query[testUser], u *testUser) error
+	}{
+		{"Create", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.Create(ctx, u) }},
+		{"CreateAll", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error {
+			return q.CreateAll(ctx, []*testUser{{Name: "ok"}, u})
+		}},
+		{"Upsert", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.Upsert(ctx, u) }},
+		{"InsertIgnore", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.InsertIgnore(ctx, u) }},
+		{"Update", func(ctx context.Context, q *orm.Query[testUser], u *testUser) error { return q.Update(ctx, u) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			q := newTestQuery(tq)
+			q.RegisterValidator(validate)
+
+			if err := tt.run(t.Context(), q, &testUser{ID: 1}); !errors.Is(err, errInvalid) {
+				t.Errorf("err = %v, want %v", err, errInvalid)
+			}
+			if len(tq.Queries) != 0 {
+				t.Errorf("expected no query, got %d", len(tq.Queries))
+			}
+
+			if err := tt.run(t.Context(), q, &testUser{ID: 1, Name: "alice"}); err != nil {
+				t.Errorf("valid row: %v", err)
+			}
+		})
+	}
+}
+
+// --- Default scopes ---
+
+func TestDefaultScopesFromContext(t *testing.T) {
+	t.Parallel()
+
+	tenant := orm.WithDefaultScopes(t.Context(), scope.Where("tenant_id = ?", 7))
+
+	tests := []struct {
+		name     string
+		run      func(ctx context.Context, q *orm.Query[testUser])
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "All",
+			run:      func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Where("name = ?", "alice").All(ctx) },
+			wantSQL:  "SELECT `id`, `name` FROM `users` WHERE (name = ?) AND tenant_id = ?",
+			wantArgs: []any{"alice", 7},
+		},
+		{
+			name:     "First",
+			run:      func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.First(ctx) },
+			wantSQL:  "SELECT `id`, `name` FROM `users` WHERE tenant_id = ? LIMIT 1",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "Count",
+			run:      func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Count(ctx) },
+			wantSQL:  "SELECT COUNT(*) FROM `users` WHERE tenant_id = ?",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "Exists",
+			run:      func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.Exists(ctx) },
+			wantSQL:  "SELECT 1 FROM `users` WHERE tenant_id = ? LIMIT 1",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "Delete",
+			run:      func(ctx context.Context, q *orm.Query[testUser]) { _ = q.Where("id = ?", 1).Delete(ctx) },
+			wantSQL:  "DELETE FROM `users` WHERE (id = ?) AND tenant_id = ?",
+			wantArgs: []any{1, 7},
+		},
+		{
+			name: "All with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ? OR id = ?", "a", 2).All(ctx)
+			},
+			wantSQL:  "SELECT `id`, `name` FROM `users` WHERE (name = ? OR id = ?) AND tenant_id = ?",
+			wantArgs: []any{"a", 2, 7},
+		},
+		{
+			name: "Count with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ? OR id = ?", "a", 2).Count(ctx)
+			},
+			wantSQL:  "SELECT COUNT(*) FROM `users` WHERE (name = ? OR id = ?) AND tenant_id = ?",
+			wantArgs: []any{"a", 2, 7},
+		},
+		{
+			name: "Updates with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_ = q.Where("name = ? OR id = ?", "a", 2).Updates(ctx, map[string]any{"name": "b"})
+			},
+			wantSQL:  "UPDATE `users` SET `name` = ? WHERE (name = ? OR id = ?) AND tenant_id = ?",
+			wantArgs: []any{"b", "a", 2, 7},
+		},
+		{
+			name: "Delete with OR",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_ = q.Where("name = ? OR id = ?", "a", 2).Delete(ctx)
+			},
+			wantSQL:  "DELETE FROM `users` WHERE (name = ? OR id = ?) AND tenant_id = ?",
+			wantArgs: []any{"a", 2, 7},
+		},
+		{
+			name: "multiple conditions form one group",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.Where("name = ?", "a").Where("active").All(ctx)
+			},
+			wantSQL:  "SELECT `id`, `name` FROM `users` WHERE (name = ? AND active) AND tenant_id = ?",
+			wantArgs: []any{"a", 7},
+		},
+		{
+			name: "nested contexts add up",
+			run: func(ctx context.Context, q *orm.Query[testUser]) {
+				_, _ = q.All(orm.WithDefaultScopes(ctx, scope.Where("active")))
+			},
+			wantSQL:  "SELECT `id`, `name` FROM `users` WHERE tenant_id = ? AND active",
+			wantArgs: []any{7},
+		},
+		{
+			name:    "WithoutDefaultScopes opts out",
+			run:     func(ctx context.Context, q *orm.Query[testUser]) { _, _ = q.WithoutDefaultScopes().All(ctx) },
+			wantSQL: "SELECT `id`, `name` FROM `users`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			tt.run(tenant, newTestQuery(tq))
+
+			got := tq.LastQuery()
+			if got.SQL != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", got.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(got.Args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", got.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDefaultScopesKeepWriteGuards(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	ctx := orm.WithDefaultScopes(t.Context(), scope.Where("tenant_id = ?", 7))
+
+	// A default scope does not count as the WHERE that Delete and Updates
+	// require.
+	if err := newTestQuery(tq).Delete(ctx); err == nil {
+		t.Error("Delete: expected error without WHERE")
+	}
+	if err := newTestQuery(tq).Updates(ctx, map[string]any{"name": "x"}); err == nil {
+		t.Error("Updates: expected error without WHERE")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no query, got %d", len(tq.Queries))
+	}
+}
+
+func TestDefaultScopesRejectPreparedQuery(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	ctx := orm.WithDefaultScopes(t.Context(), scope.Where("tenant_id = ?", 7))
+
+	if _, err := newTestQuery(tq).Prepare().All(ctx); err == nil || !strings.Contains(err.Error(), "default scopes") {
+		t.Errorf("err = %v, want a default scopes error", err)
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no query, got %d", len(tq.Queries))
+	}
+
+	_, _ = newTestQuery(tq).WithoutDefaultScopes().Prepare().All(ctx)
+	if len(tq.Queries) != 1 {
+		t.Errorf("len(Queries) = %d, want 1 once opted out", len(tq.Queries))
+	}
+}
+
+// --- Query timeout ---
+
+func TestQueryTimeoutAbortsBlockedStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, q *orm.Query[testUser]) error
+	}{
+		{"All", func(ctx context.Context, q *orm.Query[testUser]) error {
+			_, err := q.All(ctx)
+			return err
+		}},
+		{"Count", func(ctx context.Context, q *orm.Query[testUser]) error {
+			_, err := q.Count(ctx)
+			return err
+		}},
+		{"Create", func(ctx context.Context, q *orm.Query[testUser]) error {
+			return q.Create(ctx, &testUser{Name: "alice"})
+		}},
+		{"Update", func(ctx context.Context, q *orm.Query[testUser]) error {
+			return q.Update(ctx, &testUser{ID: 1, Name: "alice"})
+		}},
+		{"Delete", func(ctx context.Context, q *orm.Query[testUser]) error {
+			return q.Where("id = ?", 1).Delete(ctx)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(orm.MySQL)
+			tq.Block = true
+			ctx := orm.WithQueryTimeout(t.Context(), 10*time.Millisecond)
+
+			err := tt.run(ctx, newTestQuery(tq))
+			if !errors.Is(err, orm.ErrQueryTimeout) {
+				t.Errorf("err = %v, want ErrQueryTimeout", err)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("err = %v, want it to wrap context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestQueryTimeoutSetsStatementDeadline(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.All(orm.WithQueryTimeout(t.Context(), time.Minute))
+	if _, ok := tq.LastQuery().Ctx.Deadline(); !ok {
+		t.Error("expected the statement context to have a deadline")
+	}
+	if err := tq.LastQuery().Ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("statement context err = %v, want it released after the call", err)
+	}
+
+	_, _ = q.All(t.Context())
+	if _, ok := tq.LastQuery().Ctx.Deadline(); ok {
+		t.Error("unexpected deadline without WithQueryTimeout")
+	}
+}
+
+func TestQueryTimeoutKeepsCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	tq.Block = true
+	ctx, cancel := context.WithCancel(t.Context())
+	ctx = orm.WithQueryTimeout(ctx, time.Minute)
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := newTestQuery(tq).All(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if errors.Is(err, orm.ErrQueryTimeout) {
+		t.Errorf("err = %v, want the caller's cancellation unwrapped", err)
+	}
+}
+
+// --- Updates ---
+
+func TestUpdates(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	err := q.Where("id = ?", 123).Updates(t.Context(), map[string]any{
+		"name": "new name",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := tq.LastQuery()
+	want := "UPDATE `users` SET `name` = ? WHERE id = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 2 || got.Args[0] != "new name" || got.Args[1] != 123 {
+		t.Errorf("Args = %v", got.Args)
+	}
+}
+
+func TestUpdatesPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq)
+
+	err := q.Where("id = ?", 123).Updates(t.Context(), map[string]any{
+		"name": "new name",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := tq.LastQuery()
+	want := `UPDATE "users" SET "name" = $1 WHERE id = $2`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestUpdatesAutoUpdatedAt(t *testing.T) {
+	t.Parallel()
+
+	fixed := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	ctx := orm.WithClock(t.Context(), fixedClock{t: fixed})
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestArticleQuery(tq)
+
+	err := q.Where("id = ?", 1).Updates(ctx, map[string]any{
+		"title": "updated title",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := tq.LastQuery()
+	// Should contain both title and updated_at in SET clause
+	if !strings.Contains(got.SQL, "`updated_at` = ?") {
+		t.Errorf("SQL should contain updated_at: %q", got.SQL)
+	}
+	if !strings.Contains(got.SQL, "`title` = ?") {
+		t.Errorf("SQL should contain title: %q", got.SQL)
+	}
+
+	// Verify updated_at value is the fixed time
+	foundUpdatedAt := false
+	for _, arg := range got.Args {
+		if ts, ok := arg.(time.Time); ok && ts.Equal(fixed) {
+			foundUpdatedAt = true
+			break
+		}
+	}
+	if !foundUpdatedAt {
+		t.Errorf("Args should contain fixed time %v: %v", fixed, got.Args)
+	}
+}
+
+func TestUpdatesWithoutWhereReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	err := q.Updates(t.Context(), map[string]any{"name": "oops"})
+	if err == nil {
+		t.Fatal("expected error for Updates without WHERE, got nil")
+	}
+}
+
+// --- ExistingIDs ---
+
+func TestBuildExistingIDs(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	_, _ = orm.ExistingIDs(t.Context(), q.Where("name = ?", "alice").OrderBy("id").Limit(5), []int{1, 2, 3})
+
+	got := tq.LastQuery()
+	want := "SELECT DISTINCT `users`.`id` FROM `users` WHERE name = ? AND `users`.`id` IN (?, ?, ?)"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+	if len(got.Args) != 4 || got.Args[0] != "alice" || got.Args[3] != 3 {
+		t.Errorf("Args = %v", got.Args)
+	}
+}
+
+func TestBuildExistingIDsPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq)
+
+	_, _ = orm.ExistingIDs(t.Context(), q, []string{"a", "b"})
+
+	got := tq.LastQuery()
+	want := `SELECT DISTINCT "users"."id" FROM "users" WHERE "users"."id" IN ($1, $2)`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestExistingIDsRejectsMissingPK(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := orm.NewQuery[testUser](tq, "users", testUserColumns, "", scanTestUser, nil, nil)
+	q.RegisterReadOnly()
+
+	if _, err := orm.ExistingIDs(t.Context(), q, []int{1}); err == nil {
+		t.Error("ExistingIDs: expected error without a primary key, got nil")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+func TestExistingIDsEmpty(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	ids, err := orm.ExistingIDs(t.Context(), q, []int{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 0 || len(tq.Queries) != 0 {
+		t.Errorf("ids = %v, queries = %d, want no query", ids, len(tq.Queries))
+	}
+}
+
+func TestBuildCountIgnoresLimitOffset(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.Where("name = ?", "alice").OrderBy("id").Limit(10).Offset(20).Count(t.Context())
+
+	got := tq.LastQuery()
+	want := "SELECT COUNT(*) FROM `users` WHERE name = ?"
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildExistsIgnoresOffset(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := newTestQuery(tq)
+
+	_, _ = q.Where("name = ?", "alice").Offset(5).Exists(t.Context())
+
+	got := tq.LastQuery()
+	want := `SELECT 1 FROM "users" WHERE name = $1 LIMIT 1`
+	if got.SQL != want {
+		t.Errorf("SQL = %q, want %q", got.SQL, want)
+	}
+}
+
+func TestBuildExists(t *testing.T) {
+	t.Parallel()
+
+	postsJoin := orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	}
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		build   func(q *orm.Query[testUser]) *orm.Query[testUser]
+		want    string
+	}{
+		{"MySQL", orm.MySQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Where("name = ?", "alice").OrderBy("id") },
+			"SELECT 1 FROM `users` WHERE name = ? LIMIT 1"},
+		{"PostgreSQL join", orm.PostgreSQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Join("Posts").Where("posts.published = ?", true).Limit(10)
+			},
+			`SELECT 1 FROM "users" INNER JOIN "posts" ON "posts"."user_id" = "users"."id" WHERE posts.published = $1 LIMIT 1`},
+		{"group by and having", orm.PostgreSQL,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.GroupBy("name").Having("COUNT(*) > ?", 1)
+			},
+			`SELECT 1 FROM "users" GROUP BY name HAVING COUNT(*) > $1 LIMIT 1`},
+		{"SQL Server", orm.SQLServer,
+			func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Where("name = ?", "alice") },
+			"SELECT 1 FROM [users] WHERE name = @p1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestQuery(tq)
+			q.RegisterJoin("Posts", postsJoin)
+			_, _ = tt.build(q).Exists(t.Context())
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistsReportsMatchingRow(t *testing.T) {
+	t.Parallel()
+
+	// The stub driver returns a row for queries mentioning "found".
+	tests := []struct {
+		table string
+		want  bool
+	}{
+		{"found_users", true},
+		{"users", false},
+	}
+	for _, tt := range tests {
+		obs := &recordingObserver{}
+		got, err := newStubUserQuery(t, tt.table, obs).Where("name = ?", "alice").Exists(t.Context())
+		if err != nil {
+			t.Fatalf("%s: Exists: %v", tt.table, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: Exists = %v, want %v", tt.table, got, tt.want)
+		}
+		want := "SELECT 1 FROM `" + tt.table + "` WHERE name = ? LIMIT 1"
+		if len(obs.infos) != 1 || obs.infos[0].SQL != want {
+			t.Errorf("%s: queries = %+v, want %q", tt.table, obs.infos, want)
+		}
+	}
+}
+
+func TestBuildCountDistinct(t *testing.T) {
+	t.Parallel()
+
+	postsJoin := orm.JoinConfig{
+		TargetTable:  "posts",
+		TargetColumn: "user_id",
+		SourceTable:  "users",
+		SourceColumn: "id",
+	}
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		expr    string
+		want    string
+	}{
+		{
+			name:    "qualified column",
+			dialect: orm.MySQL,
+			expr:    "users.id",
+			want:    "SELECT COUNT(DISTINCT `users`.`id`) FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id` WHERE posts.title = ?",
+		},
+		{
+			name:    "bare column on PostgreSQL",
+			dialect: orm.PostgreSQL,
+			expr:    "user_id",
+			want:    `SELECT COUNT(DISTINCT "user_id") FROM "users" INNER JOIN "posts" ON "posts"."user_id" = "users"."id" WHERE posts.title = $1`,
+		},
+		{
+			name:    "expression passes through",
+			dialect: orm.MySQL,
+			expr:    "LOWER(users.name)",
+			want:    "SELECT COUNT(DISTINCT LOWER(users.name)) FROM `users` INNER JOIN `posts` ON `posts`.`user_id` = `users`.`id` WHERE posts.title = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			q := newTestQuery(tq)
+			q.RegisterJoin("Posts", postsJoin)
+
+			_, _ = q.Join("Posts").Where("posts.title = ?", "hello").Limit(10).Offset(20).
+				CountDistinct(t.Context(), tt.expr)
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountDistinctRequiresExpression(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	if _, err := newTestQuery(tq).CountDistinct(t.Context(), " "); err == nil {
+		t.Fatal("expected error for empty expression, got nil")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+func TestWritesFailInReadOnlyTx(t *testing.T) {
+	t.Parallel()
+
+	tx := orm.NewReadOnlyTestTx(orm.PostgreSQL)
+	q := orm.NewQuery[testUser](tx, "users", testUserColumns, "id", scanTestUser, testUserColValPairs, setTestUserPK)
+	ctx := t.Context()
+	u := &testUser{ID: 1, Name: "alice"}
+
+	writes := map[string]func() error{
+		"Create":       func() error { return q.Create(ctx, u) },
+		"CreateResult": func() error { _, err := q.CreateResult(ctx, u); return err },
+		"CreateAll":    func() error { return q.CreateAll(ctx, []*testUser{u}) },
+		"Upsert":       func() error { return q.Upsert(ctx, u) },
+		"InsertIgnore": func() error { return q.InsertIgnore(ctx, u) },
+		"Update":       func() error { return q.Update(ctx, u) },
+		"Updates":      func() error { return q.Where("id = ?", 1).Updates(ctx, map[string]any{"name": "bob"}) },
+		"Delete":       func() error { return q.Where("id = ?", 1).Delete(ctx) },
+		"DeleteAll":    func() error { return q.DeleteAll(ctx) },
+		"UpdateResult": func() error { _, err := q.UpdateResult(ctx, u); return err },
+		"UpdatesResult": func() error {
+			_, err := q.Where("id = ?", 1).UpdatesResult(ctx, map[string]any{"name": "bob"})
+			return err
+		},
+		"DeleteResult": func() error { _, err := q.Where("id = ?", 1).DeleteResult(ctx); return err },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, orm.ErrReadOnly) {
+			t.Errorf("%s: err = %v, want ErrReadOnly", name, err)
+		}
+	}
+}
+
+func TestWritesFailOnReadOnlyModel(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	q := orm.NewQuery[testUser](tq, "users", testUserColumns, "", scanTestUser, nil, nil)
+	q.RegisterReadOnly()
+	ctx := t.Context()
+	u := &testUser{Name: "alice"}
+
+	writes := map[string]func() error{
+		"Create":       func() error { return q.Create(ctx, u) },
+		"CreateAll":    func() error { return q.CreateAll(ctx, []*testUser{u}) },
+		"Upsert":       func() error { return q.Upsert(ctx, u) },
+		"InsertIgnore": func() error { return q.InsertIgnore(ctx, u) },
+		"Update":       func() error { return q.Update(ctx, u) },
+		"Updates":      func() error { return q.Where("name = ?", "a").Updates(ctx, map[string]any{"name": "b"}) },
+		"Delete":       func() error { return q.Where("name = ?", "a").Delete(ctx) },
+		"DeleteAll":    func() error { return q.DeleteAll(ctx) },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, orm.ErrReadOnlyModel) {
+			t.Errorf("%s: err = %v, want ErrReadOnlyModel", name, err)
+		}
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+
+	_, _ = q.All(ctx)
+	if want := "SELECT `id`, `name` FROM `users`"; tq.LastQuery().SQL != want {
+		t.Errorf("SQL = %q, want %q", tq.LastQuery().SQL, want)
+	}
+}
+
+func TestBuildPluck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		build   func(q *orm.Query[testUser]) *orm.Query[testUser]
+		column  string
+		want    string
+	}{
+		{
+			name:    "bare column with conditions",
+			dialect: orm.MySQL,
+			build: func(q *orm.Query[testUser]) *orm.Query[testUser] {
+				return q.Where("id > ?", 1).OrderBy("name").Limit(5)
+			},
+			column: "name",
+			want:   "SELECT `name` FROM `users` WHERE id > ? ORDER BY name LIMIT 5",
+		},
+		{
+			name:    "qualified column",
+			dialect: orm.PostgreSQL,
+			build:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q.Where("id > ?", 1) },
+			column:  "users.name",
+			want:    `SELECT "users"."name" FROM "users" WHERE id > $1`,
+		},
+		{
+			name:    "expression",
+			dialect: orm.MySQL,
+			build:   func(q *orm.Query[testUser]) *orm.Query[testUser] { return q },
+			column:  "LOWER(name)",
+			want:    "SELECT LOWER(name) FROM `users`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tq := orm.NewTestQuerier(tt.dialect)
+			_, _ = orm.Pluck[string](t.Context(), tt.build(newTestQuery(tq)), tt.column)
+
+			if got := tq.LastQuery().SQL; got != tt.want {
+				t.Errorf("SQL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluckRequiresColumn(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	if _, err := orm.Pluck[string](t.Context(), newTestQuery(tq), ""); err == nil {
+		t.Fatal("expected error for empty column, got nil")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(tq.Queries))
+	}
+}
+
+func TestBuildUpsertReturning(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PostgreSQL returns all columns", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.PostgreSQL)
+		q := newTestQuery(tq).Where("name = ?", "ignored")
+
+		u := testUser{ID: 1, Name: "alice"}
+		_ = q.UpsertReturning(t.Context(), &u)
+
+		if len(tq.Queries) != 1 {
+			t.Fatalf("len(Queries) = %d, want 1", len(tq.Queries))
+		}
+		want := `INSERT INTO "users" ("id", "name") VALUES ($1, $2)` +
+			` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id", "name"`
+		if got := tq.LastQuery().SQL; got != want {
+			t.Errorf("SQL = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("MySQL reloads by primary key", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.MySQL)
+		q := newTestQuery(tq).Where("name = ?", "ignored").OrderBy("name")
+
+		u := testUser{ID: 7, Name: "alice"}
+		_ = q.UpsertReturning(t.Context(), &u)
+
+		if len(tq.Queries) != 2 {
+			t.Fatalf("len(Queries) = %d, want 2", len(tq.Queries))
+		}
+		if got := tq.Queries[0].SQL; !strings.Contains(got, "ON DUPLICATE KEY UPDATE") {
+			t.Errorf("first query = %q, want an upsert", got)
+		}
+		reload := tq.Queries[1]
+		want := "SELECT `id`, `name` FROM `users` WHERE `users`.`id` = ? LIMIT 1"
+		if reload.SQL != want {
+			t.Errorf("reload SQL = %q, want %q", reload.SQL, want)
+		}
+		if len(reload.Args) != 1 || reload.Args[0] != 7 {
+			t.Errorf("reload Args = %v, want [7]", reload.Args)
+		}
+	})
+
+	t.Run("read-only model", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.PostgreSQL)
+		q := newTestQuery(tq)
+		q.RegisterReadOnly()
+
+		if err := q.UpsertReturning(t.Context(), &testUser{ID: 1}); !errors.Is(err, orm.ErrReadOnlyModel) {
+			t.Errorf("err = %v, want ErrReadOnlyModel", err)
+		}
+		if len(tq.Queries) != 0 {
+			t.Errorf("expected no query, got %d", len(tq.Queries))
+		}
+	})
+}
+
+func scanTestUserInto(_ *sql.Rows, _ *testUser) error {
+	return nil
+}
+
+func TestBuildCreateReturning(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PostgreSQL appends RETURNING", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.PostgreSQL)
+		q := newTestQuery(tq)
+		q.RegisterScanInto(scanTestUserInto)
+
+		_ = q.CreateReturning(t.Context(), &testUser{Name: "alice"}, "name")
+
+		if len(tq.Queries) != 1 {
+			t.Fatalf("len(Queries) = %d, want 1", len(tq.Queries))
+		}
+		// The generated primary key is read back along with the requested columns.
+		want := `INSERT INTO "users" ("name") VALUES ($1) RETURNING "name", "id"`
+		if got := tq.LastQuery().SQL; got != want {
+			t.Errorf("SQL = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("MySQL selects the columns by primary key", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.MySQL)
+		tq.InsertIDs = []int64{9}
+		q := newTestQuery(tq).Where("name = ?", "ignored")
+		q.RegisterScanInto(scanTestUserInto)
+
+		u := testUser{Name: "alice"}
+		_ = q.CreateReturning(t.Context(), &u, "name")
+
+		if len(tq.Queries) != 2 {
+			t.Fatalf("len(Queries) = %d, want 2", len(tq.Queries))
+		}
+		if want := "INSERT INTO `users` (`name`) VALUES (?)"; tq.Queries[0].SQL != want {
+			t.Errorf("insert SQL = %q, want %q", tq.Queries[0].SQL, want)
+		}
+		reload := tq.Queries[1]
+		want := "SELECT `name` FROM `users` WHERE `users`.`id` = ? LIMIT 1"
+		if reload.SQL != want {
+			t.Errorf("reload SQL = %q, want %q", reload.SQL, want)
+		}
+		if len(reload.Args) != 1 || reload.Args[0] != 9 {
+			t.Errorf("reload Args = %v, want [9]", reload.Args)
+		}
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			scanInto bool
+			cols     []string
+			wantErr  string
+		}{
+			{"no columns", true, nil, "at least one column"},
+			{"unknown column", true, []string{"slug"}, `unknown column "slug"`},
+			{"no scanner", false, []string{"name"}, "RegisterScanInto"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				tq := orm.NewTestQuerier(orm.PostgreSQL)
+				q := newTestQuery(tq)
+				if tt.scanInto {
+					q.RegisterScanInto(scanTestUserInto)
+				}
+				err := q.CreateReturning(t.Context(), &testUser{Name: "alice"}, tt.cols...)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if len(tq.Queries) != 0 {
+					t.Errorf("expected no query, got %d", len(tq.Queries))
+				}
+			})
+		}
+	})
+}
+func TestCreateAllSafePKAssignment(t *testing.T) {
+	t.Parallel()
+
+	newUsers := func() []*testUser {
+		return []*testUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	}
+
+	t.Run("batch insert assumes contiguous IDs", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.MySQL)
+		tq.InsertIDs = []int64{10}
+		users := newUsers()
+		if err := newTestQuery(tq).CreateAll(t.Context(), users); err != nil {
+			t.Fatalf("CreateAll: %v", err)
+		}
+		if len(tq.Queries) != 1 {
+			t.Fatalf("len(Queries) = %d, want 1", len(tq.Queries))
+		}
+		for i, want := range []int{10, 11, 12} {
+			if users[i].ID != want {
+				t.Errorf("users[%d].ID = %d, want %d", i, users[i].ID, want)
+			}
+		}
+	})
+
+	t.Run("safe assignment survives gaps", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.MySQL)
+		tq.InsertIDs = []int64{10, 15, 11} // interleaved with another session
+		users := newUsers()
+		if err := newTestQuery(tq).SafePKAssignment().CreateAll(t.Context(), users); err != nil {
+			t.Fatalf("CreateAll: %v", err)
+		}
+		if len(tq.Queries) != 3 {
+			t.Fatalf("len(Queries) = %d, want one INSERT per row", len(tq.Queries))
+		}
+		if got, want := tq.Queries[1].SQL, "INSERT INTO `users` (`name`) VALUES (?)"; got != want {
+			t.Errorf("SQL = %q, want %q", got, want)
+		}
+		for i, want := range []int{10, 15, 11} {
+			if users[i].ID != want {
+				t.Errorf("users[%d].ID = %d, want %d", i, users[i].ID, want)
+			}
+		}
+	})
+
+	t.Run("RETURNING dialects keep the batch", func(t *testing.T) {
+		t.Parallel()
+
+		tq := orm.NewTestQuerier(orm.PostgreSQL)
+		_ = newTestQuery(tq).SafePKAssignment().CreateAll(t.Context(), newUsers())
+
+		want := `INSERT INTO "users" ("name") VALUES ($1), ($2), ($3) RETURNING "id"`
+		if len(tq.Queries) != 1 || tq.LastQuery().SQL != want {
+			t.Errorf("Queries = %v, want a single %q", tq.Queries, want)
+		}
+	})
+}
+
+func TestCreateAllChunksLargeBatches(t *testing.T) {
+	t.Parallel()
+
+	cols := []string{"a", "b", "c", "d", "e", "f", "g"}
+	colVals := func(u *testUser, _ bool) ([]string, []any) {
+		return cols, []any{u.ID, u.Name, 1, 2, 3, 4, 5}
+	}
+	tq := orm.NewTestQuerier(orm.PostgreSQL)
+	q := orm.NewQuery[testUser](tq, "wide", cols, "a", scanTestUser, colVals, nil)
+
+	items := make([]*testUser, 10000)
+	for i := range items {
+		items[i] = &testUser{ID: i, Name: "u"}
+	}
+	if err := q.CreateAll(t.Context(), items); err != nil {
+		t.Fatalf("CreateAll: %v", err)
+	}
+
+	// 65535 / 7 = 9362 rows fit in one statement.
+	if len(tq.Queries) != 2 {
+		t.Fatalf("len(Queries) = %d, want 2", len(tq.Queries))
+	}
+	for i, want := range []int{9362 * 7, 638 * 7} {
+		got := tq.Queries[i]
+		if len(got.Args) != want {
+			t.Errorf("Queries[%d]: %d args, want %d", i, len(got.Args), want)
+		}
+		if !strings.HasSuffix(got.SQL, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", want-6, want-5, want-4, want-3, want-2, want-1, want)) {
+			t.Errorf("Queries[%d]: placeholders do not end at $%d", i, want)
+		}
+	}
+	if got := tq.Queries[1].Args[0]; got != 9362 {
+		t.Errorf("second chunk starts with row %v, want 9362", got)
+	}
+}
+
+func TestCreateAllMaxBatchParams(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	tq.InsertIDs = []int64{10, 20, 30}
+	users := []*testUser{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+	if err := newTestQuery(tq).MaxBatchParams(2).CreateAll(t.Context(), users); err != nil {
+		t.Fatalf("CreateAll: %v", err)
+	}
+
+	wantSQL := []string{
+		"INSERT INTO `users` (`name`) VALUES (?), (?)",
+		"INSERT INTO `users` (`name`) VALUES (?), (?)",
+		"INSERT INTO `users` (`name`) VALUES (?)",
+	}
+	if len(tq.Queries) != len(wantSQL) {
+		t.Fatalf("len(Queries) = %d, want %d", len(tq.Queries), len(wantSQL))
+	}
+	for i, want := range wantSQL {
+		if tq.Queries[i].SQL != want {
+			t.Errorf("Queries[%d].SQL = %q, want %q", i, tq.Queries[i].SQL, want)
+		}
+	}
+	// Each chunk's IDs start at its own LastInsertId.
+	for i, want := range []int{10, 11, 20, 21, 30} {
+		if users[i].ID != want {
+			t.Errorf("users[%d].ID = %d, want %d", i, users[i].ID, want)
+		}
+	}
+}
+
+func TestCreateStream(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	tq.InsertIDs = []int64{1, 3, 5}
+
+	users := []*testUser{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+	ch := make(chan *testUser)
+	go func() {
+		defer close(ch)
+		for _, u := range users {
+			ch <- u
+		}
+	}()
+
+	if err := newTestQuery(tq).CreateStream(t.Context(), ch, 2); err != nil {
+		t.Fatalf("CreateStream: %v", err)
+	}
+
+	wantSQL := []string{
+		"INSERT INTO `users` (`name`) VALUES (?), (?)",
+		"INSERT INTO `users` (`name`) VALUES (?), (?)",
+		"INSERT INTO `users` (`name`) VALUES (?)", // partial final batch
+	}
+	if len(tq.Queries) != len(wantSQL) {
+		t.Fatalf("len(Queries) = %d, want %d", len(tq.Queries), len(wantSQL))
+	}
+	for i, want := range wantSQL {
+		if tq.Queries[i].SQL != want {
+			t.Errorf("Queries[%d].SQL = %q, want %q", i, tq.Queries[i].SQL, want)
+		}
+	}
+	for i, want := range []int{1, 2, 3, 4, 5} {
+		if users[i].ID != want {
+			t.Errorf("users[%d].ID = %d, want %d", i, users[i].ID, want)
+		}
+	}
+}
+
+func TestCreateStreamStopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	ctx, cancel := context.WithCancel(t.Context())
+
+	ch := make(chan *testUser, 1)
+	ch <- &testUser{Name: "a"}
+	go func() {
+		// The channel is never closed; only cancellation ends the stream.
+		for len(ch) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	if err := newTestQuery(tq).CreateStream(ctx, ch, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("expected the unflushed batch to be dropped, got %d queries", len(tq.Queries))
+	}
+}
+
+func TestCreateStreamRequiresPositiveBatchSize(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.MySQL)
+	if err := newTestQuery(tq).CreateStream(t.Context(), make(chan *testUser), 0); err == nil {
+		t.Error("expected error for batchSize 0")
+	}
+}
+
+func TestToSQLMatchesAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query func(q *orm.Query[testUser]) *orm.Query[testUser]
+	}{
+		{"plain", func(q *orm.Query[testUser]) *orm.Query[testUser] { return q }},
+		{"where, order and limit", func(q *orm.Query[testUser]) *orm.Query[testUser] {
+			return q.Where("name = ?", "alice").Where("id IN (?)", []int{1, 2}).OrderBy("id DESC").Limit(10).Offset(20)
+		}},
+		{"join preload", func(q *orm.Query[testUser]) *orm.Query[testUser] {
+			return q.Preload("Author").PreloadStrategy(orm.PreloadJoin)
+		}},
+		{"raw", func(q *orm.Query[testUser]) *orm.Query[testUser] {
+			return q.Raw("SELECT id, name FROM users WHERE name = ?", "bob")
+		}},
+	}
+	for dialect, d := range map[string]orm.Dialect{"mysql": orm.MySQL, "postgres": orm.PostgreSQL} {
+		for _, tt := range tests {
+			tq := orm.NewTestQuerier(d)
+			q := tt.query(newPreloadTestQuery(tq))
+
+			gotSQL, gotArgs := q.ToSQL()
+			if len(tq.Queries) != 0 {
+				t.Fatalf("%s/%s: ToSQL ran %d queries, want none", dialect, tt.name, len(tq.Queries))
+			}
+			_, _ = q.All(t.Context())
+			want := tq.LastQuery()
+			if gotSQL != want.SQL || !reflect.DeepEqual(gotArgs, want.Args) {
+				t.Errorf("%s/%s: ToSQL = %q %v, All ran %q %v", dialect, tt.name, gotSQL, gotArgs, want.SQL, want.Args)
+			}
+		}
+	}
+}
+
+func TestToSQLInsertMatchesCreate(t *testing.T) {
+	t.Parallel()
+
+	for name, d := range map[string]orm.Dialect{"mysql": orm.MySQL, "postgres": orm.PostgreSQL} {
+		tq := orm.NewTestQuerier(d)
+		q := newTestQuery(tq)
+		u := testUser{Name: "alice"}
+
+		gotSQL, gotArgs := q.ToSQLInsert(&u)
+		if len(tq.Queries) != 0 {
+			t.Fatalf("%s: ToSQLInsert ran %d queries, want none", name, len(tq.Queries))
+		}
+		_ = q.Create(t.Context(), &u)
+		want := tq.LastQuery()
+		if gotSQL != want.SQL || !reflect.DeepEqual(gotArgs, want.Args) {
+			t.Errorf("%s: ToSQLInsert = %q %v, Create ran %q %v", name, gotSQL, gotArgs, want.SQL, want.Args)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect orm.Dialect
+		analyze bool
+		want    string
+	}{
+		{"MySQL", orm.MySQL, false, "EXPLAIN SELECT `id`, `name` FROM `users` WHERE name = ? LIMIT 5"},
+		{"MySQL analyze", orm.MySQL, true, "EXPLAIN ANALYZE SELECT `id`, `name` FROM `users` WHERE name = ? LIMIT 5"},
+		{"PostgreSQL", orm.PostgreSQL, false, `EXPLAIN (FORMAT TEXT) SELECT "id", "name" FROM "users" WHERE name = $1 LIMIT 5`},
+		{"PostgreSQL analyze", orm.PostgreSQL, true, `EXPLAIN (ANALYZE, FORMAT TEXT) SELECT "id", "name" FROM "users" WHERE name = $1 LIMIT 5`},
+	}
+	for _, tt := range tests {
+		tq := orm.NewTestQuerier(tt.dialect)
+		q := newTestQuery(tq).Where("name = ?", "alice").Limit(5)
+		if tt.analyze {
+			_, _ = q.ExplainAnalyze(t.Context())
+		} else {
+			_, _ = q.Explain(t.Context())
+		}
+
+		got := tq.LastQuery()
+		if got.SQL != tt.want {
+			t.Errorf("%s: SQL = %q, want %q", tt.name, got.SQL, tt.want)
+		}
+		if !reflect.DeepEqual(got.Args, []any{"alice"}) {
+			t.Errorf("%s: Args = %v, want [alice]", tt.name, got.Args)
+		}
+	}
+}
+
+func TestExplainReturnsPlanRows(t *testing.T) {
+	t.Parallel()
+
+	plan, err := newStubUserQuery(t, "found_users", nil).Explain(t.Context())
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if want := []string{"1\tfound"}; !reflect.DeepEqual(plan, want) {
+		t.Errorf("plan = %q, want %q", plan, want)
+	}
+}
+
+func TestExplainUnsupportedDialect(t *testing.T) {
+	t.Parallel()
+
+	tq := orm.NewTestQuerier(orm.SQLServer)
+	if _, err := newTestQuery(tq).Explain(t.Context()); err == nil {
+		t.Error("Explain on SQL Server: want an error")
+	}
+	if len(tq.Queries) != 0 {
+		t.Errorf("ran %d queries, want none", len(tq.Queries))
 	}
 }
@@ -0,0 +1,48 @@
+package orm
+
+import "database/sql"
+
+// ScanRow scans the current row of rows into a new T. fields returns
+// pointers to the fields of T in the same order as the selected columns.
+// It is the manual counterpart to generated scanners, for report rows that
+// do not map to a model:
+//
+//	type salesRow struct {
+//	    Region string
+//	    Total  int64
+//	}
+//
+//	rows, err := db.QueryContext(ctx, "SELECT region, SUM(amount) FROM sales GROUP BY region")
+//	// handle err, defer rows.Close()
+//	for rows.Next() {
+//	    r, err := orm.ScanRow(rows, func(r *salesRow) []any { return []any{&r.Region, &r.Total} })
+//	    // ...
+//	}
+//
+// No reflection is used; the caller is responsible for matching columns.
+func ScanRow[T any](rows *sql.Rows, fields func(*T) []any) (T, error) {
+	var v T
+	err := rows.Scan(fields(&v)...)
+	return v, err //nolint:wrapcheck // pass through
+}
+
+// SkipNull wraps a scan destination so that a NULL column leaves *dest
+// unchanged; any other value is converted as rows.Scan would. Generated
+// scanners use it for joined relation columns, which are NULL when a
+// LEFT JOIN finds no related row.
+func SkipNull[V any](dest *V) sql.Scanner {
+	return skipNull[V]{dest}
+}
+
+type skipNull[V any] struct{ dest *V }
+
+func (s skipNull[V]) Scan(src any) error {
+	var v sql.Null[V]
+	if err := v.Scan(src); err != nil {
+		return err //nolint:wrapcheck // pass through
+	}
+	if v.Valid {
+		*s.dest = v.V
+	}
+	return nil
+}
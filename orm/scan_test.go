@@ -0,0 +1,37 @@
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+func TestSkipNull(t *testing.T) {
+	t.Parallel()
+
+	name := "kept"
+	if err := orm.SkipNull(&name).Scan(nil); err != nil {
+		t.Fatalf("Scan(nil): %v", err)
+	}
+	if name != "kept" {
+		t.Errorf("name = %q after NULL, want %q", name, "kept")
+	}
+
+	if err := orm.SkipNull(&name).Scan([]byte("alice")); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if name != "alice" {
+		t.Errorf("name = %q, want %q", name, "alice")
+	}
+
+	var id int
+	if err := orm.SkipNull(&id).Scan(int64(42)); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("id = %d, want 42", id)
+	}
+	if err := orm.SkipNull(&id).Scan("not a number"); err == nil {
+		t.Error("expected conversion error, got nil")
+	}
+}
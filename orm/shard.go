@@ -0,0 +1,52 @@
+package orm
+
+import "context"
+
+// ShardKey is routing metadata for sharded setups, e.g. {"tenant_id", 42}.
+// The orm never interprets it; a custom Querier reads it from the context
+// to pick a connection:
+//
+//	type router struct{ orm.Querier } // embeds the default shard
+//
+//	func (r router) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+//	    if k, ok := orm.ShardKeyFromContext(ctx); ok {
+//	        return r.shardFor(k).QueryContext(ctx, query, args...)
+//	    }
+//	    return r.Querier.QueryContext(ctx, query, args...)
+//	}
+type ShardKey struct {
+	Name  string
+	Value any
+}
+
+type shardKeyKey struct{}
+
+// WithShardKey returns a child context carrying the given shard key.
+// Every statement run with the context, including preload queries,
+// carries it to the Querier.
+func WithShardKey(ctx context.Context, name string, value any) context.Context {
+	return context.WithValue(ctx, shardKeyKey{}, ShardKey{Name: name, Value: value})
+}
+
+// ShardKeyFromContext returns the shard key carried by ctx, if any.
+func ShardKeyFromContext(ctx context.Context) (ShardKey, bool) {
+	k, ok := ctx.Value(shardKeyKey{}).(ShardKey)
+	return k, ok
+}
+
+// ShardKey attaches routing metadata to every statement this query runs,
+// as WithShardKey does for a context. It takes precedence over a shard key
+// already in the context.
+func (q *Query[T]) ShardKey(name string, value any) *Query[T] {
+	q2 := q.clone()
+	q2.shardKey = &ShardKey{Name: name, Value: value}
+	return q2
+}
+
+// routed returns ctx carrying the query's shard key, if one is set.
+func (q *Query[T]) routed(ctx context.Context) context.Context {
+	if q.shardKey == nil {
+		return ctx
+	}
+	return WithShardKey(ctx, q.shardKey.Name, q.shardKey.Value)
+}
@@ -0,0 +1,16 @@
+package orm
+
+import "fmt"
+
+// ParseSortColumn returns s as a C if it equals one of valid, and an error
+// wrapping ErrUnknownSortColumn otherwise. Generated code uses it to parse
+// user input, such as a sort query parameter, into a model's sort column
+// type before it reaches ORDER BY.
+func ParseSortColumn[C ~string](s string, valid ...C) (C, error) {
+	for _, c := range valid {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownSortColumn, s)
+}
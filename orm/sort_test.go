@@ -0,0 +1,26 @@
+package orm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mickamy/ormgen/orm"
+)
+
+type sortColumn string
+
+func TestParseSortColumn(t *testing.T) {
+	t.Parallel()
+
+	got, err := orm.ParseSortColumn("name", sortColumn("id"), sortColumn("name"))
+	if err != nil {
+		t.Fatalf("ParseSortColumn: %v", err)
+	}
+	if got != "name" {
+		t.Errorf("got %q, want name", got)
+	}
+
+	if _, err := orm.ParseSortColumn("name; DROP TABLE users", sortColumn("name")); !errors.Is(err, orm.ErrUnknownSortColumn) {
+		t.Errorf("err = %v, want ErrUnknownSortColumn", err)
+	}
+}
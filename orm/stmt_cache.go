@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// stmtCache is a concurrency-safe LRU cache of prepared statements keyed by
+// SQL text. A statement dropped from the cache, by eviction or after an
+// error, is closed once no caller is still executing it.
+type stmtCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *cachedStmt, most recently used first
+	entries map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // callers between acquire and release
+	dropped bool // removed from the cache; close when refs reaches 0
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// acquire returns the cached statement for query, preparing it on raw if
+// needed. The caller must release it once the statement has been executed.
+func (c *stmtCache) acquire(ctx context.Context, raw *sql.DB, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[query]; ok {
+		c.order.MoveToFront(el)
+		cs := el.Value.(*cachedStmt) //nolint:forcetypeassert // only *cachedStmt is stored
+		cs.refs++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.mu.Unlock()
+
+	// Prepare outside the lock so a slow round trip does not block other
+	// queries. Two callers may race to prepare the same query; the loser
+	// uses its own statement once and closes it.
+	stmt, err := raw.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // pass through
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[query]; ok {
+		return &cachedStmt{query: query, stmt: stmt, refs: 1, dropped: true}, nil
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(cs)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back().Value.(*cachedStmt) //nolint:forcetypeassert // only *cachedStmt is stored
+		c.drop(oldest)
+		if oldest.refs == 0 {
+			_ = oldest.stmt.Close()
+		}
+	}
+	return cs, nil
+}
+
+// release ends a use of cs. With a non-nil err the statement is dropped
+// from the cache, so that a statement broken by e.g. a schema change or a
+// lost connection is prepared afresh on the next call.
+func (c *stmtCache) release(cs *cachedStmt, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if err != nil {
+		c.drop(cs)
+	}
+	if cs.dropped && cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+// drop removes cs from the cache; the caller closes it once unused.
+// c.mu must be held.
+func (c *stmtCache) drop(cs *cachedStmt) {
+	if cs.dropped {
+		return
+	}
+	cs.dropped = true
+	c.order.Remove(c.entries[cs.query])
+	delete(c.entries, cs.query)
+}
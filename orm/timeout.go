@@ -0,0 +1,47 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns a child context carrying a query timeout.
+// Each terminal method (All, First, Count, Create, Update, Delete, ...)
+// called with the context runs its statements under a deadline of d from
+// the moment it starts, including any preload queries, and returns an error
+// wrapping ErrQueryTimeout if the deadline is exceeded. An earlier deadline
+// or cancellation of ctx itself still applies and is returned unwrapped.
+//
+// Inside a transaction the timeout bounds each terminal method, not the
+// transaction: the context passed to BeginTx or Transaction still decides
+// when the transaction is rolled back. A statement cut off by the timeout
+// may leave the transaction unusable (PostgreSQL aborts it), so return the
+// error and let Transaction roll back.
+func WithQueryTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, d)
+}
+
+// withQueryTimeout bounds ctx by the query timeout it carries, if any. The
+// returned finish func releases the deadline and, if it expired, wraps err
+// with ErrQueryTimeout; call it once the rows of the statement are closed.
+// The bounded context carries no timeout, so nested terminal methods, such
+// as preloads, share the deadline instead of starting their own.
+func withQueryTimeout(ctx context.Context) (context.Context, func(error) error) {
+	d, _ := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	if d <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	tctx, cancel := context.WithTimeout(context.WithValue(ctx, queryTimeoutKey{}, time.Duration(0)), d)
+	return tctx, func(err error) error {
+		expired := errors.Is(tctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+		if err != nil && expired {
+			return fmt.Errorf("%w after %s: %w", ErrQueryTimeout, d, err)
+		}
+		return err
+	}
+}
@@ -1,19 +1,33 @@
 package scope
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // Applier is implemented by query builders to receive scope fragments.
 // This interface lives in the scope package so that orm can import scope
 // without creating circular dependencies.
 type Applier interface {
 	ApplyWhere(clause string, args []any)
+	ApplyOrWhere(clause string, args []any)
 	ApplyOrderBy(clause string)
 	ApplyLimit(n int)
 	ApplyOffset(n int)
-	ApplySelect(columns string)
+	ApplySelectColumns(columns []string)
 	ApplyJoin(name string)
 	ApplyLeftJoin(name string)
 	ApplyPreload(name string)
+	ApplyOrderByCI(column, direction string)
+	ApplyEqCI(column string, value any)
+	ApplyILike(column string, pattern any)
+	ApplyColumnWhere(column, clause string, args []any)
+	ApplyQualifiedColumnWhere(column, clause string, args []any)
+	ApplyWhereChecked(clause string, args []any)
+	ApplyOrderByColumn(column, direction string)
+	ApplyGroupBy(columns []string)
+	ApplyHaving(clause string, args []any)
+	ApplyDistinct()
 }
 
 type scopeKind int
@@ -27,15 +41,28 @@ const (
 	kindJoin
 	kindLeftJoin
 	kindPreload
+	kindOrderByCI
+	kindEqCI
+	kindColumnWhere
+	kindWhereChecked
+	kindOrderByColumn
+	kindGroupBy
+	kindHaving
+	kindOr
+	kindILike
+	kindDistinct
+	kindQualifiedColumnWhere
 )
 
 // Scope represents a single query condition fragment.
 // Scopes are immutable and safe to reuse across queries.
 type Scope struct {
-	kind   scopeKind
-	clause string
-	args   []any
-	n      int
+	kind    scopeKind
+	clause  string
+	column  string
+	columns []string
+	args    []any
+	n       int
 }
 
 // Apply dispatches this Scope to the given Applier.
@@ -50,13 +77,36 @@ func (s Scope) Apply(a Applier) {
 	case kindOffset:
 		a.ApplyOffset(s.n)
 	case kindSelect:
-		a.ApplySelect(s.clause)
+		a.ApplySelectColumns(s.columns)
 	case kindJoin:
 		a.ApplyJoin(s.clause)
 	case kindLeftJoin:
 		a.ApplyLeftJoin(s.clause)
 	case kindPreload:
 		a.ApplyPreload(s.clause)
+	case kindOrderByCI:
+		column, direction, _ := strings.Cut(s.clause, " ")
+		a.ApplyOrderByCI(column, direction)
+	case kindEqCI:
+		a.ApplyEqCI(s.clause, s.args[0])
+	case kindColumnWhere:
+		a.ApplyColumnWhere(s.column, s.clause, s.args)
+	case kindWhereChecked:
+		a.ApplyWhereChecked(s.clause, s.args)
+	case kindOrderByColumn:
+		a.ApplyOrderByColumn(s.column, s.clause)
+	case kindGroupBy:
+		a.ApplyGroupBy(s.columns)
+	case kindHaving:
+		a.ApplyHaving(s.clause, s.args)
+	case kindOr:
+		a.ApplyOrWhere(s.clause, s.args)
+	case kindILike:
+		a.ApplyILike(s.column, s.args[0])
+	case kindDistinct:
+		a.ApplyDistinct()
+	case kindQualifiedColumnWhere:
+		a.ApplyQualifiedColumnWhere(s.column, s.clause, s.args)
 	}
 }
 
@@ -68,6 +118,26 @@ func Where(clause string, args ...any) Scope {
 	return Scope{kind: kindWhere, clause: clause, args: args}
 }
 
+// Or returns a Scope that ORs clause with all WHERE conditions applied
+// before it, grouping them so that later conditions still apply to the
+// whole result. Scope order matters, as with Query.OrWhere:
+//
+//	Users(db).Scopes(scope.Where("name = ?", n), scope.Or("email = ?", e), Active)
+//	// → WHERE ((name = ?) OR (email = ?)) AND active = ?
+func Or(clause string, args ...any) Scope {
+	return Scope{kind: kindOr, clause: clause, args: args}
+}
+
+// WhereChecked is like Where, but the query validates that the number of ?
+// placeholders in clause matches len(args). On a mismatch the terminal
+// method returns an error wrapping orm.ErrPlaceholderMismatch instead of
+// sending a broken query.
+//
+//	scope.WhereChecked("a = ? OR b = ?", x)  // → error: 2 placeholders, 1 arg
+func WhereChecked(clause string, args ...any) Scope {
+	return Scope{kind: kindWhereChecked, clause: clause, args: args}
+}
+
 // OrderBy returns a Scope that sets the ORDER BY clause.
 //
 //	scope.OrderBy("created_at DESC")
@@ -75,6 +145,127 @@ func OrderBy(clause string) Scope {
 	return Scope{kind: kindOrderBy, clause: clause}
 }
 
+// Asc returns a Scope that orders by column ascending. The column is quoted
+// for the query's dialect; an unqualified column refers to the query's own
+// table, so the order stays unambiguous when relations are joined.
+//
+//	scope.Asc("name")  // → ORDER BY `users`.`name` ASC
+func Asc(column string) Scope {
+	return Scope{kind: kindOrderByColumn, column: column, clause: "ASC"}
+}
+
+// Desc is like Asc but orders descending.
+//
+//	scope.Desc("created_at")  // → ORDER BY `users`.`created_at` DESC
+func Desc(column string) Scope {
+	return Scope{kind: kindOrderByColumn, column: column, clause: "DESC"}
+}
+
+// OrderByCI returns a Scope that orders case-insensitively by column,
+// optionally followed by ASC or DESC. The case-insensitive expression is
+// provided by the query's dialect.
+//
+//	scope.OrderByCI("name DESC")  // → ORDER BY LOWER(name) DESC
+func OrderByCI(column string) Scope {
+	return Scope{kind: kindOrderByCI, clause: strings.TrimSpace(column)}
+}
+
+// EqCI returns a Scope that matches column against value case-insensitively.
+// The case-insensitive expression is provided by the query's dialect.
+//
+//	scope.EqCI("email", "Alice@Example.com")  // → WHERE LOWER(email) = LOWER(?)
+func EqCI(column string, value any) Scope {
+	return Scope{kind: kindEqCI, clause: column, args: []any{value}}
+}
+
+// IsNull returns a Scope matching rows whose column is NULL.
+//
+//	scope.IsNull("deleted_at")  // → WHERE deleted_at IS NULL
+func IsNull(column string) Scope {
+	return Where(column + " IS NULL")
+}
+
+// IsNotNull returns a Scope matching rows whose column is not NULL.
+//
+//	scope.IsNotNull("confirmed_at")  // → WHERE confirmed_at IS NOT NULL
+func IsNotNull(column string) Scope {
+	return Where(column + " IS NOT NULL")
+}
+
+// Like returns a Scope matching column against a LIKE pattern, in which %
+// and _ are wildcards. Escape user input with EscapeLike so that its own %
+// and _ match literally:
+//
+//	scope.Like("name", "%"+scope.EscapeLike(term)+"%")  // → WHERE name LIKE ?
+func Like(column, pattern string) Scope {
+	return Where(column+" LIKE ?", pattern)
+}
+
+// ILike is like Like but ignores case. The comparison is provided by the
+// query's dialect: ILIKE on PostgreSQL, LOWER() on both sides on MySQL.
+//
+//	scope.ILike("email", scope.EscapeLike(domain)+"%")  // → WHERE email ILIKE $1
+func ILike(column, pattern string) Scope {
+	return Scope{kind: kindILike, column: column, args: []any{pattern}}
+}
+
+// likeEscaper escapes LIKE wildcards with backslash, the default escape
+// character of both MySQL and PostgreSQL.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike escapes the LIKE wildcards % and _ (and the escape character
+// \) in s, so that s matches itself literally inside a Like or ILike
+// pattern.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// columnWhere returns a WHERE Scope on a column that the query quotes for
+// its dialect. clause is a fmt format in which %[1]s stands for the column.
+func columnWhere(column, clause string, args ...any) Scope {
+	return Scope{kind: kindColumnWhere, column: column, clause: clause, args: args}
+}
+
+// After returns a Scope matching rows whose column is strictly after t.
+// The column is quoted for the query's dialect.
+//
+//	scope.After("created_at", since)  // → WHERE `created_at` > ?
+func After(column string, t time.Time) Scope {
+	return columnWhere(column, "%[1]s > ?", t)
+}
+
+// Before returns a Scope matching rows whose column is strictly before t.
+// The column is quoted for the query's dialect.
+//
+//	scope.Before("created_at", until)  // → WHERE `created_at` < ?
+func Before(column string, t time.Time) Scope {
+	return columnWhere(column, "%[1]s < ?", t)
+}
+
+// DateEq returns a Scope matching rows whose column falls on the same
+// calendar day as t, in t's location. The column is quoted for the query's
+// dialect.
+//
+//	scope.DateEq("created_at", day)  // → WHERE `created_at` >= ? AND `created_at` < ?
+func DateEq(column string, t time.Time) Scope {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return columnWhere(column, "%[1]s >= ? AND %[1]s < ?", start, start.AddDate(0, 0, 1))
+}
+
+// GroupBy returns a Scope that adds columns to the GROUP BY clause.
+//
+//	scope.GroupBy("user_id")
+func GroupBy(columns ...string) Scope {
+	return Scope{kind: kindGroupBy, columns: columns}
+}
+
+// Having returns a Scope that adds a HAVING clause fragment.
+//
+//	scope.Having("COUNT(*) > ?", 5)
+func Having(clause string, args ...any) Scope {
+	return Scope{kind: kindHaving, clause: clause, args: args}
+}
+
 // Limit returns a Scope that sets the LIMIT.
 func Limit(n int) Scope {
 	return Scope{kind: kindLimit, n: n}
@@ -85,11 +276,13 @@ func Offset(n int) Scope {
 	return Scope{kind: kindOffset, n: n}
 }
 
-// Select returns a Scope that overrides the SELECT column list.
+// Select returns a Scope that overrides the SELECT column list, like
+// Query.SelectColumns: column names are quoted, expressions kept as is.
 //
-//	scope.Select("id", "name")
+//	scope.Select("id", "name")          // → SELECT `id`, `name`
+//	scope.Select("role", "COUNT(*) AS n") // → SELECT `role`, COUNT(*) AS n
 func Select(columns ...string) Scope {
-	return Scope{kind: kindSelect, clause: strings.Join(columns, ", ")}
+	return Scope{kind: kindSelect, columns: columns}
 }
 
 // Join returns a Scope that adds an INNER JOIN for the named relation.
@@ -97,6 +290,13 @@ func Join(name string) Scope {
 	return Scope{kind: kindJoin, clause: name}
 }
 
+// Distinct returns a Scope that makes the query SELECT DISTINCT.
+//
+//	scope.Distinct()  // → SELECT DISTINCT `id`, `name` FROM `users`
+func Distinct() Scope {
+	return Scope{kind: kindDistinct}
+}
+
 // LeftJoin returns a Scope that adds a LEFT JOIN for the named relation.
 func LeftJoin(name string) Scope {
 	return Scope{kind: kindLeftJoin, clause: name}
@@ -124,6 +324,62 @@ func In[T any](column string, values []T) Scope {
 	return Where(column+" IN ("+placeholders+")", args...)
 }
 
+// Between returns a WHERE scope matching rows whose column lies in the
+// inclusive range [lo, hi]. Both bounds have the same type, so a date range
+// cannot mix time.Time with a string by mistake.
+//
+//	scope.Between("created_at", from, to)  // → WHERE created_at BETWEEN ? AND ?
+func Between[T any](column string, lo, hi T) Scope {
+	return Where(column+" BETWEEN ? AND ?", lo, hi)
+}
+
+// NotBetween is like Between but matches rows whose column lies outside
+// [lo, hi].
+//
+//	scope.NotBetween("price", 10, 20)  // → WHERE price NOT BETWEEN ? AND ?
+func NotBetween[T any](column string, lo, hi T) Scope {
+	return Where(column+" NOT BETWEEN ? AND ?", lo, hi)
+}
+
+// MaxPerPage is the upper bound Paginate applies to perPage.
+const MaxPerPage = 100
+
+// Paginate returns Limit and Offset scopes for a 1-based page number.
+// page below 1 is treated as 1; perPage is clamped to [1, MaxPerPage].
+//
+//	scope.Paginate(3, 20)  // → LIMIT 20 OFFSET 40
+func Paginate(page, perPage int) Scopes {
+	page = max(page, 1)
+	perPage = min(max(perPage, 1), MaxPerPage)
+	return Combine(Limit(perPage), Offset((page-1)*perPage))
+}
+
+// Keyset returns scopes for keyset (cursor) pagination on a unique column:
+// rows after lastValue, the column's value on the last row of the previous
+// page, in column order. Unlike Offset, the database seeks straight to the
+// page through the column's index, however deep it is. A nil lastValue
+// starts at the first page. Add Limit for the page size:
+//
+//	Users(db).Scopes(scope.Keyset("id", lastID, false)...).Scopes(scope.Limit(20)).All(ctx)
+//	// → WHERE `users`.`id` > ? ORDER BY `users`.`id` ASC LIMIT 20
+//
+// With desc the rows come in descending order, so the WHERE is `id` < ?.
+// The column is quoted for the query's dialect and, unless already
+// qualified, prefixed with the base table in both the WHERE and the
+// ORDER BY, so that a joined table sharing the column cannot make it
+// ambiguous.
+func Keyset(column string, lastValue any, desc bool) Scopes {
+	op, order := ">", Asc(column)
+	if desc {
+		op, order = "<", Desc(column)
+	}
+	if lastValue == nil {
+		return Combine(order)
+	}
+	where := Scope{kind: kindQualifiedColumnWhere, column: column, clause: "%[1]s " + op + " ?", args: []any{lastValue}}
+	return Combine(where, order)
+}
+
 // Scopes is a named slice of Scope, useful for conditionally building
 // up a set of scopes.
 //
@@ -131,7 +387,7 @@ func In[T any](column string, values []T) Scope {
 //	if onlyActive {
 //	    s = s.Append(Active)
 //	}
-//	s = s.Append(Paginate(page, perPage))
+//	s = s.Append(Paginate(page, perPage)...)
 //	Users(db).Scopes(s...).All(ctx)
 type Scopes []Scope
 
@@ -1,21 +1,34 @@
 package scope_test
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/mickamy/ormgen/scope"
 )
 
 // mockApplier records calls from Scope.Apply for assertions.
 type mockApplier struct {
-	wheres    []appliedWhere
-	orderBys  []string
-	selects   []string
-	joins     []string
-	leftJoins []string
-	preloads  []string
-	limit     *int
-	offset    *int
+	wheres       []appliedWhere
+	orWheres     []appliedWhere
+	orderBys     []string
+	selects      [][]string
+	joins        []string
+	leftJoins    []string
+	preloads     []string
+	ciOrders     []string
+	ciEqs        []appliedWhere
+	iLikes       []appliedWhere
+	columnWheres []appliedColumnWhere
+	qualWheres   []appliedColumnWhere
+	checked      []appliedWhere
+	colOrders    []string
+	groupBys     []string
+	havings      []appliedWhere
+	distinct     bool
+	limit        *int
+	offset       *int
 }
 
 type appliedWhere struct {
@@ -23,16 +36,56 @@ type appliedWhere struct {
 	args   []any
 }
 
+type appliedColumnWhere struct {
+	column string
+	clause string
+	args   []any
+}
+
 func (m *mockApplier) ApplyWhere(clause string, args []any) {
 	m.wheres = append(m.wheres, appliedWhere{clause, args})
 }
-func (m *mockApplier) ApplyOrderBy(clause string) { m.orderBys = append(m.orderBys, clause) }
-func (m *mockApplier) ApplyLimit(n int)           { m.limit = &n }
-func (m *mockApplier) ApplyOffset(n int)          { m.offset = &n }
-func (m *mockApplier) ApplySelect(columns string) { m.selects = append(m.selects, columns) }
-func (m *mockApplier) ApplyJoin(name string)      { m.joins = append(m.joins, name) }
-func (m *mockApplier) ApplyLeftJoin(name string)   { m.leftJoins = append(m.leftJoins, name) }
-func (m *mockApplier) ApplyPreload(name string)    { m.preloads = append(m.preloads, name) }
+func (m *mockApplier) ApplyOrWhere(clause string, args []any) {
+	m.orWheres = append(m.orWheres, appliedWhere{clause, args})
+}
+func (m *mockApplier) ApplyOrderBy(clause string)          { m.orderBys = append(m.orderBys, clause) }
+func (m *mockApplier) ApplyLimit(n int)                    { m.limit = &n }
+func (m *mockApplier) ApplyOffset(n int)                   { m.offset = &n }
+func (m *mockApplier) ApplySelectColumns(columns []string) { m.selects = append(m.selects, columns) }
+func (m *mockApplier) ApplyJoin(name string)               { m.joins = append(m.joins, name) }
+func (m *mockApplier) ApplyLeftJoin(name string)           { m.leftJoins = append(m.leftJoins, name) }
+func (m *mockApplier) ApplyPreload(name string)            { m.preloads = append(m.preloads, name) }
+func (m *mockApplier) ApplyOrderByCI(column, direction string) {
+	m.ciOrders = append(m.ciOrders, column+"|"+direction)
+}
+func (m *mockApplier) ApplyEqCI(column string, value any) {
+	m.ciEqs = append(m.ciEqs, appliedWhere{column, []any{value}})
+}
+func (m *mockApplier) ApplyILike(column string, pattern any) {
+	m.iLikes = append(m.iLikes, appliedWhere{column, []any{pattern}})
+}
+func (m *mockApplier) ApplyColumnWhere(column, clause string, args []any) {
+	m.columnWheres = append(m.columnWheres, appliedColumnWhere{column, clause, args})
+}
+func (m *mockApplier) ApplyQualifiedColumnWhere(column, clause string, args []any) {
+	m.qualWheres = append(m.qualWheres, appliedColumnWhere{column, clause, args})
+}
+
+func (m *mockApplier) ApplyWhereChecked(clause string, args []any) {
+	m.checked = append(m.checked, appliedWhere{clause, args})
+}
+
+func (m *mockApplier) ApplyOrderByColumn(column, direction string) {
+	m.colOrders = append(m.colOrders, column+"|"+direction)
+}
+
+func (m *mockApplier) ApplyGroupBy(columns []string) { m.groupBys = append(m.groupBys, columns...) }
+
+func (m *mockApplier) ApplyHaving(clause string, args []any) {
+	m.havings = append(m.havings, appliedWhere{clause, args})
+}
+
+func (m *mockApplier) ApplyDistinct() { m.distinct = true }
 
 func TestWhere(t *testing.T) {
 	t.Parallel()
@@ -68,6 +121,34 @@ func TestWhereMultipleArgs(t *testing.T) {
 	}
 }
 
+func TestOr(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.Or("email = ?", "a@example.com").Apply(m)
+
+	if len(m.wheres) != 0 {
+		t.Errorf("wheres = %v, want none", m.wheres)
+	}
+	if len(m.orWheres) != 1 || m.orWheres[0].clause != "email = ?" || m.orWheres[0].args[0] != "a@example.com" {
+		t.Errorf("orWheres = %+v", m.orWheres)
+	}
+}
+
+func TestWhereChecked(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.WhereChecked("a = ? OR b = ?", 1).Apply(m)
+
+	if len(m.wheres) != 0 {
+		t.Errorf("expected no plain where, got %v", m.wheres)
+	}
+	if len(m.checked) != 1 || m.checked[0].clause != "a = ? OR b = ?" || len(m.checked[0].args) != 1 {
+		t.Errorf("checked = %v, want [{a = ? OR b = ? [1]}]", m.checked)
+	}
+}
+
 func TestOrderBy(t *testing.T) {
 	t.Parallel()
 
@@ -79,6 +160,95 @@ func TestOrderBy(t *testing.T) {
 	}
 }
 
+func TestOrderByCI(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.OrderByCI("name").Apply(m)
+	scope.OrderByCI(" email DESC ").Apply(m)
+
+	want := []string{"name|", "email|DESC"}
+	if len(m.ciOrders) != 2 || m.ciOrders[0] != want[0] || m.ciOrders[1] != want[1] {
+		t.Errorf("ciOrders = %v, want %v", m.ciOrders, want)
+	}
+	if len(m.orderBys) != 0 {
+		t.Errorf("orderBys = %v, want none", m.orderBys)
+	}
+}
+
+func TestAscDesc(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.Asc("name").Apply(m)
+	scope.Desc("posts.created_at").Apply(m)
+
+	want := []string{"name|ASC", "posts.created_at|DESC"}
+	if len(m.colOrders) != 2 || m.colOrders[0] != want[0] || m.colOrders[1] != want[1] {
+		t.Errorf("colOrders = %v, want %v", m.colOrders, want)
+	}
+	if len(m.orderBys) != 0 {
+		t.Errorf("orderBys = %v, want none", m.orderBys)
+	}
+}
+
+func TestEqCI(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.EqCI("email", "Alice@Example.com").Apply(m)
+
+	if len(m.ciEqs) != 1 || m.ciEqs[0].clause != "email" || m.ciEqs[0].args[0] != "Alice@Example.com" {
+		t.Errorf("ciEqs = %v, want [email Alice@Example.com]", m.ciEqs)
+	}
+	if len(m.wheres) != 0 {
+		t.Errorf("wheres = %v, want none", m.wheres)
+	}
+}
+
+func TestTimeComparisons(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("JST", 9*60*60)
+	ts := time.Date(2025, 3, 14, 15, 9, 26, 0, loc)
+	dayStart := time.Date(2025, 3, 14, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name       string
+		scope      scope.Scope
+		wantClause string
+		wantArgs   []any
+	}{
+		{"After", scope.After("created_at", ts), "%[1]s > ?", []any{ts}},
+		{"Before", scope.Before("created_at", ts), "%[1]s < ?", []any{ts}},
+		{"DateEq", scope.DateEq("created_at", ts), "%[1]s >= ? AND %[1]s < ?", []any{dayStart, dayStart.AddDate(0, 0, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &mockApplier{}
+			tt.scope.Apply(m)
+
+			if len(m.columnWheres) != 1 {
+				t.Fatalf("expected 1 column where, got %d", len(m.columnWheres))
+			}
+			got := m.columnWheres[0]
+			if got.column != "created_at" || got.clause != tt.wantClause {
+				t.Errorf("column, clause = %q, %q, want %q, %q", got.column, got.clause, "created_at", tt.wantClause)
+			}
+			if len(got.args) != len(tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", got.args, tt.wantArgs)
+			}
+			for i := range got.args {
+				if !got.args[i].(time.Time).Equal(tt.wantArgs[i].(time.Time)) {
+					t.Errorf("args[%d] = %v, want %v", i, got.args[i], tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
 func TestLimit(t *testing.T) {
 	t.Parallel()
 
@@ -107,8 +277,34 @@ func TestSelect(t *testing.T) {
 	m := &mockApplier{}
 	scope.Select("id", "name", "email").Apply(m)
 
-	if len(m.selects) != 1 || m.selects[0] != "id, name, email" {
-		t.Errorf("selects = %v, want [id, name, email]", m.selects)
+	if len(m.selects) != 1 || !slices.Equal(m.selects[0], []string{"id", "name", "email"}) {
+		t.Errorf("selects = %v, want [[id name email]]", m.selects)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.Distinct().Apply(m)
+
+	if !m.distinct {
+		t.Error("distinct = false, want true")
+	}
+}
+
+func TestGroupByHaving(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.GroupBy("user_id", "status").Apply(m)
+	scope.Having("COUNT(*) > ?", 5).Apply(m)
+
+	if len(m.groupBys) != 2 || m.groupBys[0] != "user_id" || m.groupBys[1] != "status" {
+		t.Errorf("groupBys = %v, want [user_id status]", m.groupBys)
+	}
+	if len(m.havings) != 1 || m.havings[0].clause != "COUNT(*) > ?" || m.havings[0].args[0] != 5 {
+		t.Errorf("havings = %+v", m.havings)
 	}
 }
 
@@ -134,6 +330,114 @@ func TestIn(t *testing.T) {
 	}
 }
 
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		scope  scope.Scope
+		clause string
+		args   []any
+	}{
+		{"Between", scope.Between("created_at", from, to), "created_at BETWEEN ? AND ?", []any{from, to}},
+		{"NotBetween", scope.NotBetween("price", 10, 20), "price NOT BETWEEN ? AND ?", []any{10, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &mockApplier{}
+			tt.scope.Apply(m)
+
+			if len(m.wheres) != 1 {
+				t.Fatalf("expected 1 where, got %d", len(m.wheres))
+			}
+			if m.wheres[0].clause != tt.clause {
+				t.Errorf("clause = %q, want %q", m.wheres[0].clause, tt.clause)
+			}
+			if len(m.wheres[0].args) != 2 || m.wheres[0].args[0] != tt.args[0] || m.wheres[0].args[1] != tt.args[1] {
+				t.Errorf("args = %v, want %v", m.wheres[0].args, tt.args)
+			}
+		})
+	}
+}
+
+func TestIsNull(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scope scope.Scope
+		want  string
+	}{
+		{scope.IsNull("deleted_at"), "deleted_at IS NULL"},
+		{scope.IsNotNull("deleted_at"), "deleted_at IS NOT NULL"},
+	}
+	for _, tt := range tests {
+		m := &mockApplier{}
+		tt.scope.Apply(m)
+
+		if len(m.wheres) != 1 {
+			t.Fatalf("expected 1 where, got %d", len(m.wheres))
+		}
+		if m.wheres[0].clause != tt.want {
+			t.Errorf("clause = %q, want %q", m.wheres[0].clause, tt.want)
+		}
+		if len(m.wheres[0].args) != 0 {
+			t.Errorf("args = %v, want none", m.wheres[0].args)
+		}
+	}
+}
+
+func TestLike(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.Like("name", "%"+scope.EscapeLike("50%_off")+"%").Apply(m)
+
+	if len(m.wheres) != 1 {
+		t.Fatalf("expected 1 where, got %d", len(m.wheres))
+	}
+	if m.wheres[0].clause != "name LIKE ?" {
+		t.Errorf("clause = %q, want %q", m.wheres[0].clause, "name LIKE ?")
+	}
+	if want := `%50\%\_off%`; len(m.wheres[0].args) != 1 || m.wheres[0].args[0] != want {
+		t.Errorf("args = %v, want [%s]", m.wheres[0].args, want)
+	}
+}
+
+func TestILike(t *testing.T) {
+	t.Parallel()
+
+	m := &mockApplier{}
+	scope.ILike("email", "alice%").Apply(m)
+
+	if len(m.wheres) != 0 {
+		t.Errorf("ILike must defer to the dialect, got plain wheres %v", m.wheres)
+	}
+	if len(m.iLikes) != 1 || m.iLikes[0].clause != "email" || m.iLikes[0].args[0] != "alice%" {
+		t.Errorf("iLikes = %v, want [{email [alice%%]}]", m.iLikes)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"plain":      "plain",
+		"100%":       `100\%`,
+		"snake_case": `snake\_case`,
+		`C:\dir`:     `C:\\dir`,
+	}
+	for in, want := range tests {
+		if got := scope.EscapeLike(in); got != want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestInEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -263,3 +567,106 @@ func TestScopesAppendDoesNotMutate(t *testing.T) {
 		t.Fatalf("original mutated: len = %d", len(original))
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		page       int
+		perPage    int
+		wantLimit  int
+		wantOffset int
+	}{
+		{"first page", 1, 20, 20, 0},
+		{"third page", 3, 20, 20, 40},
+		{"page below 1", 0, 10, 10, 0},
+		{"negative page", -5, 10, 10, 0},
+		{"perPage below 1", 2, 0, 1, 1},
+		{"perPage above max", 2, scope.MaxPerPage + 50, scope.MaxPerPage, scope.MaxPerPage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &mockApplier{}
+			for _, s := range scope.Paginate(tt.page, tt.perPage) {
+				s.Apply(m)
+			}
+			if m.limit == nil || *m.limit != tt.wantLimit {
+				t.Errorf("limit = %v, want %d", m.limit, tt.wantLimit)
+			}
+			if m.offset == nil || *m.offset != tt.wantOffset {
+				t.Errorf("offset = %v, want %d", m.offset, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		lastValue any
+		desc      bool
+		wantWhere string
+		wantOrder string
+	}{
+		{"ascending", 42, false, "%[1]s > ?", "id|ASC"},
+		{"descending", 42, true, "%[1]s < ?", "id|DESC"},
+		{"first page", nil, false, "", "id|ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &mockApplier{}
+			for _, s := range scope.Keyset("id", tt.lastValue, tt.desc).Append(scope.Limit(20)) {
+				s.Apply(m)
+			}
+
+			if tt.wantWhere == "" {
+				if len(m.qualWheres) != 0 {
+					t.Errorf("qualWheres = %v, want none", m.qualWheres)
+				}
+			} else {
+				if len(m.qualWheres) != 1 {
+					t.Fatalf("qualWheres = %v, want 1", m.qualWheres)
+				}
+				got := m.qualWheres[0]
+				if got.column != "id" || got.clause != tt.wantWhere || len(got.args) != 1 || got.args[0] != tt.lastValue {
+					t.Errorf("qualWhere = %+v, want id %q [%v]", got, tt.wantWhere, tt.lastValue)
+				}
+			}
+			if len(m.colOrders) != 1 || m.colOrders[0] != tt.wantOrder {
+				t.Errorf("colOrders = %v, want [%s]", m.colOrders, tt.wantOrder)
+			}
+			if m.limit == nil || *m.limit != 20 {
+				t.Errorf("limit = %v, want 20", m.limit)
+			}
+		})
+	}
+}
+
+func TestPaginateMerge(t *testing.T) {
+	t.Parallel()
+
+	s := scope.Combine(scope.Where("active = ?", true)).Merge(scope.Paginate(2, 10))
+	if len(s) != 3 {
+		t.Fatalf("len = %d, want 3", len(s))
+	}
+
+	m := &mockApplier{}
+	for _, sc := range s {
+		sc.Apply(m)
+	}
+	if len(m.wheres) != 1 {
+		t.Errorf("wheres = %d, want 1", len(m.wheres))
+	}
+	if m.offset == nil || *m.offset != 10 {
+		t.Errorf("offset = %v, want 10", m.offset)
+	}
+}